@@ -2,11 +2,13 @@ module github.com/akatsuki-members/credit-crypto/libs/common-endpoints
 
 go 1.17
 
-require github.com/stretchr/testify v1.8.0
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.8.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -14,8 +14,9 @@ const healthPattern = "/health"
 type Checker func() Report
 
 type Item struct {
-	Name    string `json:"name"`    // integration or component name
-	Healthy bool   `json:"healthy"` // health status of the component.
+	Name    string `json:"name"`             // integration or component name
+	Healthy bool   `json:"healthy"`          // health status of the component.
+	Status  string `json:"status,omitempty"` // component specific state, like a circuit breaker state.
 }
 
 // Contains health report.
@@ -18,9 +18,9 @@ func TestAddHealth(t *testing.T) {
 
 	// GIVEN
 	expectedHealth := []health.Item{
-		{"Database", true},
-		{"Cache", true},
-		{"OtherService", true},
+		{Name: "Database", Healthy: true},
+		{Name: "Cache", Healthy: true},
+		{Name: "OtherService", Healthy: true},
 	}
 	healthChecker := newMockedHealthChecker(newHealthReportOK(), true)
 	expectedCode := 200
@@ -53,9 +53,9 @@ func TestAddHealthThatFails(t *testing.T) {
 
 	// GIVEN
 	expectedHealth := []health.Item{
-		{"Database", false},
-		{"Cache", true},
-		{"OtherService", true},
+		{Name: "Database", Healthy: false},
+		{Name: "Cache", Healthy: true},
+		{Name: "OtherService", Healthy: true},
 	}
 	healthChecker := newMockedHealthChecker(newHealthReportFailed(), false)
 	expectedCode := 500
@@ -136,17 +136,17 @@ func newMockedHealthChecker(report []health.Item, healthy bool) func() health.Re
 
 func newHealthReportOK() []health.Item {
 	return []health.Item{
-		{"Database", true},
-		{"Cache", true},
-		{"OtherService", true},
+		{Name: "Database", Healthy: true},
+		{Name: "Cache", Healthy: true},
+		{Name: "OtherService", Healthy: true},
 	}
 }
 
 func newHealthReportFailed() []health.Item {
 	return []health.Item{
-		{"Database", false},
-		{"Cache", true},
-		{"OtherService", true},
+		{Name: "Database", Healthy: false},
+		{Name: "Cache", Healthy: true},
+		{Name: "OtherService", Healthy: true},
 	}
 }
 
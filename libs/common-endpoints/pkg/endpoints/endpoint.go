@@ -20,8 +20,9 @@ type Info struct {
 
 // Item contains information about components and their status.
 type Item struct {
-	Name    string `json:"name"`    // integration or component name
-	Healthy bool   `json:"healthy"` // health status of the component.
+	Name    string `json:"name"`             // integration or component name
+	Healthy bool   `json:"healthy"`          // health status of the component.
+	Status  string `json:"status,omitempty"` // component specific state, like a circuit breaker state.
 }
 
 // Contains health report.
@@ -33,6 +34,7 @@ type Report struct {
 type Handler struct {
 	router       *http.ServeMux
 	hasEndpoints bool
+	shuttingDown func() bool
 }
 
 func New(router *http.ServeMux) *Handler {
@@ -59,6 +61,15 @@ func (h *Handler) WithHealth(checker HealthChecker) *Handler {
 	return h
 }
 
+// WithShutdown makes the health endpoint report the service as not-ready while the given
+// function returns true, like a pubsub lifecycle coordinator shutting down. It must be called
+// before serving requests.
+func (h *Handler) WithShutdown(shuttingDown func() bool) *Handler {
+	h.shuttingDown = shuttingDown
+
+	return h
+}
+
 func (h *Handler) WithInfo(data Info) *Handler {
 	h.hasEndpoints = true
 	infoReport := info.Report{
@@ -80,9 +91,15 @@ func (h *Handler) newHealthChecker(checker HealthChecker) health.Checker {
 			report[idx] = health.Item{
 				Name:    v.Name,
 				Healthy: v.Healthy,
+				Status:  v.Status,
 			}
 		}
 
+		if h.shuttingDown != nil && h.shuttingDown() {
+			report = append(report, health.Item{Name: "shutdown", Healthy: false, Status: "shutting down"})
+			result.Healthy = false
+		}
+
 		return health.Report{
 			Healthy: result.Healthy,
 			Data:    report,
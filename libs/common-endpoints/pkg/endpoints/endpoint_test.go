@@ -60,6 +60,58 @@ func TestHealth(t *testing.T) {
 	assert.Equal(t, expectedResult, result)
 }
 
+func TestHealthReportsComponentStatus(t *testing.T) {
+	t.Parallel()
+
+	expectedCode := 500
+	expectedHealth := []endpoints.Item{
+		{Name: "Database", Healthy: true},
+		{Name: "EventBus", Healthy: false, Status: "open"},
+	}
+	expectedResult := handlers.Result{
+		Success: false,
+		Data:    expectedHealth,
+	}
+	mux := http.NewServeMux()
+
+	healthChecker := newMockedHealthChecker(expectedHealth, false)
+
+	endpoints.New(mux).WithHealth(healthChecker)
+
+	code, result := hitHealth(t, mux)
+
+	assert.Equal(t, expectedCode, code)
+	assert.Equal(t, expectedResult, result)
+}
+
+func TestHealthReportsNotReadyWhileShuttingDown(t *testing.T) {
+	t.Parallel()
+
+	expectedCode := 500
+	expectedHealth := []endpoints.Item{
+		{Name: "Database", Healthy: true},
+		{Name: "shutdown", Healthy: false, Status: "shutting down"},
+	}
+	expectedResult := handlers.Result{
+		Success: false,
+		Data:    expectedHealth,
+	}
+	mux := http.NewServeMux()
+	shuttingDown := false
+
+	healthChecker := newMockedHealthChecker([]endpoints.Item{{Name: "Database", Healthy: true}}, true)
+
+	endpoints.New(mux).WithHealth(healthChecker).WithShutdown(func() bool { return shuttingDown })
+
+	healthyCode, _ := hitHealth(t, mux)
+	shuttingDown = true
+	code, result := hitHealth(t, mux)
+
+	assert.Equal(t, 200, healthyCode)
+	assert.Equal(t, expectedCode, code)
+	assert.Equal(t, expectedResult, result)
+}
+
 func TestInfo(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,145 @@
+package bridge
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const defaultRetryDelay = time.Second
+
+var errUnknownChannel = errors.New("event has no source channel")
+
+// Settings contains the bridge configuration.
+type Settings struct {
+	// Subscriber already subscribed to the source channels.
+	Subscriber *subscribers.Subscriber
+	// Publisher of the destination event bus.
+	Publisher *publishers.Publisher
+	// Channels destination channel by source channel or channel pattern, events of channels
+	// without mapping keep their channel name.
+	Channels Channels
+	// Workers number of events forwarded concurrently, events with the same ordering key are
+	// always forwarded in order.
+	Workers int
+	// RetryDelay delay between attempts to publish an event into the destination, defaults to
+	// one second. Events are retried until they are published or the bridge stops, so events
+	// with the same ordering key are never forwarded out of order.
+	RetryDelay time.Duration
+}
+
+// Bridge forwards every event received from the subscriber through the publisher.
+type Bridge struct {
+	subscriber *subscribers.Subscriber
+	publisher  *publishers.Publisher
+	channels   Channels
+	retryDelay time.Duration
+	consumer   *consumers.Consumer
+}
+
+// New instances a new bridge.
+func New(settings Settings) *Bridge {
+	if settings.RetryDelay <= 0 {
+		settings.RetryDelay = defaultRetryDelay
+	}
+
+	newBridge := Bridge{
+		subscriber: settings.Subscriber,
+		publisher:  settings.Publisher,
+		channels:   settings.Channels,
+		retryDelay: settings.RetryDelay,
+	}
+	// events still failing when the bridge stops are rejected so the source delivers them again.
+	newBridge.consumer = consumers.New(consumers.Settings{
+		Subscriber: settings.Subscriber,
+		Handler:    newBridge.forward,
+		Workers:    settings.Workers,
+		RetryDelay: settings.RetryDelay,
+	})
+
+	return &newBridge
+}
+
+// Run forwards events until the context is done or the source stream ends. Events are
+// acknowledged only after they are published into the destination, publishing is retried
+// until it succeeds so later events with the same ordering key wait for it.
+func (b *Bridge) Run(ctx context.Context) error {
+	return b.consumer.Run(ctx)
+}
+
+// Shutdown stops receiving events and waits for the ones being forwarded to be published and
+// acknowledged, the subscriber and publisher are left open for their owner to close.
+func (b *Bridge) Shutdown(ctx context.Context) error {
+	return b.consumer.Shutdown(ctx)
+}
+
+func (b *Bridge) forward(ctx context.Context, event messages.Event) error {
+	destination, err := b.destination(event)
+	if err != nil {
+		return err
+	}
+
+	// message id and channel belong to the source event bus, the destination sets its own.
+	messageID := event.Header.MessageID
+	event.Header.MessageID = ""
+	event.Header.Channel = ""
+
+	for {
+		err = b.publisher.Publish(ctx, publishers.EventMessage{ChannelName: destination, Event: event})
+		if err == nil {
+			return nil
+		}
+
+		log.Println("error", err, "message_id", messageID, "method", "bridge.Bridge.forward")
+
+		timer := time.NewTimer(b.retryDelay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Destination returns the destination channel of the source channel, a mapping of the
+// channel itself wins over patterns and the first matching pattern wins over the next ones.
+func (b *Bridge) Destination(source string) string {
+	for _, mapping := range b.channels {
+		if mapping.Source == source {
+			return mapping.Destination
+		}
+	}
+
+	for _, mapping := range b.channels {
+		if subscribers.IsChannelPattern(mapping.Source) && subscribers.MatchChannel(mapping.Source, source) {
+			return mapping.Destination
+		}
+	}
+
+	return source
+}
+
+// destination returns the destination channel of the event, events of event buses that do
+// not report the channel can only come from a single subscribed channel.
+func (b *Bridge) destination(event messages.Event) (string, error) {
+	source := event.Header.Channel
+	if source == "" {
+		channels := b.subscriber.Channels()
+		if len(channels) != 1 || subscribers.IsChannelPattern(channels[0]) {
+			return "", errors.WithMessagef(errUnknownChannel, "%q", event.Header.ID)
+		}
+
+		source = channels[0]
+	}
+
+	return b.Destination(source), nil
+}
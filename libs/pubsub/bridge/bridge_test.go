@@ -0,0 +1,293 @@
+package bridge_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/bridge"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridgeForwardsEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	source, destination := memory.New(), memory.New()
+	subscriber := subscribers.New(subscribers.Settings{EventBus: source.Subscriber()})
+	_ = subscriber.Subscribe(ctx, "orders")
+	_ = subscriber.Subscribe(ctx, "credit.*.created")
+	relay := bridge.New(bridge.Settings{
+		Subscriber: subscriber,
+		Publisher:  publishers.New(destination),
+		Channels:   bridge.Channels{{Source: "orders", Destination: "credit.orders"}},
+		Workers:    4,
+	})
+
+	go func() { _ = relay.Run(ctx) }()
+	// When
+	for idx := 0; idx < 5; idx++ {
+		_ = source.Publish(ctx, "orders", eventFixture(string(rune('a'+idx)), "account-1"))
+	}
+
+	_ = source.Publish(ctx, "credit.loan.created", eventFixture("z", ""))
+	// Then
+	assert.Eventually(t, func() bool {
+		return len(destination.Events("credit.orders")) == 5 && len(destination.Events("credit.loan.created")) == 1
+	}, time.Second, time.Millisecond)
+
+	forwarded := destination.Events("credit.orders")
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, ids(forwarded))
+	assert.Equal(t, "account-1", forwarded[0].Header.OrderingKey)
+	assert.Equal(t, "eu-west", forwarded[0].Header.Attribute("region"))
+	assert.Equal(t, "credit.orders/0", forwarded[0].Header.MessageID)
+}
+
+func TestBridgeDoesNotAcknowledgeFailedForwards(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	bus := memory.New()
+	source := &ackRecorder{EventBusSubscriber: bus.Subscriber()}
+	subscriber := subscribers.New(subscribers.Settings{EventBus: source})
+	_ = subscriber.Subscribe(ctx, "orders")
+	relay := bridge.New(bridge.Settings{
+		Subscriber: subscriber,
+		Publisher:  publishers.New(failingPublisher{}),
+	})
+	_ = bus.Publish(ctx, "orders", eventFixture("a", ""))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	// When
+	err := relay.Run(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, source.acknowledged)
+}
+
+func TestBridgeRetriesFailedForwardsInOrder(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	source, destination := memory.New(), memory.New()
+	subscriber := subscribers.New(subscribers.Settings{EventBus: source.Subscriber()})
+	_ = subscriber.Subscribe(ctx, "orders")
+	relay := bridge.New(bridge.Settings{
+		Subscriber: subscriber,
+		Publisher:  publishers.New(&failOncePublisher{EventBusPublisher: destination}),
+		Workers:    4,
+		RetryDelay: 10 * time.Millisecond,
+	})
+
+	go func() { _ = relay.Run(ctx) }()
+	// When
+	_ = source.Publish(ctx, "orders", eventFixture("a", "account-1"))
+	_ = source.Publish(ctx, "orders", eventFixture("b", "account-1"))
+	// Then
+	assert.Eventually(t, func() bool {
+		return len(destination.Events("orders")) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, ids(destination.Events("orders")))
+}
+
+func TestDestination(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	relay := bridge.New(bridge.Settings{Channels: bridge.Channels{
+		{Source: "orders", Destination: "credit.orders"},
+		{Source: "credit.*.created", Destination: "credit.created"},
+	}})
+	// Then
+	assert.Equal(t, "credit.orders", relay.Destination("orders"))
+	assert.Equal(t, "credit.created", relay.Destination("credit.loan.created"))
+	assert.Equal(t, "payments", relay.Destination("payments"))
+}
+
+func TestDestinationOfOverlappingPatterns(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	config, err := bridge.ReadConfig(strings.NewReader(`
+source:
+  url: memory://a
+  channels: [credit.>]
+channels:
+  credit.loan.*: loans
+  credit.*.created: created
+  credit.>: credit
+  credit.loan.created: loan-created
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	relay := bridge.New(bridge.Settings{Channels: config.Channels})
+
+	tests := map[string]string{
+		"credit.loan.created":    "loan-created",
+		"credit.loan.updated":    "loans",
+		"credit.payment.created": "created",
+		"credit.payment.updated": "credit",
+		"payments":               "payments",
+	}
+
+	for source, expected := range tests {
+		source, expected := source, expected
+
+		t.Run(source, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			for idx := 0; idx < 10; idx++ {
+				// Then
+				assert.Equal(t, expected, relay.Destination(source))
+			}
+		})
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	config, err := bridge.ReadConfig(strings.NewReader(`
+source:
+  url: memory://bridge-config-source
+  channels: [orders]
+destination:
+  url: memory://bridge-config-destination
+channels:
+  orders: credit.orders
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	relay, err := bridge.FromConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	source, _ := eventbus.OpenPublisher(ctx, "memory://bridge-config-source")
+	destination, _ := eventbus.OpenSubscriber(ctx, "memory://bridge-config-destination")
+	_ = destination.Subscribe(ctx, "credit.orders")
+
+	go func() { _ = relay.Run(ctx) }()
+	// When
+	_ = source.Publish(ctx, "orders", eventFixture("a", ""))
+	// Then
+	assert.Eventually(t, func() bool {
+		events, _ := destination.Pull(ctx, 0)
+
+		return len(events) == 1 && events[0].Header.ID == "a"
+	}, time.Second, time.Millisecond)
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"unknown field":      "source: {url: memory://a, channels: [orders]}\nretries: 3\n",
+		"no source channels": "source: {url: memory://a}\n",
+		"invalid position":   "source: {url: memory://a, channels: [orders], position: middle}\n",
+		"channels list":      "source: {url: memory://a, channels: [orders]}\nchannels: [orders]\n",
+	}
+
+	for name, content := range tests {
+		content := content
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			_, err := bridge.ReadConfig(strings.NewReader(content))
+			// Then
+			assert.Error(t, err)
+		})
+	}
+}
+
+// ackRecorder records the acknowledged messages of the wrapped event bus.
+type ackRecorder struct {
+	subscribers.EventBusSubscriber
+	acknowledged []string
+}
+
+func (a *ackRecorder) Acknowledge(ctx context.Context, id string) error {
+	a.acknowledged = append(a.acknowledged, id)
+
+	return a.EventBusSubscriber.Acknowledge(ctx, id)
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, string, interface{}) error {
+	return errors.New("destination unavailable")
+}
+
+// failOncePublisher fails the first publish and then publishes into the wrapped event bus.
+type failOncePublisher struct {
+	publishers.EventBusPublisher
+	failed int32
+}
+
+func (f *failOncePublisher) Publish(ctx context.Context, channel string, message interface{}) error {
+	if atomic.CompareAndSwapInt32(&f.failed, 0, 1) {
+		return errors.New("destination unavailable")
+	}
+
+	return f.EventBusPublisher.Publish(ctx, channel, message)
+}
+
+func eventFixture(id, orderingKey string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{
+			ID:          id,
+			Domain:      "loans",
+			EventType:   "orders",
+			Version:     "0.1.0",
+			Application: "core-app",
+			OrderingKey: orderingKey,
+			Attributes:  map[string]string{"region": "eu-west"},
+		},
+		Data: []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
+
+func ids(events []messages.Event) []string {
+	var result []string
+
+	for _, event := range events {
+		result = append(result, event.Header.ID)
+	}
+
+	return result
+}
@@ -0,0 +1,184 @@
+package bridge
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	positionEarliest = "earliest"
+	positionLatest   = "latest"
+)
+
+var (
+	errNoSourceChannels = errors.New("source must have channels")
+	errInvalidChannels  = errors.New("channels must map source channels to destination channels")
+	errInvalidPosition  = errors.New("source position must be earliest or latest")
+)
+
+// Config is the yaml configuration of a bridge.
+//
+//	source:
+//	  url: memory://legacy
+//	  channels: [orders, credit.*.created]
+//	  group: bridge
+//	  position: earliest
+//	destination:
+//	  url: memory://next
+//	channels:
+//	  orders: credit.orders
+//	workers: 4
+type Config struct {
+	Source      SourceConfig      `yaml:"source"`
+	Destination DestinationConfig `yaml:"destination"`
+	// Channels destination channel by source channel or channel pattern, patterns are tried
+	// in the order they are written.
+	Channels Channels `yaml:"channels"`
+	Workers  int      `yaml:"workers"`
+}
+
+// ChannelMapping maps a source channel or channel pattern to its destination channel.
+type ChannelMapping struct {
+	Source      string
+	Destination string
+}
+
+// Channels are the channel mappings of a bridge, in yaml they are a mapping of source channel
+// to destination channel whose order is kept.
+type Channels []ChannelMapping
+
+// UnmarshalYAML decodes the yaml mapping keeping the order of its keys.
+func (c *Channels) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.WithMessagef(errInvalidChannels, "line %d", node.Line)
+	}
+
+	channels := make(Channels, 0, len(node.Content)/2)
+
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		var mapping ChannelMapping
+
+		err := node.Content[idx].Decode(&mapping.Source)
+		if err != nil {
+			return err
+		}
+
+		err = node.Content[idx+1].Decode(&mapping.Destination)
+		if err != nil {
+			return err
+		}
+
+		channels = append(channels, mapping)
+	}
+
+	*c = channels
+
+	return nil
+}
+
+// SourceConfig configures the event bus events are consumed from.
+type SourceConfig struct {
+	URL      string   `yaml:"url"`
+	Channels []string `yaml:"channels"`
+	Group    string   `yaml:"group"`
+	Name     string   `yaml:"name"`
+	// Position where the channels are read from, earliest or latest, defaults to the event
+	// bus own starting point.
+	Position string `yaml:"position"`
+}
+
+// DestinationConfig configures the event bus events are published into.
+type DestinationConfig struct {
+	URL string `yaml:"url"`
+}
+
+// LoadConfig reads the yaml configuration file.
+func LoadConfig(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "could not open bridge configuration")
+	}
+
+	defer file.Close()
+
+	return ReadConfig(file)
+}
+
+// ReadConfig reads the yaml configuration.
+func ReadConfig(reader io.Reader) (Config, error) {
+	var config Config
+
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(&config)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "invalid bridge configuration")
+	}
+
+	if len(config.Source.Channels) == 0 {
+		return Config{}, errNoSourceChannels
+	}
+
+	switch config.Source.Position {
+	case "", positionEarliest, positionLatest:
+	default:
+		return Config{}, errors.WithMessagef(errInvalidPosition, "%q", config.Source.Position)
+	}
+
+	return config, nil
+}
+
+// FromConfig opens both event buses of the configuration and subscribes to the source channels.
+func FromConfig(ctx context.Context, config Config) (*Bridge, error) {
+	source, err := eventbus.OpenSubscriber(ctx, config.Source.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := eventbus.OpenPublisher(ctx, config.Destination.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: source,
+		Group:    config.Source.Group,
+		Name:     config.Source.Name,
+	})
+
+	for _, channel := range config.Source.Channels {
+		err = subscriber.Subscribe(ctx, channel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Source.Position != "" {
+		position := subscribers.Earliest()
+		if config.Source.Position == positionLatest {
+			position = subscribers.Latest()
+		}
+
+		for _, channel := range config.Source.Channels {
+			err = subscriber.Seek(ctx, channel, position)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return New(Settings{
+		Subscriber: subscriber,
+		Publisher:  publishers.New(destination),
+		Channels:   config.Channels,
+		Workers:    config.Workers,
+	}), nil
+}
@@ -0,0 +1,3 @@
+// Package bridge relays events from one event bus to another, like while migrating between
+// event buses, keeping the headers and the order of every ordering key.
+package bridge
@@ -0,0 +1,4 @@
+// Package capture reads and writes events as newline delimited json records, plain or
+// compressed, to dump channels, record what a subscriber receives and replay it into any
+// event bus with its original timing, accelerated or as fast as possible.
+package capture
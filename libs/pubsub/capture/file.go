@@ -0,0 +1,127 @@
+package capture
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GzipExtension extension of gzip compressed capture files.
+	GzipExtension = ".gz"
+	// ZstdExtension extension of zstd compressed capture files.
+	ZstdExtension = ".zst"
+)
+
+// FileWriter writes records into a capture file, compressed when the file extension is
+// GzipExtension or ZstdExtension.
+type FileWriter struct {
+	*Writer
+	file       *os.File
+	compressor io.WriteCloser
+}
+
+// Create creates the capture file, truncating it when it already exists.
+func Create(path string) (*FileWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create capture file")
+	}
+
+	var compressor io.WriteCloser
+
+	switch filepath.Ext(path) {
+	case GzipExtension:
+		compressor = gzip.NewWriter(file)
+	case ZstdExtension:
+		compressor, err = zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+
+			return nil, errors.Wrap(err, "could not create zstd writer")
+		}
+	}
+
+	newWriter := FileWriter{file: file, compressor: compressor}
+
+	if compressor != nil {
+		newWriter.Writer = NewWriter(compressor)
+	} else {
+		newWriter.Writer = NewWriter(file)
+	}
+
+	return &newWriter, nil
+}
+
+// Close flushes the pending records and closes the file.
+func (f *FileWriter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.compressor != nil {
+		err := f.compressor.Close()
+		if err != nil {
+			f.file.Close()
+
+			return errors.Wrap(err, "could not flush capture file")
+		}
+	}
+
+	return errors.Wrap(f.file.Close(), "could not close capture file")
+}
+
+// FileReader reads the records of a capture file, decompressing it when the file extension is
+// GzipExtension or ZstdExtension.
+type FileReader struct {
+	*Reader
+	file  *os.File
+	close func()
+}
+
+// Open opens the capture file.
+func Open(path string) (*FileReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open capture file")
+	}
+
+	newReader := FileReader{file: file, close: func() {}}
+
+	switch filepath.Ext(path) {
+	case GzipExtension:
+		decompressor, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+
+			return nil, errors.Wrap(err, "could not read gzip capture file")
+		}
+
+		newReader.Reader = NewReader(decompressor)
+		newReader.close = func() { decompressor.Close() }
+	case ZstdExtension:
+		decompressor, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+
+			return nil, errors.Wrap(err, "could not read zstd capture file")
+		}
+
+		newReader.Reader = NewReader(decompressor)
+		newReader.close = decompressor.Close
+	default:
+		newReader.Reader = NewReader(file)
+	}
+
+	return &newReader, nil
+}
+
+// Close closes the file.
+func (f *FileReader) Close() error {
+	f.close()
+
+	return errors.Wrap(f.file.Close(), "could not close capture file")
+}
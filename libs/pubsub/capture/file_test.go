@@ -0,0 +1,91 @@
+package capture_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		name       string
+		compressed bool
+	}{
+		"plain": {name: "events.ndjson"},
+		"gzip":  {name: "events.ndjson" + capture.GzipExtension, compressed: true},
+		"zstd":  {name: "events.ndjson" + capture.ZstdExtension, compressed: true},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			expected := recordsFixture(3)
+			path := filepath.Join(t.TempDir(), test.name)
+
+			writer, err := capture.Create(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, record := range expected {
+				_ = writer.Write(record)
+			}
+
+			closeErr := writer.Close()
+			// When
+			reader, err := capture.Open(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			defer reader.Close()
+
+			var records []capture.Record
+
+			for {
+				record, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				assert.NoError(t, err)
+
+				records = append(records, record)
+			}
+			// Then
+			content, _ := os.ReadFile(path)
+			assert.NoError(t, closeErr)
+			assert.Equal(t, expected, records)
+			assert.Equal(t, !test.compressed, content[0] == '{')
+		})
+	}
+}
+
+func recordsFixture(count int) []capture.Record {
+	records := make([]capture.Record, count)
+
+	for idx := range records {
+		id := string(rune('1' + idx))
+		records[idx] = capture.Record{
+			Channel: "orders",
+			Event: messages.Event{
+				Header: messages.Header{ID: id, Domain: "loans"},
+				Data:   []byte(`{"amount":` + id + `}`),
+			},
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,172 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+const maxRecordSize = 64 << 20
+
+// Record is an event of a channel, as one line of a capture file:
+//
+//	{"channel":"orders","time":"2022-08-01T10:00:00Z","header":{"id":"1","domain":"loans"},"data_base64":"e30="}
+//
+// Hand written files can give json data as "data" instead of "data_base64".
+type Record struct {
+	Channel string
+	// Time when the event was captured.
+	Time  time.Time
+	Event messages.Event
+}
+
+type jsonRecord struct {
+	Channel    string          `json:"channel,omitempty"`
+	Time       *time.Time      `json:"time,omitempty"`
+	Header     jsonHeader      `json:"header"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	DataBase64 []byte          `json:"data_base64,omitempty"`
+}
+
+type jsonHeader struct {
+	ID          string            `json:"id,omitempty"`
+	Domain      string            `json:"domain,omitempty"`
+	EventType   string            `json:"event_type,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Application string            `json:"application,omitempty"`
+	MessageID   string            `json:"message_id,omitempty"`
+	OrderingKey string            `json:"ordering_key,omitempty"`
+	Channel     string            `json:"channel,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+// MarshalJSON encodes the record with its data in base64.
+func (r Record) MarshalJSON() ([]byte, error) {
+	header := r.Event.Header
+	record := jsonRecord{
+		Channel: r.Channel,
+		Header: jsonHeader{
+			ID:          header.ID,
+			Domain:      header.Domain,
+			EventType:   header.EventType,
+			Version:     header.Version,
+			Application: header.Application,
+			MessageID:   header.MessageID,
+			OrderingKey: header.OrderingKey,
+			Channel:     header.Channel,
+			Attributes:  header.Attributes,
+		},
+		DataBase64: r.Event.Data,
+	}
+
+	if !r.Time.IsZero() {
+		record.Time = &r.Time
+	}
+
+	return json.Marshal(record)
+}
+
+// UnmarshalJSON decodes the record, json data is taken as it is.
+func (r *Record) UnmarshalJSON(content []byte) error {
+	var record jsonRecord
+
+	err := json.Unmarshal(content, &record)
+	if err != nil {
+		return err
+	}
+
+	header := record.Header
+	*r = Record{
+		Channel: record.Channel,
+		Event: messages.Event{
+			Header: messages.Header{
+				ID:          header.ID,
+				Domain:      header.Domain,
+				EventType:   header.EventType,
+				Version:     header.Version,
+				Application: header.Application,
+				MessageID:   header.MessageID,
+				OrderingKey: header.OrderingKey,
+				Channel:     header.Channel,
+				Attributes:  header.Attributes,
+			},
+			Data: record.DataBase64,
+		},
+	}
+
+	if record.Time != nil {
+		r.Time = *record.Time
+	}
+
+	if len(record.Data) > 0 {
+		r.Event.Data = []byte(record.Data)
+	}
+
+	return nil
+}
+
+// Writer writes records as newline delimited json, it is safe for concurrent use.
+type Writer struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriter instances a new record writer.
+func NewWriter(writer io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(writer)}
+}
+
+// Write writes the record in its own line.
+func (w *Writer) Write(record Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return errors.Wrap(w.encoder.Encode(record), "could not write record")
+}
+
+// Reader reads records from newline delimited json, empty lines are skipped.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader instances a new record reader.
+func NewReader(reader io.Reader) *Reader {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRecordSize)
+
+	return &Reader{scanner: scanner}
+}
+
+// Read returns the next record or io.EOF when there are no more.
+func (r *Reader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record Record
+
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return Record{}, errors.Wrapf(err, "invalid record in line %d", r.line)
+		}
+
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, errors.Wrap(err, "could not read records")
+	}
+
+	return Record{}, io.EOF
+}
@@ -0,0 +1,115 @@
+package capture_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterAndReaderRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expected := []capture.Record{
+		{
+			Channel: "orders",
+			Time:    time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC),
+			Event: messages.Event{
+				Header: messages.Header{
+					ID:          "1",
+					Domain:      "loans",
+					EventType:   "created",
+					Version:     "2",
+					MessageID:   "orders/0",
+					OrderingKey: "account-1",
+					Attributes:  map[string]string{"region": "eu-west"},
+				},
+				Data: []byte{0xff, 0x00, 0x01},
+			},
+		},
+		{Channel: "payments", Event: messages.Event{Header: messages.Header{ID: "2"}, Data: []byte(`{}`)}},
+	}
+
+	var buffer bytes.Buffer
+
+	writer := capture.NewWriter(&buffer)
+	// When
+	for _, record := range expected {
+		if err := writer.Write(record); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	reader := capture.NewReader(&buffer)
+	first, firstErr := reader.Read()
+	second, secondErr := reader.Read()
+	_, endErr := reader.Read()
+	// Then
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, expected, []capture.Record{first, second})
+	assert.True(t, errors.Is(endErr, io.EOF))
+}
+
+func TestReaderAcceptsHandWrittenJSONData(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	input := `
+{"channel":"orders","header":{"id":"1","domain":"loans"},"data":{"amount": 10}}
+
+`
+	reader := capture.NewReader(strings.NewReader(input))
+	// When
+	record, err := reader.Read()
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "loans", record.Event.Header.Domain)
+	assert.JSONEq(t, `{"amount": 10}`, string(record.Event.Data))
+	assert.True(t, record.Time.IsZero())
+}
+
+func TestRecordHeaderKeysAreSnakeCase(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	record := capture.Record{
+		Channel: "orders",
+		Event: messages.Event{
+			Header: messages.Header{
+				ID:          "1",
+				EventType:   "created",
+				MessageID:   "orders/0",
+				OrderingKey: "account-1",
+			},
+		},
+	}
+	// When
+	got, err := record.MarshalJSON()
+	// Then
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"channel": "orders",
+		"header": {"id": "1", "event_type": "created", "message_id": "orders/0", "ordering_key": "account-1"}
+	}`, string(got))
+}
+
+func TestReaderReportsInvalidLines(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	reader := capture.NewReader(strings.NewReader("{\"header\":{}}\nnot json\n"))
+	_, _ = reader.Read()
+	// When
+	_, err := reader.Read()
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
@@ -0,0 +1,106 @@
+package capture
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AsFastAsPossible speed publishing the records one after the other without waiting.
+	AsFastAsPossible = 0
+	// OriginalTiming speed keeping the time between records as they were captured.
+	OriginalTiming = 1
+)
+
+// ErrNoChannel is returned when replaying a record without channel and no channel is set.
+var ErrNoChannel = errors.New("record has no channel")
+
+// ReplaySettings contains the replay configuration.
+type ReplaySettings struct {
+	// Speed factor applied to the time between records, OriginalTiming keeps it, 10 replays
+	// ten times faster and AsFastAsPossible does not wait at all.
+	Speed float64
+	// Channel publishes every record into this channel instead of the captured one.
+	Channel string
+}
+
+// Replay publishes the records of the reader into the event bus until io.EOF, waiting between them as the
+// settings speed says. Records without capture time are published right away. It returns the
+// number of published records.
+func Replay(ctx context.Context, reader *Reader, eventBus publishers.EventBusPublisher, settings ReplaySettings) (int, error) {
+	publisher := publishers.New(eventBus)
+
+	var (
+		count      int
+		start      time.Time
+		firstTime  time.Time
+		recordTime time.Time
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+
+		if err != nil {
+			return count, err
+		}
+
+		if !record.Time.IsZero() {
+			recordTime = record.Time
+		}
+
+		if firstTime.IsZero() && !recordTime.IsZero() {
+			firstTime, start = recordTime, time.Now()
+		}
+
+		if settings.Speed > AsFastAsPossible && !firstTime.IsZero() {
+			offset := time.Duration(float64(recordTime.Sub(firstTime)) / settings.Speed)
+
+			err = wait(ctx, time.Until(start.Add(offset)))
+			if err != nil {
+				return count, err
+			}
+		}
+
+		channel := record.Channel
+		if settings.Channel != "" {
+			channel = settings.Channel
+		}
+
+		if channel == "" {
+			return count, errors.WithMessagef(ErrNoChannel, "event %q", record.Event.Header.ID)
+		}
+
+		record.Event.Header.MessageID = ""
+		record.Event.Header.Channel = ""
+
+		err = publisher.Publish(ctx, publishers.EventMessage{ChannelName: channel, Event: record.Event})
+		if err != nil {
+			return count, err
+		}
+
+		count++
+	}
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "replay cancelled")
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+package capture_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaySpeeds(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		speed       float64
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		"original timing": {
+			speed:       capture.OriginalTiming,
+			minDuration: 200 * time.Millisecond,
+			maxDuration: time.Second,
+		},
+		"accelerated": {
+			speed:       4,
+			minDuration: 50 * time.Millisecond,
+			maxDuration: 200 * time.Millisecond,
+		},
+		"as fast as possible": {
+			speed:       capture.AsFastAsPossible,
+			maxDuration: 50 * time.Millisecond,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			bus := memory.New()
+			reader := capture.NewReader(timedCaptureFixture(t, 100*time.Millisecond))
+			start := time.Now()
+			// When
+			count, err := capture.Replay(context.TODO(), reader, bus, capture.ReplaySettings{Speed: test.speed})
+			// Then
+			elapsed := time.Since(start)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, count)
+			assert.Len(t, bus.Events("orders"), 3)
+			assert.GreaterOrEqual(t, elapsed, test.minDuration)
+			assert.Less(t, elapsed, test.maxDuration)
+		})
+	}
+}
+
+func TestReplayOverridesChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	bus := memory.New()
+	reader := capture.NewReader(timedCaptureFixture(t, 0))
+	// When
+	_, err := capture.Replay(context.TODO(), reader, bus, capture.ReplaySettings{Channel: "payments"})
+	// Then
+	events := bus.Events("payments")
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, "payments/0", events[0].Header.MessageID)
+}
+
+func TestReplayRequiresChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var buffer bytes.Buffer
+
+	record := recordsFixture(1)[0]
+	record.Channel = ""
+	_ = capture.NewWriter(&buffer).Write(record)
+	// When
+	count, err := capture.Replay(context.TODO(), capture.NewReader(&buffer), memory.New(), capture.ReplaySettings{})
+	// Then
+	assert.Zero(t, count)
+	assert.True(t, errors.Is(err, capture.ErrNoChannel))
+}
+
+func TestReplayHonoursContext(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+
+	defer cancel()
+
+	reader := capture.NewReader(timedCaptureFixture(t, time.Hour))
+	// When
+	count, err := capture.Replay(ctx, reader, memory.New(), capture.ReplaySettings{Speed: capture.OriginalTiming})
+	// Then
+	assert.Equal(t, 1, count)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestSubscriberCapturesReceivedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	bus := memory.New()
+
+	var buffer bytes.Buffer
+
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(bus.Subscriber(), capture.Subscriber(capture.NewWriter(&buffer))),
+		MessagesPerPull: 10,
+	})
+	_ = subscriber.Subscribe(ctx, "orders")
+
+	for _, record := range recordsFixture(2) {
+		_ = bus.Publish(ctx, record.Channel, record.Event)
+	}
+	// When
+	events, err := subscriber.Pull(ctx)
+	// Then
+	reader := capture.NewReader(&buffer)
+	first, _ := reader.Read()
+	second, _ := reader.Read()
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, events[0], first.Event)
+	assert.Equal(t, "orders", second.Channel)
+	assert.Equal(t, "orders/1", second.Event.Header.MessageID)
+	assert.False(t, first.Time.IsZero())
+}
+
+// timedCaptureFixture returns a capture of three events recorded the gap apart.
+func timedCaptureFixture(t *testing.T, gap time.Duration) *bytes.Buffer {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	writer := capture.NewWriter(&buffer)
+	start := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	for idx, record := range recordsFixture(3) {
+		record.Time = start.Add(time.Duration(idx) * gap)
+
+		err := writer.Write(record)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	return &buffer
+}
@@ -0,0 +1,23 @@
+package capture
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+// Subscriber returns a subscriber middleware that records every received event into the
+// writer. Events are delivered even when recording them fails, the error is logged.
+func Subscriber(writer *Writer) subscribers.Middleware {
+	return subscribers.Transform(func(_ context.Context, event messages.Event) (messages.Event, error) {
+		err := writer.Write(Record{Channel: event.Header.Channel, Time: time.Now().UTC(), Event: event})
+		if err != nil {
+			log.Println("error", err, "message_id", event.Header.MessageID, "method", "capture.Subscriber")
+		}
+
+		return event, nil
+	})
+}
@@ -0,0 +1,262 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// State state of a circuit breaker.
+type State int
+
+const (
+	// Closed calls go through, failures are counted.
+	Closed State = iota
+	// Open calls fail fast with ErrOpen until the open timeout is over.
+	Open
+	// HalfOpen a few trial calls go through to check the event bus recovered.
+	HalfOpen
+)
+
+const (
+	// DefaultFailureThreshold consecutive failures that open the circuit.
+	DefaultFailureThreshold = 5
+	// DefaultOpenTimeout time the circuit stays open before trying again.
+	DefaultOpenTimeout = 30 * time.Second
+	// DefaultHalfOpenCalls successful trial calls that close the circuit again.
+	DefaultHalfOpenCalls = 1
+)
+
+// outcome of a call to the event bus.
+type outcome int
+
+const (
+	succeeded outcome = iota
+	failed
+	// cancelled calls tell nothing about the event bus, they were given up by their caller.
+	cancelled
+)
+
+// ErrOpen is returned without calling the event bus while the circuit is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Settings contains the circuit breaker configuration.
+type Settings struct {
+	// Name of the protected event bus, it is shown in errors and health reports.
+	Name string
+	// FailureThreshold consecutive failures that open the circuit, DefaultFailureThreshold
+	// when it is zero.
+	FailureThreshold int
+	// OpenTimeout time the circuit stays open before letting trial calls through,
+	// DefaultOpenTimeout when it is zero.
+	OpenTimeout time.Duration
+	// HalfOpenCalls trial calls let through while half-open, all of them must succeed to close
+	// the circuit. DefaultHalfOpenCalls when it is zero.
+	HalfOpenCalls int
+	// CallTimeout maximum time of every call to the event bus, calls only end with the caller
+	// context when it is zero.
+	CallTimeout time.Duration
+	// IsFailure tells whether an error counts as an event bus failure, every error does when
+	// it is nil. Calls cancelled by their caller count neither as failures nor as successes,
+	// calls whose caller deadline is exceeded always count as failures.
+	IsFailure func(err error) bool
+	// OnStateChange is called on every state change.
+	OnStateChange func(from, to State)
+	// Now returns the current time, time.Now when it is nil.
+	Now func() time.Time
+}
+
+// Breaker is a circuit breaker, it is safe for concurrent use.
+type Breaker struct {
+	settings Settings
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	// trials calls let through since the circuit went half-open and successes how many of
+	// them succeeded.
+	trials    int
+	successes int
+}
+
+// New instances a new closed circuit breaker.
+func New(settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultFailureThreshold
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = DefaultOpenTimeout
+	}
+
+	if settings.HalfOpenCalls <= 0 {
+		settings.HalfOpenCalls = DefaultHalfOpenCalls
+	}
+
+	if settings.IsFailure == nil {
+		settings.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	return &Breaker{settings: settings}
+}
+
+// Name returns the name of the protected event bus.
+func (b *Breaker) Name() string {
+	return b.settings.Name
+}
+
+// State returns the current state, an open circuit whose timeout is over is half-open.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	expired := b.expire()
+	state := b.state
+	b.mu.Unlock()
+
+	if expired {
+		b.changed(Open, HalfOpen)
+	}
+
+	return state
+}
+
+// Healthy reports whether the event bus is usable, it is not while the circuit is open.
+func (b *Breaker) Healthy() bool {
+	return b.State() != Open
+}
+
+// Do calls the function unless the circuit is open, the outcome updates the circuit state.
+func (b *Breaker) Do(ctx context.Context, call func(ctx context.Context) error) error {
+	err := b.allow()
+	if err != nil {
+		return err
+	}
+
+	callCtx := ctx
+
+	if b.settings.CallTimeout > 0 {
+		var cancel context.CancelFunc
+
+		callCtx, cancel = context.WithTimeout(ctx, b.settings.CallTimeout)
+		defer cancel()
+	}
+
+	err = call(callCtx)
+
+	b.record(b.outcome(ctx, err))
+
+	return err
+}
+
+// outcome classifies the result of a call made with the caller context.
+func (b *Breaker) outcome(ctx context.Context, err error) outcome {
+	switch {
+	case err == nil:
+		return succeeded
+	case errors.Is(ctx.Err(), context.Canceled):
+		return cancelled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), b.settings.IsFailure(err):
+		return failed
+	default:
+		return succeeded
+	}
+}
+
+// allow reserves a call, it fails while the circuit is open or half-open with every trial
+// call taken.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	expired := b.expire()
+
+	var err error
+
+	switch {
+	case b.state == Open, b.state == HalfOpen && b.trials >= b.settings.HalfOpenCalls:
+		err = errors.WithMessagef(ErrOpen, "%q", b.settings.Name)
+	case b.state == HalfOpen:
+		b.trials++
+	}
+
+	b.mu.Unlock()
+
+	if expired {
+		b.changed(Open, HalfOpen)
+	}
+
+	return err
+}
+
+// record updates the state with the outcome of a call, a cancelled trial call gives its place
+// back to the next call.
+func (b *Breaker) record(result outcome) {
+	b.mu.Lock()
+
+	from := b.state
+
+	switch {
+	case result == cancelled:
+		if b.state == HalfOpen && b.trials > 0 {
+			b.trials--
+		}
+	case result == failed && b.state == HalfOpen:
+		b.open()
+	case result == failed && b.state == Closed:
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.open()
+		}
+	case b.state == HalfOpen:
+		b.successes++
+		if b.successes >= b.settings.HalfOpenCalls {
+			b.state, b.failures = Closed, 0
+		}
+	case b.state == Closed:
+		b.failures = 0
+	}
+
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+}
+
+// expire turns an open circuit half-open once its timeout is over and reports whether it did,
+// the lock must be held.
+func (b *Breaker) expire() bool {
+	if b.state != Open || b.settings.Now().Sub(b.openedAt) < b.settings.OpenTimeout {
+		return false
+	}
+
+	b.state, b.trials, b.successes = HalfOpen, 0, 0
+
+	return true
+}
+
+// open opens the circuit, the lock must be held.
+func (b *Breaker) open() {
+	b.state, b.openedAt, b.failures = Open, b.settings.Now(), 0
+}
+
+func (b *Breaker) changed(from, to State) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,355 @@
+package circuitbreaker_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/circuitbreaker"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnavailable = errors.New("broker unavailable")
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	clock := newClock()
+	eventBus := &flakyEventBusMock{err: errUnavailable}
+	breaker := circuitbreaker.New(circuitbreaker.Settings{Name: "kafka", FailureThreshold: 3, Now: clock.now})
+	publisher := publishers.New(publishers.Chain(eventBus, breaker.Publisher()))
+	// When
+	for idx := 0; idx < 3; idx++ {
+		_ = publisher.Publish(context.TODO(), eventMessageFixture())
+	}
+
+	err := publisher.Publish(context.TODO(), eventMessageFixture())
+	// Then
+	assert.True(t, errors.Is(err, circuitbreaker.ErrOpen))
+	assert.Contains(t, err.Error(), `"kafka"`)
+	assert.Equal(t, 3, eventBus.count())
+	assert.Equal(t, circuitbreaker.Open, breaker.State())
+	assert.False(t, breaker.Healthy())
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(flakyEventBusMock)
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 2})
+	publisher := publishers.Chain(eventBus, breaker.Publisher())
+	// When
+	for _, err := range []error{errUnavailable, nil, errUnavailable, nil} {
+		eventBus.fail(err)
+		_ = publisher.Publish(context.TODO(), "orders", eventMessageFixture().Event)
+	}
+	// Then
+	assert.Equal(t, circuitbreaker.Closed, breaker.State())
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		trialErr      error
+		expectedState circuitbreaker.State
+	}{
+		"trial succeeds": {
+			expectedState: circuitbreaker.Closed,
+		},
+		"trial fails": {
+			trialErr:      errUnavailable,
+			expectedState: circuitbreaker.Open,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			clock := newClock()
+
+			var changes []string
+
+			breaker := circuitbreaker.New(circuitbreaker.Settings{
+				FailureThreshold: 1,
+				OpenTimeout:      time.Minute,
+				Now:              clock.now,
+				OnStateChange: func(from, to circuitbreaker.State) {
+					changes = append(changes, from.String()+">"+to.String())
+				},
+			})
+			_ = breaker.Do(context.TODO(), func(context.Context) error { return errUnavailable })
+			clock.advance(time.Minute)
+			// When
+			state := breaker.State()
+			err := breaker.Do(context.TODO(), func(context.Context) error { return test.trialErr })
+			// Then
+			assert.Equal(t, circuitbreaker.HalfOpen, state)
+			assert.Equal(t, test.trialErr, err)
+			assert.Equal(t, test.expectedState, breaker.State())
+			assert.Equal(t, []string{"closed>open", "open>half-open", "half-open>" + test.expectedState.String()}, changes)
+		})
+	}
+}
+
+func TestBreakerLimitsHalfOpenTrials(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	clock := newClock()
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1, Now: clock.now})
+	_ = breaker.Do(context.TODO(), func(context.Context) error { return errUnavailable })
+	clock.advance(circuitbreaker.DefaultOpenTimeout)
+
+	started, release := make(chan struct{}), make(chan struct{})
+
+	go func() {
+		_ = breaker.Do(context.TODO(), func(context.Context) error {
+			close(started)
+			<-release
+
+			return nil
+		})
+	}()
+
+	<-started
+	// When
+	err := breaker.Do(context.TODO(), func(context.Context) error { return nil })
+	close(release)
+	// Then
+	assert.True(t, errors.Is(err, circuitbreaker.ErrOpen))
+	assert.Eventually(t, func() bool { return breaker.State() == circuitbreaker.Closed }, time.Second, time.Millisecond)
+}
+
+func TestBreakerIgnoresCallerCancellation(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithCancel(context.TODO())
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 2})
+	_ = breaker.Do(context.TODO(), func(context.Context) error { return errUnavailable })
+
+	cancel()
+	// When
+	err := breaker.Do(ctx, func(ctx context.Context) error { return ctx.Err() })
+	stateAfterCancellation := breaker.State()
+	_ = breaker.Do(context.TODO(), func(context.Context) error { return errUnavailable })
+	// Then
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, circuitbreaker.Closed, stateAfterCancellation)
+	assert.Equal(t, circuitbreaker.Open, breaker.State())
+}
+
+func TestBreakerCancelledTrialKeepsHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	clock := newClock()
+	ctx, cancel := context.WithCancel(context.TODO())
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1, Now: clock.now})
+	_ = breaker.Do(context.TODO(), func(context.Context) error { return errUnavailable })
+	clock.advance(circuitbreaker.DefaultOpenTimeout)
+
+	cancel()
+	// When
+	cancelledErr := breaker.Do(ctx, func(ctx context.Context) error { return ctx.Err() })
+	stateAfterCancellation := breaker.State()
+	trialErr := breaker.Do(context.TODO(), func(context.Context) error { return nil })
+	// Then
+	assert.True(t, errors.Is(cancelledErr, context.Canceled))
+	assert.Equal(t, circuitbreaker.HalfOpen, stateAfterCancellation)
+	assert.NoError(t, trialErr)
+	assert.Equal(t, circuitbreaker.Closed, breaker.State())
+}
+
+func TestBreakerCallerDeadlineCountsAsFailure(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	breaker := circuitbreaker.New(circuitbreaker.Settings{})
+	// When
+	for idx := 0; idx < circuitbreaker.DefaultFailureThreshold; idx++ {
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+		_ = breaker.Do(ctx, func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		})
+
+		cancel()
+	}
+	// Then
+	assert.Equal(t, circuitbreaker.Open, breaker.State())
+}
+
+func TestBreakerCallTimeoutCountsAsFailure(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1, CallTimeout: 10 * time.Millisecond})
+	// When
+	err := breaker.Do(context.TODO(), func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, circuitbreaker.Open, breaker.State())
+}
+
+func TestBreakerIsFailure(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	breaker := circuitbreaker.New(circuitbreaker.Settings{
+		FailureThreshold: 1,
+		IsFailure:        func(err error) bool { return !errors.Is(err, messages.ErrUnsupportedMessage) },
+	})
+	publisher := publishers.Chain(memory.New(), breaker.Publisher())
+	// When
+	err := publisher.Publish(context.TODO(), "orders", "not an event")
+	// Then
+	assert.True(t, errors.Is(err, messages.ErrUnsupportedMessage))
+	assert.Equal(t, circuitbreaker.Closed, breaker.State())
+}
+
+func TestBreakerSubscriber(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	bus := memory.New()
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1})
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(bus.Subscriber(), breaker.Subscriber()),
+		MessagesPerPull: 10,
+	})
+	_ = subscriber.Subscribe(ctx, "orders")
+	_ = bus.Publish(ctx, "orders", eventMessageFixture().Event)
+	// When
+	events, pullErr := subscriber.Pull(ctx)
+	ackErr := subscriber.Acknowledge(ctx, "orders/9")
+	_, openErr := subscriber.Pull(ctx)
+	seekErr := subscriber.Seek(ctx, "orders", subscribers.Earliest())
+	// Then
+	assert.NoError(t, pullErr)
+	assert.Len(t, events, 1)
+	assert.Error(t, ackErr)
+	assert.True(t, errors.Is(openErr, circuitbreaker.ErrOpen))
+	assert.NoError(t, seekErr, "optional features are reachable through the breaker")
+}
+
+func TestBreakerPublishBatch(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := &batchEventBusMock{err: errUnavailable}
+	breaker := circuitbreaker.New(circuitbreaker.Settings{FailureThreshold: 1})
+	publisher := publishers.New(publishers.Chain(eventBus, breaker.Publisher()))
+	events := []publishers.EventMessage{eventMessageFixture(), eventMessageFixture()}
+	// When
+	_, firstErr := publisher.PublishBatch(context.TODO(), events)
+	results, err := publisher.PublishBatch(context.TODO(), events)
+	// Then
+	assert.Error(t, firstErr)
+	assert.Error(t, err)
+	assert.Equal(t, 1, eventBus.batches)
+	assert.True(t, errors.Is(results[1].Err, circuitbreaker.ErrOpen))
+}
+
+func eventMessageFixture() publishers.EventMessage {
+	return publishers.EventMessage{
+		ChannelName: "orders",
+		Event: messages.Event{
+			Header: messages.Header{ID: "1", Domain: "loans", EventType: "created", Version: "1"},
+			Data:   []byte(`{}`),
+		},
+	}
+}
+
+type flakyEventBusMock struct {
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (f *flakyEventBusMock) Publish(context.Context, string, interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	return f.err
+}
+
+func (f *flakyEventBusMock) fail(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+func (f *flakyEventBusMock) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+type clock struct {
+	mu      sync.Mutex
+	current time.Time
+}
+
+func newClock() *clock {
+	return &clock{current: time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)}
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.current
+}
+
+func (c *clock) advance(duration time.Duration) {
+	c.mu.Lock()
+	c.current = c.current.Add(duration)
+	c.mu.Unlock()
+}
+
+type batchEventBusMock struct {
+	err     error
+	batches int
+}
+
+func (b *batchEventBusMock) Publish(context.Context, string, interface{}) error {
+	return b.err
+}
+
+func (b *batchEventBusMock) PublishBatch(_ context.Context, _ string, batch []interface{}) []error {
+	b.batches++
+
+	errs := make([]error, len(batch))
+	for idx := range errs {
+		errs[idx] = b.err
+	}
+
+	return errs
+}
+
+func (b *batchEventBusMock) MaxBatchSize() int {
+	return 0
+}
@@ -0,0 +1,3 @@
+// Package circuitbreaker stops calling a degraded event bus for a while after repeated
+// failures, so publishers and subscribers fail fast instead of waiting for every timeout.
+package circuitbreaker
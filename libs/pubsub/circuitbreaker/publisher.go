@@ -0,0 +1,107 @@
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+)
+
+var errDelaysNotSupported = errors.New("event bus does not support delays")
+
+// Publisher returns a publisher middleware that publishes through the circuit breaker, native
+// batches and delays of the event bus are kept.
+func (b *Breaker) Publisher() publishers.Middleware {
+	return func(next publishers.EventBusPublisher) publishers.EventBusPublisher {
+		return &publisher{next: next, breaker: b}
+	}
+}
+
+type publisher struct {
+	next    publishers.EventBusPublisher
+	breaker *Breaker
+}
+
+func (p *publisher) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	return p.breaker.Do(ctx, func(ctx context.Context) error {
+		return p.next.Publish(ctx, messageChannel, message)
+	})
+}
+
+// PublishBatch publishes the batch as one call when the event bus has a native batch api, it
+// counts as failed when every message failed. Otherwise every message is a call of its own.
+func (p *publisher) PublishBatch(ctx context.Context, messageChannel string, batch []interface{}) []error {
+	batchPublisher, ok := p.next.(publishers.BatchEventBusPublisher)
+	if !ok {
+		errs := make([]error, len(batch))
+		for idx, message := range batch {
+			errs[idx] = p.Publish(ctx, messageChannel, message)
+		}
+
+		return errs
+	}
+
+	var errs []error
+
+	err := p.breaker.Do(ctx, func(ctx context.Context) error {
+		errs = batchPublisher.PublishBatch(ctx, messageChannel, batch)
+
+		return allFailed(errs)
+	})
+	if errs == nil && err != nil {
+		errs = make([]error, len(batch))
+		for idx := range errs {
+			errs[idx] = err
+		}
+	}
+
+	return errs
+}
+
+// MaxBatchSize returns the maximum batch size of the event bus.
+func (p *publisher) MaxBatchSize() int {
+	if batchPublisher, ok := p.next.(publishers.BatchEventBusPublisher); ok {
+		return batchPublisher.MaxBatchSize()
+	}
+
+	return 0
+}
+
+// PublishDelayed publishes the delayed message through the circuit breaker.
+func (p *publisher) PublishDelayed(
+	ctx context.Context, messageChannel string, message interface{}, delay time.Duration,
+) error {
+	delayPublisher, ok := p.next.(publishers.DelayEventBusPublisher)
+	if !ok {
+		return errDelaysNotSupported
+	}
+
+	return p.breaker.Do(ctx, func(ctx context.Context) error {
+		return delayPublisher.PublishDelayed(ctx, messageChannel, message, delay)
+	})
+}
+
+// MaxDelay returns the maximum delay of the event bus.
+func (p *publisher) MaxDelay() time.Duration {
+	if delayPublisher, ok := p.next.(publishers.DelayEventBusPublisher); ok {
+		return delayPublisher.MaxDelay()
+	}
+
+	return 0
+}
+
+// allFailed returns the first error when every message of the batch failed.
+func allFailed(errs []error) error {
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs[0]
+}
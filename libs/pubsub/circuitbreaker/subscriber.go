@@ -0,0 +1,68 @@
+package circuitbreaker
+
+import (
+	"context"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+// Subscriber returns a subscriber middleware that subscribes, pulls, opens streams and
+// acknowledges through the circuit breaker.
+func (b *Breaker) Subscriber() subscribers.Middleware {
+	return func(next subscribers.EventBusSubscriber) subscribers.EventBusSubscriber {
+		return &subscriber{EventBusSubscriber: next, breaker: b}
+	}
+}
+
+type subscriber struct {
+	subscribers.EventBusSubscriber
+	breaker *Breaker
+}
+
+func (s *subscriber) Pull(ctx context.Context, numberOfMessages uint8) ([]messages.Event, error) {
+	var events []messages.Event
+
+	err := s.breaker.Do(ctx, func(ctx context.Context) error {
+		var err error
+
+		events, err = s.EventBusSubscriber.Pull(ctx, numberOfMessages)
+
+		return err
+	})
+
+	return events, err
+}
+
+// Stream opens the stream through the circuit breaker, the call timeout does not apply to the
+// stream itself.
+func (s *subscriber) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	var stream <-chan messages.Event
+
+	err := s.breaker.Do(ctx, func(context.Context) error {
+		var err error
+
+		stream, err = s.EventBusSubscriber.Stream(ctx)
+
+		return err
+	})
+
+	return stream, err
+}
+
+func (s *subscriber) Subscribe(ctx context.Context, channel string) error {
+	return s.breaker.Do(ctx, func(ctx context.Context) error {
+		return s.EventBusSubscriber.Subscribe(ctx, channel)
+	})
+}
+
+func (s *subscriber) Acknowledge(ctx context.Context, ID string) error {
+	return s.breaker.Do(ctx, func(ctx context.Context) error {
+		return s.EventBusSubscriber.Acknowledge(ctx, ID)
+	})
+}
+
+// Unwrap returns the wrapped event bus.
+func (s *subscriber) Unwrap() subscribers.EventBusSubscriber {
+	return s.EventBusSubscriber
+}
@@ -0,0 +1,248 @@
+package claimcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReferenceAttribute header attribute with the blob store key of the event data.
+	ReferenceAttribute = "claimcheck"
+
+	// DefaultThreshold leaves room for the headers below the 256KB sqs and sns limit.
+	DefaultThreshold = 192 << 10
+
+	// DefaultPendingExpiry is longer than the maximum sqs visibility timeout, so events still
+	// unacknowledged by then were delivered again under a new receipt handle.
+	DefaultPendingExpiry = 12 * time.Hour
+
+	keySuffixSize = 8
+)
+
+// Settings contains the claim check configuration.
+type Settings struct {
+	// Store where the data of large events is kept.
+	Store BlobStore
+	// Threshold data size in bytes above which data is moved to the store, DefaultThreshold
+	// when it is zero.
+	Threshold int
+	// RetainOnAcknowledge keeps the data in the store after the event is acknowledged. Set it
+	// when several consumer groups read the channel and let the store expire the data instead.
+	RetainOnAcknowledge bool
+	// PendingExpiry time an unacknowledged event is remembered to delete its data on
+	// acknowledgement, DefaultPendingExpiry when it is zero. The data of events forgotten
+	// after it must be expired by the store.
+	PendingExpiry time.Duration
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+}
+
+// Subscriber returns a subscriber middleware that fetches the data of claim checked events
+// and deletes it from the store once the event is acknowledged. Rejected events keep their
+// data, they are delivered again.
+func Subscriber(settings Settings) subscribers.Middleware {
+	if settings.PendingExpiry <= 0 {
+		settings.PendingExpiry = DefaultPendingExpiry
+	}
+
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	return func(next subscribers.EventBusSubscriber) subscribers.EventBusSubscriber {
+		newSubscriber := &subscriber{
+			store:   settings.Store,
+			retain:  settings.RetainOnAcknowledge,
+			expiry:  settings.PendingExpiry,
+			now:     settings.Now,
+			pending: make(map[string]pendingCheck),
+		}
+		newSubscriber.EventBusSubscriber = subscribers.Transform(newSubscriber.checkOut)(next)
+
+		// rejections are only seen when the event bus rejects natively, emulated ones deliver
+		// the checked out event again without going through this middleware.
+		if nacker := findNacker(next); nacker != nil {
+			return &nackSubscriber{subscriber: newSubscriber, nacker: nacker}
+		}
+
+		return newSubscriber
+	}
+}
+
+type subscriber struct {
+	subscribers.EventBusSubscriber
+	store   BlobStore
+	retain  bool
+	expiry  time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	pending map[string]pendingCheck // blob keys by message id.
+	sweptAt time.Time
+}
+
+type pendingCheck struct {
+	key        string
+	receivedAt time.Time
+}
+
+// nackSubscriber is the subscriber of event buses with native rejections.
+type nackSubscriber struct {
+	*subscriber
+	nacker subscribers.NackEventBusSubscriber
+}
+
+func (s *subscriber) checkOut(ctx context.Context, event messages.Event) (messages.Event, error) {
+	key := event.Header.Attribute(ReferenceAttribute)
+	if key == "" {
+		return event, nil
+	}
+
+	data, err := s.store.Get(ctx, key)
+	if err != nil {
+		return messages.Event{}, errors.WithMessagef(err, "could not check out event %q", event.Header.ID)
+	}
+
+	if !s.retain {
+		s.remember(event.Header.MessageID, key)
+	}
+
+	event.Header = event.Header.WithoutAttribute(ReferenceAttribute)
+	event.Data = data
+
+	return event, nil
+}
+
+// Acknowledge acknowledges the message and then deletes its data from the store, a failed
+// delete is only logged because the event was already processed.
+func (s *subscriber) Acknowledge(ctx context.Context, messageID string) error {
+	err := s.EventBusSubscriber.Acknowledge(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	key, ok := s.forget(messageID)
+	if !ok {
+		return nil
+	}
+
+	err = s.store.Delete(ctx, key)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", messageID,
+			"method", "claimcheck.subscriber.Acknowledge",
+		)
+	}
+
+	return nil
+}
+
+// Unwrap returns the wrapped event bus.
+func (s *subscriber) Unwrap() subscribers.EventBusSubscriber {
+	return s.EventBusSubscriber
+}
+
+// Nack rejects the message keeping its data, the event bus delivers it again, usually under a
+// new message id.
+func (n *nackSubscriber) Nack(ctx context.Context, messageID string, delay time.Duration) error {
+	err := n.nacker.Nack(ctx, messageID, delay)
+	if err != nil {
+		return err
+	}
+
+	n.forget(messageID)
+
+	return nil
+}
+
+// remember keeps the blob key of the message and forgets the expired ones, looking for them
+// at most twice per expiry.
+func (s *subscriber) remember(messageID, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	if now.Sub(s.sweptAt) > s.expiry/2 {
+		s.sweptAt = now
+
+		for pendingID, pending := range s.pending {
+			if now.Sub(pending.receivedAt) > s.expiry {
+				delete(s.pending, pendingID)
+			}
+		}
+	}
+
+	s.pending[messageID] = pendingCheck{key: key, receivedAt: now}
+}
+
+// forget returns the blob key of the message, if any, and forgets it.
+func (s *subscriber) forget(messageID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.pending[messageID]
+	delete(s.pending, messageID)
+
+	return pending.key, ok
+}
+
+// findNacker walks the middleware chain looking for an event bus with native rejections.
+func findNacker(eventBus subscribers.EventBusSubscriber) subscribers.NackEventBusSubscriber {
+	for eventBus != nil {
+		nacker, ok := eventBus.(subscribers.NackEventBusSubscriber)
+		if ok {
+			return nacker
+		}
+
+		unwrapper, ok := eventBus.(subscribers.Unwrapper)
+		if !ok {
+			return nil
+		}
+
+		eventBus = unwrapper.Unwrap()
+	}
+
+	return nil
+}
+
+// newKey returns a unique blob key for the event, retries of the same event get different
+// keys so a late delete never removes the data of a newer copy.
+func newKey(messageChannel, eventID string) (string, error) {
+	suffix := make([]byte, keySuffixSize)
+
+	_, err := io.ReadFull(rand.Reader, suffix)
+	if err != nil {
+		return "", errors.Wrap(err, "could not generate claim check key")
+	}
+
+	return path.Join(sanitize(messageChannel), sanitize(eventID)+"-"+hex.EncodeToString(suffix)), nil
+}
+
+// sanitize keeps key segments to a safe character set.
+func sanitize(value string) string {
+	result := []byte(value)
+
+	for idx, b := range result {
+		isAlphanumeric := (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+		if !isAlphanumeric && b != '-' && b != '_' {
+			result[idx] = '_'
+		}
+	}
+
+	if len(result) == 0 {
+		return "_"
+	}
+
+	return string(result)
+}
@@ -0,0 +1,273 @@
+package claimcheck_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/claimcheck"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLargeEventRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	dir := t.TempDir()
+	expectedEvent := eventMessageFixture(bytes.Repeat([]byte("a"), 2048))
+	settings := claimcheck.Settings{Store: claimcheck.NewFileStore(dir), Threshold: 1024}
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, claimcheck.Publisher(settings)))
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, claimcheck.Subscriber(settings)),
+		MessagesPerPull: 1,
+	})
+	// When
+	err := publisher.Publish(ctx, publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	published := eventBus.events[0]
+
+	got, err := subscriber.Pull(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	stored := countFiles(t, dir)
+	err = subscriber.Acknowledge(ctx, got[0].Header.MessageID)
+	// Then
+	assert.NoError(t, err)
+	assert.Nil(t, published.Data)
+	assert.Contains(t, published.Header.Attribute(claimcheck.ReferenceAttribute), "orders-topic/123-456-789-")
+	assert.Equal(t, []messages.Event{expectedEvent}, got)
+	assert.Equal(t, 1, stored)
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+func TestSmallEventIsNotCheckedIn(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := eventMessageFixture([]byte(`{"value_one": "one"}`))
+	dir := t.TempDir()
+	eventBus := new(eventBusMock)
+	settings := claimcheck.Settings{Store: claimcheck.NewFileStore(dir)}
+	publisher := publishers.New(publishers.Chain(eventBus, claimcheck.Publisher(settings)))
+	// When
+	err := publisher.Publish(context.TODO(), publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, eventBus.events[0])
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+func TestFailedPublishDeletesData(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	dir := t.TempDir()
+	unavailable := errors.New("broker unavailable")
+	eventBus := &eventBusMock{err: unavailable}
+	settings := claimcheck.Settings{Store: claimcheck.NewFileStore(dir), Threshold: 1024}
+	publisher := publishers.New(publishers.Chain(eventBus, claimcheck.Publisher(settings)))
+	event := eventMessageFixture(bytes.Repeat([]byte("a"), 2048))
+	// When
+	err := publisher.Publish(context.TODO(), publishers.EventMessage{ChannelName: "orders-topic", Event: event})
+	// Then
+	assert.True(t, errors.Is(err, unavailable))
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+func TestRetainOnAcknowledge(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	dir := t.TempDir()
+	settings := claimcheck.Settings{
+		Store:               claimcheck.NewFileStore(dir),
+		Threshold:           1,
+		RetainOnAcknowledge: true,
+	}
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, claimcheck.Publisher(settings)))
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, claimcheck.Subscriber(settings)),
+		MessagesPerPull: 1,
+	})
+	// When
+	err := publisher.Publish(ctx, publishers.EventMessage{
+		ChannelName: "orders-topic",
+		Event:       eventMessageFixture([]byte(`{"value_one": "one"}`)),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := subscriber.Pull(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = subscriber.Acknowledge(ctx, got[0].Header.MessageID)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countFiles(t, dir))
+}
+
+func TestNackKeepsData(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	dir := t.TempDir()
+	settings := claimcheck.Settings{Store: claimcheck.NewFileStore(dir), Threshold: 1}
+	eventBus := new(nackEventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, claimcheck.Publisher(settings)))
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, claimcheck.Subscriber(settings)),
+		MessagesPerPull: 1,
+	})
+	_ = publisher.Publish(ctx, publishers.EventMessage{
+		ChannelName: "orders-topic",
+		Event:       eventMessageFixture([]byte(`{"value_one": "one"}`)),
+	})
+	got, _ := subscriber.Pull(ctx)
+	// When
+	err := subscriber.Nack(ctx, got[0].Header.MessageID, 0)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, eventBus.nacked)
+	// the message id is forgotten, acknowledging it late does not delete the redelivered data.
+	assert.NoError(t, subscriber.Acknowledge(ctx, got[0].Header.MessageID))
+	assert.Equal(t, 1, countFiles(t, dir))
+}
+
+func TestPendingEventsExpire(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	dir := t.TempDir()
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	settings := claimcheck.Settings{
+		Store:         claimcheck.NewFileStore(dir),
+		Threshold:     1,
+		PendingExpiry: time.Hour,
+		Now:           func() time.Time { return now },
+	}
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, claimcheck.Publisher(settings)))
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: subscribers.Chain(eventBus, claimcheck.Subscriber(settings)),
+	})
+	expired := eventMessageFixture([]byte(`{"value_one": "one"}`))
+	recent := eventMessageFixture([]byte(`{"value_one": "two"}`))
+	recent.Header.MessageID = "2"
+	_ = publisher.Publish(ctx, publishers.EventMessage{ChannelName: "orders-topic", Event: expired})
+	_, _ = subscriber.Pull(ctx)
+	now = now.Add(2 * time.Hour)
+	_ = publisher.Publish(ctx, publishers.EventMessage{ChannelName: "orders-topic", Event: recent})
+	_, _ = subscriber.Pull(ctx)
+	// When
+	expiredErr := subscriber.Acknowledge(ctx, "1")
+	recentErr := subscriber.Acknowledge(ctx, "2")
+	// Then
+	assert.NoError(t, expiredErr)
+	assert.NoError(t, recentErr)
+	assert.Equal(t, 1, countFiles(t, dir))
+}
+
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+
+	var count int
+
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			count++
+		}
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return count
+}
+
+func eventMessageFixture(data []byte) messages.Event {
+	header := messages.Header{
+		ID:          "123-456-789",
+		Domain:      "loans",
+		EventType:   "orders",
+		Version:     "0.1.0",
+		Application: "core-app",
+		MessageID:   "1",
+	}
+
+	return messages.Event{
+		Header: header,
+		Data:   data,
+	}
+}
+
+// eventBusMock delivers published events to its subscribers once, publishing fails with err
+// when it is set.
+type eventBusMock struct {
+	events []messages.Event
+	pulled int
+	err    error
+}
+
+func (e *eventBusMock) Publish(_ context.Context, _ string, message interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	e.events = append(e.events, message.(messages.Event))
+
+	return nil
+}
+
+func (e *eventBusMock) Pull(_ context.Context, _ uint8) ([]messages.Event, error) {
+	result := e.events[e.pulled:]
+	e.pulled = len(e.events)
+
+	return result, nil
+}
+
+func (e *eventBusMock) Stream(_ context.Context) (<-chan messages.Event, error) {
+	return nil, nil
+}
+
+func (e *eventBusMock) Subscribe(_ context.Context, _ string) error {
+	return nil
+}
+
+func (e *eventBusMock) Acknowledge(_ context.Context, _ string) error {
+	return nil
+}
+
+// nackEventBusMock rejects messages natively.
+type nackEventBusMock struct {
+	eventBusMock
+	nacked []string
+}
+
+func (n *nackEventBusMock) Nack(_ context.Context, id string, _ time.Duration) error {
+	n.nacked = append(n.nacked, id)
+
+	return nil
+}
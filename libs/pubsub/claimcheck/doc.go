@@ -0,0 +1,6 @@
+// Package claimcheck provides the claim check pattern for events too large for the event bus.
+//
+// Publishers store the data of large events in a blob store and publish only a reference to
+// it, subscribers fetch the data back before handing the event over and delete it once the
+// event is acknowledged.
+package claimcheck
@@ -0,0 +1,170 @@
+package claimcheck
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+)
+
+var errDelaysNotSupported = errors.New("event bus does not support delays")
+
+// Publisher returns a publisher middleware that moves the data of events larger than the
+// threshold to the store and publishes a reference to it. The data of events the event bus
+// fails to publish is deleted again. Native batches and delays of the event bus are kept.
+func Publisher(settings Settings) publishers.Middleware {
+	threshold := settings.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	return func(next publishers.EventBusPublisher) publishers.EventBusPublisher {
+		return &publisher{next: next, store: settings.Store, threshold: threshold}
+	}
+}
+
+type publisher struct {
+	next      publishers.EventBusPublisher
+	store     BlobStore
+	threshold int
+}
+
+func (p *publisher) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	event, key, err := p.checkIn(ctx, messageChannel, message)
+	if err != nil {
+		return err
+	}
+
+	err = p.next.Publish(ctx, messageChannel, event)
+	if err != nil {
+		p.discard(ctx, key)
+	}
+
+	return err
+}
+
+// PublishBatch checks in every message and publishes the batch with the native batch api when
+// the event bus has one, messages that fail to check in are not published.
+func (p *publisher) PublishBatch(ctx context.Context, messageChannel string, batch []interface{}) []error {
+	errs := make([]error, len(batch))
+
+	batchPublisher, ok := p.next.(publishers.BatchEventBusPublisher)
+	if !ok {
+		for idx, message := range batch {
+			errs[idx] = p.Publish(ctx, messageChannel, message)
+		}
+
+		return errs
+	}
+
+	checkedIn := make([]interface{}, 0, len(batch))
+	keys := make([]string, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+
+	for idx, message := range batch {
+		event, key, err := p.checkIn(ctx, messageChannel, message)
+		if err != nil {
+			errs[idx] = err
+
+			continue
+		}
+
+		checkedIn = append(checkedIn, event)
+		keys = append(keys, key)
+		indexes = append(indexes, idx)
+	}
+
+	for idx, err := range batchPublisher.PublishBatch(ctx, messageChannel, checkedIn) {
+		if err != nil {
+			p.discard(ctx, keys[idx])
+		}
+
+		errs[indexes[idx]] = err
+	}
+
+	return errs
+}
+
+// MaxBatchSize returns the maximum batch size of the event bus.
+func (p *publisher) MaxBatchSize() int {
+	if batchPublisher, ok := p.next.(publishers.BatchEventBusPublisher); ok {
+		return batchPublisher.MaxBatchSize()
+	}
+
+	return 0
+}
+
+// PublishDelayed checks in the message and publishes it with the native delays of the event bus.
+func (p *publisher) PublishDelayed(
+	ctx context.Context, messageChannel string, message interface{}, delay time.Duration,
+) error {
+	delayPublisher, ok := p.next.(publishers.DelayEventBusPublisher)
+	if !ok {
+		return errDelaysNotSupported
+	}
+
+	event, key, err := p.checkIn(ctx, messageChannel, message)
+	if err != nil {
+		return err
+	}
+
+	err = delayPublisher.PublishDelayed(ctx, messageChannel, event, delay)
+	if err != nil {
+		p.discard(ctx, key)
+	}
+
+	return err
+}
+
+// MaxDelay returns the maximum delay of the event bus.
+func (p *publisher) MaxDelay() time.Duration {
+	if delayPublisher, ok := p.next.(publishers.DelayEventBusPublisher); ok {
+		return delayPublisher.MaxDelay()
+	}
+
+	return 0
+}
+
+// checkIn moves the data of a large event to the store, it returns the event to publish and
+// the blob key, empty when the event is small enough.
+func (p *publisher) checkIn(ctx context.Context, messageChannel string, message interface{}) (messages.Event, string, error) {
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return messages.Event{}, "", err
+	}
+
+	if len(event.Data) <= p.threshold {
+		return event, "", nil
+	}
+
+	key, err := newKey(messageChannel, event.Header.ID)
+	if err != nil {
+		return messages.Event{}, "", err
+	}
+
+	err = p.store.Put(ctx, key, event.Data)
+	if err != nil {
+		return messages.Event{}, "", errors.WithMessagef(err, "could not check in event %q", event.Header.ID)
+	}
+
+	event.Header = event.Header.WithAttribute(ReferenceAttribute, key)
+	event.Data = nil
+
+	return event, key, nil
+}
+
+// discard deletes the data of an event that was not published, a failed delete is only logged
+// and left for the store to expire.
+func (p *publisher) discard(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+
+	err := p.store.Delete(ctx, key)
+	if err != nil {
+		log.Println("error", err, "key", key, "method", "claimcheck.publisher.discard")
+	}
+}
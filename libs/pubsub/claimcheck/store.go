@@ -0,0 +1,146 @@
+package claimcheck
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dirPermissions  = 0o750
+	filePermissions = 0o600
+)
+
+var errInvalidKey = errors.New("invalid blob key")
+
+// BlobStore stores event data by key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// S3Client is the subset of an S3 compatible object storage client used by S3Store, a thin
+// wrapper over the aws sdk or any S3 compatible client satisfies it.
+type S3Client interface {
+	PutObject(ctx context.Context, bucket, key string, body []byte) error
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// FileStore is a blob store backed by a directory of the local filesystem.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore instances a new filesystem blob store rooted at the given directory.
+func NewFileStore(dir string) *FileStore {
+	newStore := FileStore{
+		dir: dir,
+	}
+
+	return &newStore
+}
+
+// Put writes the data into the file named by the key.
+func (f *FileStore) Put(_ context.Context, key string, data []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), dirPermissions)
+	if err != nil {
+		return errors.Wrapf(err, "could not create directory for %q", key)
+	}
+
+	err = os.WriteFile(path, data, filePermissions)
+
+	return errors.Wrapf(err, "could not write blob %q", key)
+}
+
+// Get reads the data of the file named by the key.
+func (f *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read blob %q", key)
+	}
+
+	return data, nil
+}
+
+// Delete removes the file named by the key, missing files are not an error.
+func (f *FileStore) Delete(_ context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "could not delete blob %q", key)
+	}
+
+	return nil
+}
+
+// path returns the file path for the key, keys cannot point outside the store directory.
+func (f *FileStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." ||
+		strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", errors.WithMessagef(errInvalidKey, "%q", key)
+	}
+
+	return filepath.Join(f.dir, cleaned), nil
+}
+
+// S3Store is a blob store backed by an S3 compatible bucket.
+type S3Store struct {
+	client S3Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store instances a new S3 blob store, keys are stored under the given prefix.
+func NewS3Store(client S3Client, bucket, prefix string) *S3Store {
+	newStore := S3Store{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+
+	return &newStore
+}
+
+// Put uploads the data as an object.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	err := s.client.PutObject(ctx, s.bucket, s.prefix+key, data)
+
+	return errors.Wrapf(err, "could not put object %q", s.prefix+key)
+}
+
+// Get downloads the data of an object.
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.GetObject(ctx, s.bucket, s.prefix+key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get object %q", s.prefix+key)
+	}
+
+	return data, nil
+}
+
+// Delete removes an object.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	err := s.client.DeleteObject(ctx, s.bucket, s.prefix+key)
+
+	return errors.Wrapf(err, "could not delete object %q", s.prefix+key)
+}
@@ -0,0 +1,86 @@
+package claimcheck_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/claimcheck"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStoreRejectsKeysOutsideItsDirectory(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	store := claimcheck.NewFileStore(t.TempDir())
+	// When
+	err := store.Put(context.TODO(), "../outside", []byte("data"))
+	// Then
+	assert.EqualError(t, err, `"../outside": invalid blob key`)
+}
+
+func TestFileStoreDeleteMissingBlob(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	store := claimcheck.NewFileStore(t.TempDir())
+	// When
+	err := store.Delete(context.TODO(), "orders-topic/missing")
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestS3Store(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	expectedData := []byte("data")
+	client := &s3ClientMock{objects: make(map[string][]byte)}
+	store := claimcheck.NewS3Store(client, "claim-checks", "pubsub/")
+	// When
+	err := store.Put(ctx, "orders-topic/1", expectedData)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := store.Get(ctx, "orders-topic/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	deleteErr := store.Delete(ctx, "orders-topic/1")
+	_, getErr := store.Get(ctx, "orders-topic/1")
+	// Then
+	assert.Equal(t, expectedData, got)
+	assert.NoError(t, deleteErr)
+	assert.EqualError(t, getErr, `could not get object "pubsub/orders-topic/1": no such key`)
+}
+
+var errNoSuchKey = errors.New("no such key")
+
+type s3ClientMock struct {
+	objects map[string][]byte
+}
+
+func (s *s3ClientMock) PutObject(_ context.Context, bucket, key string, body []byte) error {
+	s.objects[bucket+"/"+key] = body
+
+	return nil
+}
+
+func (s *s3ClientMock) GetObject(_ context.Context, bucket, key string) ([]byte, error) {
+	body, ok := s.objects[bucket+"/"+key]
+	if !ok {
+		return nil, errNoSuchKey
+	}
+
+	return body, nil
+}
+
+func (s *s3ClientMock) DeleteObject(_ context.Context, bucket, key string) error {
+	delete(s.objects, bucket+"/"+key)
+
+	return nil
+}
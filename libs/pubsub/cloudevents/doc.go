@@ -0,0 +1,3 @@
+// Package cloudevents provides conversion between event messages and the CloudEvents 1.0
+// specification, including the JSON event format and the HTTP and Kafka protocol bindings.
+package cloudevents
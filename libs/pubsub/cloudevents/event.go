@@ -0,0 +1,287 @@
+package cloudevents
+
+import (
+	"strings"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+// SpecVersion is the CloudEvents specification version supported by this package.
+const SpecVersion = "1.0"
+
+// ContentMode defines how an event is carried by a protocol binding.
+type ContentMode int
+
+const (
+	// Binary mode carries the attributes in protocol headers and the data in the body.
+	Binary ContentMode = iota + 1
+	// Structured mode carries the whole event, attributes and data, in the body.
+	Structured
+)
+
+const (
+	// ApplicationExtension extension attribute used to carry the sender application name.
+	ApplicationExtension = "application"
+	// PartitionKeyExtension extension attribute defined by the partitioning extension.
+	PartitionKeyExtension = "partitionkey"
+
+	attrID              = "id"
+	attrSource          = "source"
+	attrSpecVersion     = "specversion"
+	attrType            = "type"
+	attrDataContentType = "datacontenttype"
+	attrDataSchema      = "dataschema"
+	attrSubject         = "subject"
+	attrTime            = "time"
+	attrData            = "data"
+	attrDataBase64      = "data_base64"
+
+	contentTypeJSON           = "application/json"
+	contentTypeCloudEventJSON = "application/cloudevents+json"
+)
+
+var (
+	errMissingID          = errors.New("cloud event must have an id")
+	errMissingSource      = errors.New("cloud event must have a source")
+	errMissingType        = errors.New("cloud event must have a type")
+	errInvalidSpecVersion = errors.New("unsupported cloud event specversion")
+	errInvalidExtension   = errors.New("extension names must be lowercase alphanumeric")
+	errReservedExtension  = errors.New("extension name is reserved")
+	errUnknownContentMode = errors.New("unknown content mode")
+)
+
+// Event is a CloudEvents 1.0 event.
+type Event struct {
+	ID              string
+	Source          string
+	SpecVersion     string
+	Type            string
+	DataContentType string
+	DataSchema      string
+	Subject         string
+	Time            string // Time RFC3339 timestamp of the occurrence.
+	// Extensions extension context attributes by name.
+	Extensions map[string]string
+	Data       []byte
+}
+
+// FromMessage converts an event message into a cloud event.
+//
+// Header.ID maps to id, Header.Domain to source, Header.EventType to type,
+// Header.Version to the urn:<domain>:<eventtype>:<version> dataschema, Header.Application to
+// the application extension and Header.OrderingKey to the partitionkey extension.
+// Header attributes named like an optional core attribute (datacontenttype, subject,
+// time) set that attribute, any other header attribute becomes an extension.
+// Header.MessageID is broker specific and it is not carried.
+func FromMessage(event messages.Event) (Event, error) {
+	result := Event{
+		ID:          event.Header.ID,
+		Source:      event.Header.Domain,
+		SpecVersion: SpecVersion,
+		Type:        event.Header.EventType,
+		DataSchema:  schemaOf(event.Header),
+		Data:        event.Data,
+	}
+
+	for key, value := range event.Header.Attributes {
+		switch key {
+		case attrDataContentType:
+			result.DataContentType = value
+		case attrSubject:
+			result.Subject = value
+		case attrTime:
+			result.Time = value
+		default:
+			result = result.WithExtension(key, value)
+		}
+	}
+
+	if event.Header.Application != "" {
+		result = result.WithExtension(ApplicationExtension, event.Header.Application)
+	}
+
+	if event.Header.OrderingKey != "" {
+		result = result.WithExtension(PartitionKeyExtension, event.Header.OrderingKey)
+	}
+
+	err := result.Validate()
+	if err != nil {
+		return Event{}, errors.WithMessagef(err, "could not convert event %q", event.Header.ID)
+	}
+
+	return result, nil
+}
+
+// ToMessage converts a cloud event into an event message, it is the inverse of FromMessage.
+func ToMessage(event Event) (messages.Event, error) {
+	err := event.Validate()
+	if err != nil {
+		return messages.Event{}, errors.WithMessagef(err, "could not convert cloud event %q", event.ID)
+	}
+
+	header := messages.Header{
+		ID:          event.ID,
+		Domain:      event.Source,
+		EventType:   event.Type,
+		Version:     versionOf(event),
+		Application: event.Extensions[ApplicationExtension],
+		OrderingKey: event.Extensions[PartitionKeyExtension],
+	}
+	optional := map[string]string{
+		attrDataContentType: event.DataContentType,
+		attrSubject:         event.Subject,
+		attrTime:            event.Time,
+	}
+
+	for key, value := range optional {
+		if value != "" {
+			header = header.WithAttribute(key, value)
+		}
+	}
+
+	for key, value := range event.Extensions {
+		if key != ApplicationExtension && key != PartitionKeyExtension {
+			header = header.WithAttribute(key, value)
+		}
+	}
+
+	return messages.Event{
+		Header: header,
+		Data:   event.Data,
+	}, nil
+}
+
+// Validate checks the event has the required attributes and valid extension names.
+func (e Event) Validate() error {
+	switch {
+	case e.SpecVersion != SpecVersion:
+		return errors.WithMessagef(errInvalidSpecVersion, "%q", e.SpecVersion)
+	case e.ID == "":
+		return errMissingID
+	case e.Source == "":
+		return errMissingSource
+	case e.Type == "":
+		return errMissingType
+	}
+
+	for name := range e.Extensions {
+		if !isValidAttributeName(name) {
+			return errors.WithMessagef(errInvalidExtension, "%q", name)
+		}
+
+		if isCoreAttribute(name) {
+			return errors.WithMessagef(errReservedExtension, "%q", name)
+		}
+	}
+
+	return nil
+}
+
+// WithExtension returns a copy of the event with the given extension attribute set.
+func (e Event) WithExtension(name, value string) Event {
+	extensions := make(map[string]string, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		extensions[k] = v
+	}
+
+	extensions[name] = value
+	e.Extensions = extensions
+
+	return e
+}
+
+func isValidAttributeName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isCoreAttribute(name string) bool {
+	switch name {
+	case attrID, attrSource, attrSpecVersion, attrType, attrDataContentType,
+		attrDataSchema, attrSubject, attrTime, attrData:
+		return true
+	}
+
+	return false
+}
+
+// attributes returns every context attribute of the event by name, optional empty ones
+// are left out.
+func (e Event) attributes() map[string]string {
+	result := make(map[string]string, len(e.Extensions)+8) //nolint:gomnd // core attributes.
+	for k, v := range e.Extensions {
+		result[k] = v
+	}
+
+	core := map[string]string{
+		attrID:              e.ID,
+		attrSource:          e.Source,
+		attrSpecVersion:     e.SpecVersion,
+		attrType:            e.Type,
+		attrDataContentType: e.DataContentType,
+		attrDataSchema:      e.DataSchema,
+		attrSubject:         e.Subject,
+		attrTime:            e.Time,
+	}
+
+	for k, v := range core {
+		if v != "" {
+			result[k] = v
+		}
+	}
+
+	return result
+}
+
+// setAttribute sets a context attribute by name, unknown names become extensions.
+func (e *Event) setAttribute(name, value string) {
+	switch name {
+	case attrID:
+		e.ID = value
+	case attrSource:
+		e.Source = value
+	case attrSpecVersion:
+		e.SpecVersion = value
+	case attrType:
+		e.Type = value
+	case attrDataContentType:
+		e.DataContentType = value
+	case attrDataSchema:
+		e.DataSchema = value
+	case attrSubject:
+		e.Subject = value
+	case attrTime:
+		e.Time = value
+	default:
+		*e = e.WithExtension(name, value)
+	}
+}
+
+// schemaOf returns the dataschema uri of the event version, empty without version.
+func schemaOf(header messages.Header) string {
+	if header.Version == "" {
+		return ""
+	}
+
+	return schemaPrefix(header.Domain, header.EventType) + header.Version
+}
+
+// versionOf returns the version of a urn:<domain>:<eventtype>:<version> dataschema, any other
+// dataschema is taken as the version as it is.
+func versionOf(event Event) string {
+	return strings.TrimPrefix(event.DataSchema, schemaPrefix(event.Source, event.Type))
+}
+
+func schemaPrefix(domain, eventType string) string {
+	return "urn:" + domain + ":" + eventType + ":"
+}
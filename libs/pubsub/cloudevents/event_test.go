@@ -0,0 +1,246 @@
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/cloudevents"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromMessage(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudevents.Event{
+		ID:          "123-456-789",
+		Source:      "loans",
+		SpecVersion: "1.0",
+		Type:        "orders",
+		DataSchema:  "urn:loans:orders:0.1.0",
+		Subject:     "account-1",
+		Extensions: map[string]string{
+			"application": "core-app",
+			"tenant":      "acme",
+		},
+		Data: []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+	event := eventMessageFixture()
+	event.Header = event.Header.WithAttribute("subject", "account-1").WithAttribute("tenant", "acme")
+	// When
+	got, err := cloudevents.FromMessage(event)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+}
+
+func TestFromMessageWithoutID(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedError := `could not convert event "": cloud event must have an id`
+	event := eventMessageFixture()
+	event.Header.ID = ""
+	// When
+	_, err := cloudevents.FromMessage(event)
+	// Then
+	assert.EqualError(t, err, expectedError)
+}
+
+func TestToMessageRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := eventMessageFixture()
+	expectedEvent.Header = expectedEvent.Header.WithAttribute("time", "2022-08-01T10:00:00Z")
+	cloudEvent, err := cloudevents.FromMessage(expectedEvent)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// When
+	got, err := cloudevents.ToMessage(cloudEvent)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+}
+
+func TestStructuredJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudEventFixture()
+	// When
+	encoded, err := json.Marshal(expectedEvent)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got cloudevents.Event
+
+	err = json.Unmarshal(encoded, &got)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+	assert.Contains(t, string(encoded), `"data":{"value_one":"one","value_two":"two"}`)
+}
+
+func TestStructuredJSONBinaryData(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudEventFixture()
+	expectedEvent.DataContentType = "application/octet-stream"
+	expectedEvent.Data = []byte{0x00, 0x01, 0xff}
+	// When
+	encoded, err := json.Marshal(expectedEvent)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got cloudevents.Event
+
+	err = json.Unmarshal(encoded, &got)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+	assert.Contains(t, string(encoded), `"data_base64":"AAH/"`)
+}
+
+func TestStructuredJSONKeepsDataBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		contentType      string
+		data             string
+		expectedEncoding string
+	}{
+		"indented json": {
+			contentType:      "application/json",
+			data:             "{\n  \"amount\": 10\n}",
+			expectedEncoding: `"data_base64":`,
+		},
+		"json with html characters": {
+			contentType:      "application/json",
+			data:             `{"note":"a<b"}`,
+			expectedEncoding: `"data_base64":`,
+		},
+		"compact json": {
+			contentType:      "application/json",
+			data:             `{"amount":10}`,
+			expectedEncoding: `"data":{"amount":10}`,
+		},
+		"json without content type": {
+			data:             `{"amount":10}`,
+			expectedEncoding: `"data_base64":`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			expectedEvent := cloudEventFixture()
+			expectedEvent.DataContentType = test.contentType
+			expectedEvent.Data = []byte(test.data)
+			// When
+			encoded, err := json.Marshal(expectedEvent)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var got cloudevents.Event
+
+			err = json.Unmarshal(encoded, &got)
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, expectedEvent, got)
+			assert.Contains(t, string(encoded), test.expectedEncoding)
+		})
+	}
+}
+
+func TestUnmarshalJSONKeepsEmbeddedDataAsItIs(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	document := `{"specversion":"1.0","id":"1","source":"loans","type":"orders",
+		"datacontenttype":"application/json","data":{ "amount": 10 }}`
+	// When
+	var got cloudevents.Event
+
+	err := json.Unmarshal([]byte(document), &got)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, `{ "amount": 10 }`, string(got.Data))
+}
+
+func TestUnmarshalJSONExtensionTypes(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	document := `{"specversion":"1.0","id":"1","source":"loans","type":"orders",
+		"retries":3,"replayed":true,"datacontenttype":"text/plain","data":"hello"}`
+	expectedEvent := cloudevents.Event{
+		ID:              "1",
+		Source:          "loans",
+		SpecVersion:     "1.0",
+		Type:            "orders",
+		DataContentType: "text/plain",
+		Extensions:      map[string]string{"retries": "3", "replayed": "true"},
+		Data:            []byte("hello"),
+	}
+	// When
+	var got cloudevents.Event
+
+	err := json.Unmarshal([]byte(document), &got)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+}
+
+func TestUnmarshalJSONInvalidSpecVersion(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	document := `{"specversion":"0.3","id":"1","source":"loans","type":"orders"}`
+	// When
+	var got cloudevents.Event
+
+	err := json.Unmarshal([]byte(document), &got)
+	// Then
+	assert.EqualError(t, err, `"0.3": unsupported cloud event specversion`)
+}
+
+func cloudEventFixture() cloudevents.Event {
+	return cloudevents.Event{
+		ID:              "123-456-789",
+		Source:          "loans",
+		SpecVersion:     "1.0",
+		Type:            "orders",
+		DataContentType: "application/json",
+		DataSchema:      "0.1.0",
+		Extensions: map[string]string{
+			"application": "core-app",
+		},
+		Data: []byte(`{"value_one":"one","value_two":"two"}`),
+	}
+}
+
+func eventMessageFixture() messages.Event {
+	header := messages.Header{
+		ID:          "123-456-789",
+		Domain:      "loans",
+		EventType:   "orders",
+		Version:     "0.1.0",
+		Application: "core-app",
+	}
+
+	return messages.Event{
+		Header: header,
+		Data:   []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
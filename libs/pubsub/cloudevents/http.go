@@ -0,0 +1,165 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	httpHeaderPrefix  = "ce-"
+	httpContentType   = "Content-Type"
+	maxHTTPBodyLength = 10 << 20 // 10MB
+)
+
+var errBodyTooLarge = errors.New("cloud event body is too large")
+
+// WriteHTTP sets the event attributes in the given headers according to the content mode
+// and returns the body that must be sent along with them.
+func WriteHTTP(header http.Header, event Event, mode ContentMode) ([]byte, error) {
+	switch mode {
+	case Structured:
+		body, err := event.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		header.Set(httpContentType, contentTypeCloudEventJSON)
+
+		return body, nil
+	case Binary:
+		err := event.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		for name, value := range event.attributes() {
+			if name == attrDataContentType {
+				header.Set(httpContentType, value)
+
+				continue
+			}
+
+			header.Set(httpHeaderPrefix+name, encodeHTTPHeaderValue(value))
+		}
+
+		return event.Data, nil
+	}
+
+	return nil, errors.WithMessagef(errUnknownContentMode, "%d", mode)
+}
+
+// ReadHTTP reads an event from the given headers and body, the content mode is detected
+// from the content type.
+func ReadHTTP(header http.Header, body []byte) (Event, error) {
+	if isStructuredContentType(header.Get(httpContentType)) {
+		var event Event
+
+		err := event.UnmarshalJSON(body)
+
+		return event, err
+	}
+
+	var event Event
+
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, httpHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return Event{}, errors.Wrapf(err, "invalid header %q", key)
+		}
+
+		event.setAttribute(strings.TrimPrefix(name, httpHeaderPrefix), value)
+	}
+
+	event.DataContentType = header.Get(httpContentType)
+	if len(body) > 0 {
+		event.Data = body
+	}
+
+	err := event.Validate()
+	if err != nil {
+		return Event{}, err
+	}
+
+	return event, nil
+}
+
+// NewHTTPRequest creates a POST request to the given url carrying the event.
+func NewHTTPRequest(ctx context.Context, target string, event Event, mode ContentMode) (*http.Request, error) {
+	header := make(http.Header)
+
+	body, err := WriteHTTP(header, event, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cloud event request")
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	return request, nil
+}
+
+// FromHTTPRequest reads the event carried by the given request in any content mode.
+func FromHTTPRequest(request *http.Request) (Event, error) {
+	body, err := io.ReadAll(io.LimitReader(request.Body, maxHTTPBodyLength+1))
+	if err != nil {
+		return Event{}, errors.Wrap(err, "could not read cloud event request")
+	}
+
+	if len(body) > maxHTTPBodyLength {
+		return Event{}, errBodyTooLarge
+	}
+
+	return ReadHTTP(request.Header, body)
+}
+
+func isStructuredContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == contentTypeCloudEventJSON
+}
+
+// encodeHTTPHeaderValue percent encodes the characters the http binding does not allow
+// in header values: non printable ascii, space, double quote and percent.
+func encodeHTTPHeaderValue(value string) string {
+	var builder strings.Builder
+
+	for _, b := range []byte(value) {
+		if b <= ' ' || b >= 0x7f || b == '"' || b == '%' {
+			builder.WriteString("%")
+			builder.WriteString(strings.ToUpper(hexByte(b)))
+
+			continue
+		}
+
+		builder.WriteByte(b)
+	}
+
+	return builder.String()
+}
+
+func hexByte(b byte) string {
+	const digits = "0123456789abcdef"
+
+	return string([]byte{digits[b>>4], digits[b&0x0f]})
+}
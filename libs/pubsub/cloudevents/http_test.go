@@ -0,0 +1,109 @@
+package cloudevents_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/cloudevents"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPBinaryMode(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudEventFixture()
+	expectedEvent.Subject = "a subject with spaces and \"quotes\""
+	received := make(chan cloudevents.Event, 1)
+	server := httptest.NewServer(receiverHandler(t, received))
+
+	defer server.Close()
+
+	request, err := cloudevents.NewHTTPRequest(context.TODO(), server.URL, expectedEvent, cloudevents.Binary)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// When
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	defer response.Body.Close()
+	// Then
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, "123-456-789", request.Header.Get("ce-id"))
+	assert.Equal(t, "a%20subject%20with%20spaces%20and%20%22quotes%22", request.Header.Get("ce-subject"))
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, expectedEvent, <-received)
+}
+
+func TestHTTPStructuredMode(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudEventFixture()
+	received := make(chan cloudevents.Event, 1)
+	server := httptest.NewServer(receiverHandler(t, received))
+
+	defer server.Close()
+
+	request, err := cloudevents.NewHTTPRequest(context.TODO(), server.URL, expectedEvent, cloudevents.Structured)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// When
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	defer response.Body.Close()
+	// Then
+	assert.Equal(t, "application/cloudevents+json", request.Header.Get("Content-Type"))
+	assert.Empty(t, request.Header.Get("ce-id"))
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, expectedEvent, <-received)
+}
+
+func TestReadHTTPMissingAttributes(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	header := http.Header{}
+	header.Set("ce-specversion", "1.0")
+	header.Set("ce-id", "1")
+	header.Set("ce-type", "orders")
+	// When
+	_, err := cloudevents.ReadHTTP(header, nil)
+	// Then
+	assert.EqualError(t, err, "cloud event must have a source")
+}
+
+func TestWriteHTTPUnknownMode(t *testing.T) {
+	t.Parallel()
+
+	// When
+	_, err := cloudevents.WriteHTTP(http.Header{}, cloudEventFixture(), cloudevents.ContentMode(0))
+	// Then
+	assert.EqualError(t, err, "0: unknown content mode")
+}
+
+func receiverHandler(t *testing.T, received chan<- cloudevents.Event) http.HandlerFunc {
+	t.Helper()
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		event, err := cloudevents.FromHTTPRequest(req)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received <- event
+
+		res.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,159 @@
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errDataAndBase64 = errors.New("cloud event cannot have both data and data_base64")
+	errNotAString    = errors.New("attribute must be a json string")
+)
+
+// MarshalJSON encodes the event using the CloudEvents JSON event format.
+//
+// Data is embedded as JSON when the content type is JSON and the data is JSON that encodes
+// to the very same bytes, otherwise it is encoded as data_base64, so decoding the event always
+// gives back the original data and signatures computed over it stay valid.
+func (e Event) MarshalJSON() ([]byte, error) {
+	err := e.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	document := make(map[string]interface{}, len(e.Extensions)+9) //nolint:gomnd // attributes.
+	for name, value := range e.attributes() {
+		document[name] = value
+	}
+
+	if e.Data != nil {
+		if isJSONContentType(e.DataContentType) && embeddable(e.Data) {
+			document[attrData] = json.RawMessage(e.Data)
+		} else {
+			document[attrDataBase64] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+
+	result, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode cloud event")
+	}
+
+	return result, nil
+}
+
+// UnmarshalJSON decodes an event in the CloudEvents JSON event format.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var document map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return errors.Wrap(err, "could not decode cloud event")
+	}
+
+	var result Event
+
+	for name, raw := range document {
+		if name == attrData || name == attrDataBase64 {
+			continue
+		}
+
+		value, err := decodeAttributeValue(raw)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid attribute %q", name)
+		}
+
+		result.setAttribute(name, value)
+	}
+
+	result.Data, err = decodeData(document, result.DataContentType)
+	if err != nil {
+		return err
+	}
+
+	err = result.Validate()
+	if err != nil {
+		return err
+	}
+
+	*e = result
+
+	return nil
+}
+
+func decodeData(document map[string]json.RawMessage, contentType string) ([]byte, error) {
+	data, hasData := document[attrData]
+	encoded, hasBase64 := document[attrDataBase64]
+
+	switch {
+	case hasData && hasBase64:
+		return nil, errDataAndBase64
+	case hasBase64:
+		var value string
+
+		err := json.Unmarshal(encoded, &value)
+		if err != nil {
+			return nil, errors.WithMessagef(errNotAString, "%q", attrDataBase64)
+		}
+
+		result, err := base64.StdEncoding.DecodeString(value)
+
+		return result, errors.Wrap(err, "could not decode data_base64")
+	case hasData && !isJSONContentType(contentType) && isJSONString(data):
+		var value string
+
+		err := json.Unmarshal(data, &value)
+
+		return []byte(value), errors.Wrap(err, "could not decode data")
+	case hasData:
+		return []byte(data), nil
+	}
+
+	return nil, nil
+}
+
+// decodeAttributeValue returns string attributes unquoted and any other json value
+// (number, boolean) as its json text.
+func decodeAttributeValue(raw json.RawMessage) (string, error) {
+	if !isJSONString(raw) {
+		trimmed := strings.TrimSpace(string(raw))
+		if trimmed == "null" || strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			return "", errNotAString
+		}
+
+		return trimmed, nil
+	}
+
+	var value string
+
+	err := json.Unmarshal(raw, &value)
+
+	return value, errors.Wrap(err, "could not decode attribute")
+}
+
+func isJSONString(raw json.RawMessage) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(raw)), `"`)
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])) //nolint:gomnd
+
+	return mediaType == contentTypeJSON || strings.HasSuffix(mediaType, "+json") ||
+		strings.HasPrefix(mediaType, "text/json")
+}
+
+// embeddable reports whether the data is JSON that the encoder writes unchanged, it compacts
+// and escapes embedded JSON.
+func embeddable(data []byte) bool {
+	if !json.Valid(data) {
+		return false
+	}
+
+	encoded, err := json.Marshal(json.RawMessage(data))
+
+	return err == nil && bytes.Equal(encoded, data)
+}
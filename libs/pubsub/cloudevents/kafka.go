@@ -0,0 +1,130 @@
+package cloudevents
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	kafkaHeaderPrefix = "ce_"
+	kafkaContentType  = "content-type"
+)
+
+// KafkaHeader is a kafka record header.
+type KafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// KafkaMessage is a client agnostic kafka record, adapters copy it into the record type
+// of the kafka client they use.
+type KafkaMessage struct {
+	Key     []byte
+	Value   []byte
+	Headers []KafkaHeader
+}
+
+// ToKafkaMessage creates a kafka record carrying the event according to the content mode.
+// The partitionkey extension, when present, becomes the record key.
+func ToKafkaMessage(event Event, mode ContentMode) (KafkaMessage, error) {
+	var result KafkaMessage
+
+	if key, ok := event.Extensions[PartitionKeyExtension]; ok {
+		result.Key = []byte(key)
+	}
+
+	switch mode {
+	case Structured:
+		value, err := event.MarshalJSON()
+		if err != nil {
+			return KafkaMessage{}, err
+		}
+
+		result.Value = value
+		result.Headers = []KafkaHeader{{Key: kafkaContentType, Value: []byte(contentTypeCloudEventJSON)}}
+
+		return result, nil
+	case Binary:
+		err := event.Validate()
+		if err != nil {
+			return KafkaMessage{}, err
+		}
+
+		attributes := event.attributes()
+		names := make([]string, 0, len(attributes))
+
+		for name := range attributes {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			value := attributes[name]
+			key := kafkaHeaderPrefix + name
+			if name == attrDataContentType {
+				key = kafkaContentType
+			}
+
+			result.Headers = append(result.Headers, KafkaHeader{Key: key, Value: []byte(value)})
+		}
+
+		result.Value = event.Data
+
+		return result, nil
+	}
+
+	return KafkaMessage{}, errors.WithMessagef(errUnknownContentMode, "%d", mode)
+}
+
+// FromKafkaMessage reads the event carried by a kafka record in any content mode.
+// A record key without a partitionkey extension is kept as the partitionkey extension.
+func FromKafkaMessage(message KafkaMessage) (Event, error) {
+	event, err := readKafkaMessage(message)
+	if err != nil {
+		return Event{}, err
+	}
+
+	if _, ok := event.Extensions[PartitionKeyExtension]; !ok && len(message.Key) > 0 {
+		event = event.WithExtension(PartitionKeyExtension, string(message.Key))
+	}
+
+	return event, nil
+}
+
+func readKafkaMessage(message KafkaMessage) (Event, error) {
+	var event Event
+
+	for _, header := range message.Headers {
+		key := strings.ToLower(header.Key)
+		if key == kafkaContentType && isStructuredContentType(string(header.Value)) {
+			err := event.UnmarshalJSON(message.Value)
+
+			return event, err
+		}
+	}
+
+	for _, header := range message.Headers {
+		key := strings.ToLower(header.Key)
+
+		switch {
+		case key == kafkaContentType:
+			event.DataContentType = string(header.Value)
+		case strings.HasPrefix(key, kafkaHeaderPrefix):
+			event.setAttribute(strings.TrimPrefix(key, kafkaHeaderPrefix), string(header.Value))
+		}
+	}
+
+	if len(message.Value) > 0 {
+		event.Data = message.Value
+	}
+
+	err := event.Validate()
+	if err != nil {
+		return Event{}, err
+	}
+
+	return event, nil
+}
@@ -0,0 +1,76 @@
+package cloudevents_test
+
+import (
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/cloudevents"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaBinaryMode(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudEventFixture().WithExtension("partitionkey", "account-1")
+	expectedHeaders := []cloudevents.KafkaHeader{
+		{Key: "ce_application", Value: []byte("core-app")},
+		{Key: "content-type", Value: []byte("application/json")},
+		{Key: "ce_dataschema", Value: []byte("0.1.0")},
+		{Key: "ce_id", Value: []byte("123-456-789")},
+		{Key: "ce_partitionkey", Value: []byte("account-1")},
+		{Key: "ce_source", Value: []byte("loans")},
+		{Key: "ce_specversion", Value: []byte("1.0")},
+		{Key: "ce_type", Value: []byte("orders")},
+	}
+	// When
+	message, err := cloudevents.ToKafkaMessage(expectedEvent, cloudevents.Binary)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := cloudevents.FromKafkaMessage(message)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("account-1"), message.Key)
+	assert.Equal(t, expectedHeaders, message.Headers)
+	assert.Equal(t, expectedEvent.Data, message.Value)
+	assert.Equal(t, expectedEvent, got)
+}
+
+func TestKafkaStructuredMode(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := cloudEventFixture()
+	// When
+	message, err := cloudevents.ToKafkaMessage(expectedEvent, cloudevents.Structured)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := cloudevents.FromKafkaMessage(message)
+	// Then
+	assert.NoError(t, err)
+	assert.Nil(t, message.Key)
+	assert.Equal(t, []cloudevents.KafkaHeader{
+		{Key: "content-type", Value: []byte("application/cloudevents+json")},
+	}, message.Headers)
+	assert.Equal(t, expectedEvent, got)
+}
+
+func TestKafkaRecordKeyBecomesPartitionKey(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	message, err := cloudevents.ToKafkaMessage(cloudEventFixture(), cloudevents.Structured)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	message.Key = []byte("account-2")
+	// When
+	got, err := cloudevents.FromKafkaMessage(message)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "account-2", got.Extensions["partitionkey"])
+}
@@ -0,0 +1,79 @@
+// Command bridge relays events between two event buses as configured in a yaml file, event
+// buses are given as urls like in the eventbus package.
+//
+//	bridge -config bridge.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/bridge"
+)
+
+// shutdownTimeout time given to the events being forwarded when the bridge is stopped.
+const shutdownTimeout = 30 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:])
+
+	stop()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run forwards events until the context is done, then it waits for the events being forwarded.
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bridge", flag.ContinueOnError)
+	configPath := flags.String("config", "bridge.yaml", "path of the bridge yaml configuration")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	config, err := bridge.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	relay, err := bridge.FromConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	log.Println("message", "bridge started", "source", config.Source.URL, "destination", config.Destination.URL)
+
+	ran := make(chan error, 1)
+
+	go func() { ran <- relay.Run(context.Background()) }()
+
+	select {
+	case err = <-ran:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("message", "bridge stopping")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err = relay.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	return <-ran
+}
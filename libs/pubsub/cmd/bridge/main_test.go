@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunForwardsEventsBetweenFileBuses(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+
+	defer cancel()
+
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "source.ndjson")
+	destinationPath := filepath.Join(dir, "destination.ndjson")
+	configPath := filepath.Join(dir, "bridge.yaml")
+	config := fmt.Sprintf(`
+source:
+  url: file://%s
+  channels: [orders, credit.>]
+  position: earliest
+destination:
+  url: file://%s
+channels:
+  credit.loan.*: credit.loans
+  credit.>: credit.other
+  orders: credit.orders
+workers: 2
+`, filepath.ToSlash(sourcePath), filepath.ToSlash(destinationPath))
+
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	source, err := eventbus.OpenPublisher(ctx, "file://"+filepath.ToSlash(sourcePath))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_ = source.Publish(ctx, "orders", eventFixture("1", "account-1"))
+	_ = source.Publish(ctx, "credit.loan.created", eventFixture("2", ""))
+	_ = source.Publish(ctx, "orders", eventFixture("3", "account-1"))
+	_ = source.Publish(ctx, "credit.payment.created", eventFixture("4", ""))
+
+	runCtx, stop := context.WithCancel(ctx)
+	ran := make(chan error, 1)
+	// When
+	go func() { ran <- run(runCtx, []string{"-config", configPath}) }()
+	// Then
+	assert.Eventually(t, func() bool {
+		return len(recordsOf(t, destinationPath)) == 4
+	}, 3*time.Second, 10*time.Millisecond)
+	stop()
+	assert.NoError(t, <-ran)
+
+	forwarded := make(map[string][]string)
+
+	for _, record := range recordsOf(t, destinationPath) {
+		forwarded[record.Channel] = append(forwarded[record.Channel], record.Event.Header.ID)
+		assert.Equal(t, "eu-west", record.Event.Header.Attribute("region"))
+	}
+
+	assert.Equal(t, map[string][]string{
+		"credit.orders": {"1", "3"},
+		"credit.loans":  {"2"},
+		"credit.other":  {"4"},
+	}, forwarded)
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	dir := t.TempDir()
+	invalidPath := filepath.Join(dir, "invalid.yaml")
+	_ = os.WriteFile(invalidPath, []byte("source: {url: file://bus.ndjson}\n"), 0o600)
+
+	tests := map[string][]string{
+		"unknown flag":          {"-retries", "3"},
+		"missing configuration": {"-config", filepath.Join(dir, "missing.yaml")},
+		"invalid configuration": {"-config", invalidPath},
+	}
+
+	for name, args := range tests {
+		args := args
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			err := run(context.TODO(), args)
+			// Then
+			assert.Error(t, err)
+		})
+	}
+}
+
+func recordsOf(t *testing.T, path string) []capture.Record {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+
+	defer file.Close()
+
+	var records []capture.Record
+
+	reader := capture.NewReader(file)
+
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			return records
+		}
+
+		records = append(records, record)
+	}
+}
+
+func eventFixture(id, orderingKey string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{
+			ID:          id,
+			Domain:      "loans",
+			EventType:   "orders",
+			Version:     "0.1.0",
+			Application: "core-app",
+			OrderingKey: orderingKey,
+			Attributes:  map[string]string{"region": "eu-west"},
+		},
+		Data: []byte(`{"amount": 10}`),
+	}
+}
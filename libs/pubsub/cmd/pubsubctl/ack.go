@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var errMissingMessageIDs = errors.New("message ids to acknowledge are required")
+
+// ack acknowledges the given message ids of the channel.
+func ack(ctx context.Context, args []string, _ io.Reader, stdout io.Writer) error {
+	flags := newFlagSet("ack")
+	busURL := flags.String("url", "", "event bus url")
+	channel := flags.String("channel", "", "channel the messages belong to")
+	group := flags.String("group", "", "consumer group the messages were delivered to")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errMissingMessageIDs
+	}
+
+	var channels []string
+	if *channel != "" {
+		channels = []string{*channel}
+	}
+
+	subscriber, err := subscribe(ctx, subscription{url: *busURL, channels: channels, group: *group})
+	if err != nil {
+		return err
+	}
+
+	for _, messageID := range flags.Args() {
+		err = subscriber.Acknowledge(ctx, messageID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, "acknowledged", messageID)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const (
+	messagesPerPull = 10
+	offsetPrefix    = "offset:"
+	positionUsage   = "position to start reading from: earliest, latest, a RFC 3339 time or offset:<offset>"
+	// pendingUsage is the position usage of commands reading the events not acknowledged yet.
+	pendingUsage = positionUsage + ", empty reads the events not acknowledged yet"
+)
+
+var (
+	errMissingURL      = errors.New("-url is required")
+	errMissingChannel  = errors.New("at least one -channel is required")
+	errInvalidPosition = errors.New("invalid position, expected earliest, latest, a RFC 3339 time or offset:<offset>")
+)
+
+type subscription struct {
+	url      string
+	channels []string
+	group    string
+	filter   routing.Filter
+	position string
+}
+
+// subscribe opens the event bus and subscribes to the channels.
+func subscribe(ctx context.Context, settings subscription) (*subscribers.Subscriber, error) {
+	if settings.url == "" {
+		return nil, errMissingURL
+	}
+
+	if len(settings.channels) == 0 {
+		return nil, errMissingChannel
+	}
+
+	eventBus, err := eventbus.OpenSubscriber(ctx, settings.url)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        eventBus,
+		MessagesPerPull: messagesPerPull,
+		Group:           settings.group,
+		Filter:          settings.filter,
+	})
+
+	for _, channel := range settings.channels {
+		err = subscriber.Subscribe(ctx, channel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.position == "" {
+		return subscriber, nil
+	}
+
+	position, err := parsePosition(settings.position)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range settings.channels {
+		err = subscriber.Seek(ctx, channel, position)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return subscriber, nil
+}
+
+// parsePosition parses earliest, latest, a RFC 3339 time or an offset prefixed by offset:.
+func parsePosition(value string) (subscribers.Position, error) {
+	switch {
+	case value == "earliest":
+		return subscribers.Earliest(), nil
+	case value == "latest":
+		return subscribers.Latest(), nil
+	case strings.HasPrefix(value, offsetPrefix):
+		return subscribers.AtOffset(strings.TrimPrefix(value, offsetPrefix)), nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return subscribers.Position{}, errors.WithMessagef(errInvalidPosition, "%q", value)
+	}
+
+	return subscribers.AtTime(at), nil
+}
+
+// pullAll pulls until the event bus has no more pending events or the limit is reached,
+// zero means no limit. Every event is handed to the function as it is pulled.
+func pullAll(ctx context.Context, subscriber *subscribers.Subscriber, limit int, handle func(messages.Event) error) error {
+	var count int
+
+	for limit <= 0 || count < limit {
+		events, err := subscriber.Pull(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		for _, event := range events {
+			if limit > 0 && count == limit {
+				return nil
+			}
+
+			err = handle(event)
+			if err != nil {
+				return err
+			}
+
+			count++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+)
+
+// captureEvents records the events of the channels as they arrive until interrupted, -count
+// events or -duration.
+func captureEvents(ctx context.Context, args []string, _ io.Reader, stdout io.Writer) error {
+	var channels, filters stringsFlag
+
+	flags := newFlagSet("capture")
+	busURL := flags.String("url", "", "event bus url")
+	flags.Var(&channels, "channel", "channel or channel pattern to capture, can be repeated")
+	flags.Var(&filters, "filter", "filter like domain=loans or version>=2, can be repeated")
+	position := flags.String("position", "latest", positionUsage)
+	group := flags.String("group", "", "consumer group, leave it empty to get every event")
+	output := flags.String("output", "-", "ndjson file to write, .gz and .zst files are compressed, - writes stdout")
+	count := flags.Int("count", 0, "stop after this number of events, zero captures until interrupted")
+	duration := flags.Duration("duration", 0, "stop after this time, zero captures until interrupted")
+	acknowledge := flags.Bool("ack", false, "acknowledge the captured events")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	filter, err := routing.ParseFilter(filters)
+	if err != nil {
+		return err
+	}
+
+	writer, closeOutput, err := createOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	err = record(ctx, subscription{
+		url:      *busURL,
+		channels: channels,
+		group:    *group,
+		filter:   filter,
+		position: *position,
+	}, writer, *count, *duration, *acknowledge)
+	if err != nil {
+		_ = closeOutput()
+
+		return err
+	}
+
+	return closeOutput()
+}
+
+// record writes the streamed events, the ones left out by the filter are not recorded.
+func record(
+	ctx context.Context, settings subscription, writer *capture.Writer, count int, duration time.Duration, acknowledge bool,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	subscriber, err := subscribe(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	stream, err := subscriber.Stream(ctx)
+	if err != nil {
+		return err
+	}
+
+	var captured int
+
+	for event := range stream {
+		err = writer.Write(capture.Record{Channel: event.Header.Channel, Time: time.Now().UTC(), Event: event})
+		if err != nil {
+			return err
+		}
+
+		if acknowledge {
+			err = subscriber.Acknowledge(ctx, event.Header.MessageID)
+			if err != nil {
+				return err
+			}
+		}
+
+		captured++
+		if count > 0 && captured == count {
+			return nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return subscriber.Err()
+}
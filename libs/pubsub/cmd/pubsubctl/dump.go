@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+)
+
+// dump writes the events of the channels not acknowledged yet as ndjson records, or the ones
+// from the given position.
+func dump(ctx context.Context, args []string, _ io.Reader, stdout io.Writer) error {
+	var channels stringsFlag
+
+	flags := newFlagSet("dump")
+	busURL := flags.String("url", "", "event bus url")
+	flags.Var(&channels, "channel", "channel or channel pattern to dump, can be repeated")
+	position := flags.String("position", "", pendingUsage)
+	group := flags.String("group", "", "consumer group, leave it empty to get every event")
+	output := flags.String("output", "-", "ndjson file to write, .gz and .zst files are compressed, - writes stdout")
+	count := flags.Int("count", 0, "maximum number of events to dump, zero dumps them all")
+	acknowledge := flags.Bool("ack", false, "acknowledge the dumped events")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	subscriber, err := subscribe(ctx, subscription{url: *busURL, channels: channels, group: *group, position: *position})
+	if err != nil {
+		return err
+	}
+
+	writer, closeOutput, err := createOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	err = pullAll(ctx, subscriber, *count, func(event messages.Event) error {
+		err := writer.Write(capture.Record{Channel: event.Header.Channel, Time: time.Now().UTC(), Event: event})
+		if err != nil || !*acknowledge {
+			return err
+		}
+
+		return subscriber.Acknowledge(ctx, event.Header.MessageID)
+	})
+	if err != nil {
+		_ = closeOutput()
+
+		return err
+	}
+
+	return closeOutput()
+}
+
+// createOutput returns a writer of the capture file, - writes into stdout, and the function
+// flushing and closing it.
+func createOutput(path string, stdout io.Writer) (*capture.Writer, func() error, error) {
+	if path == "-" {
+		return capture.NewWriter(stdout), func() error { return nil }, nil
+	}
+
+	file, err := capture.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file.Writer, file.Close, nil
+}
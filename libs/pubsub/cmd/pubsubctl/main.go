@@ -0,0 +1,102 @@
+// Command pubsubctl publishes, tails and inspects the events of an event bus, event buses are
+// given as urls like in the eventbus package. memory:// event buses live as long as a single
+// command, file:// ones keep their events between commands.
+//
+//	pubsubctl publish -url file://local.ndjson -channel orders -file events.ndjson
+//	pubsubctl publish -url file://local.ndjson -file incident.ndjson.zst -speed 10
+//	pubsubctl tail -url file://local.ndjson -channel orders -filter domain=loans -filter 'version>=2'
+//	pubsubctl ack -url file://local.ndjson -channel orders <message id>...
+//	pubsubctl replay -url file://local.ndjson -from orders-dead-letter -to orders
+//	pubsubctl dump -url file://local.ndjson -channel orders -output orders.ndjson
+//	pubsubctl capture -url file://local.ndjson -channel 'credit.>' -duration 10m -output incident.ndjson.zst
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+var errUnknownCommand = errors.New("unknown command")
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"publish": {usage: "publish the events of a file or stdin", run: publish},
+	"tail":    {usage: "print the events of channels as they arrive", run: tail},
+	"ack":     {usage: "acknowledge messages by id", run: ack},
+	"replay":  {usage: "publish the events of a dead letter channel again", run: replay},
+	"dump":    {usage: "write the events of channels not acknowledged yet as ndjson", run: dump},
+	"capture": {usage: "record the events of channels as they arrive", run: captureEvents},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout)
+
+	stop()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.Errorf("usage: pubsubctl <command> [flags]\n\ncommands:\n%s", usage())
+	}
+
+	selected, ok := commands[args[0]]
+	if !ok {
+		return errors.WithMessagef(errUnknownCommand, "%q\n\ncommands:\n%s", args[0], usage())
+	}
+
+	return selected.run(ctx, args[1:], stdin, stdout)
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var result strings.Builder
+
+	for _, name := range names {
+		fmt.Fprintf(&result, "  %-8s %s\n", name, commands[name].usage)
+	}
+
+	return result.String()
+}
+
+// stringsFlag is a flag that can be given several times.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("pubsubctl "+name, flag.ContinueOnError)
+}
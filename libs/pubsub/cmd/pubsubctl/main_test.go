@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const eventsFixture = `{"channel":"orders","header":{"id":"1","domain":"loans","version":"1"},"data":{"amount":10}}
+{"channel":"orders","header":{"id":"2","domain":"payments","version":"2"},"data":{"amount":20}}
+{"channel":"orders","header":{"id":"3","domain":"loans","version":"3","attributes":{"region":"eu-west"}},"data":{"amount":30}}
+`
+
+func TestPublishAndDump(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	output := filepath.Join(t.TempDir(), "orders.ndjson")
+	var stdout bytes.Buffer
+	// When
+	publishErr := run(context.TODO(), []string{"publish", "-url", busURL}, strings.NewReader(eventsFixture), &stdout)
+	dumpErr := run(context.TODO(), []string{
+		"dump", "-url", busURL, "-channel", "orders", "-position", "earliest", "-output", output,
+	}, nil, &stdout)
+	// Then
+	assert.NoError(t, publishErr)
+	assert.NoError(t, dumpErr)
+	assert.Equal(t, "published 3 events\n", stdout.String())
+	assert.Equal(t, []string{"1", "2", "3"}, recordIDs(t, output))
+}
+
+func TestPublishOverridesChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	var stdout bytes.Buffer
+	// When
+	err := run(context.TODO(), []string{"publish", "-url", busURL, "-channel", "payments"}, strings.NewReader(eventsFixture), &stdout)
+
+	stdout.Reset()
+
+	dumpErr := run(context.TODO(), []string{
+		"dump", "-url", busURL, "-channel", "payments", "-position", "earliest", "-count", "2",
+	}, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.NoError(t, dumpErr)
+	assert.Equal(t, 2, strings.Count(stdout.String(), "\n"))
+}
+
+func TestTailFiltersAndPrintsEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	busURL := busOf(t)
+	var stdout bytes.Buffer
+
+	_ = run(ctx, []string{"publish", "-url", busURL}, strings.NewReader(eventsFixture), &stdout)
+
+	stdout.Reset()
+	// When
+	err := run(ctx, []string{
+		"tail", "-url", busURL, "-channel", "orders", "-position", "earliest",
+		"-filter", "domain=loans", "-filter", "version>=2", "-count", "1",
+	}, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "id:            3\n")
+	assert.Contains(t, stdout.String(), "attributes.region: eu-west\n")
+	assert.Contains(t, stdout.String(), "\"amount\": 30")
+	assert.NotContains(t, stdout.String(), "id:            1\n")
+}
+
+func TestReplayMovesDeadLetterEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	output := filepath.Join(t.TempDir(), "orders.ndjson")
+	var stdout bytes.Buffer
+
+	_ = run(context.TODO(), []string{"publish", "-url", busURL, "-channel", "orders-dead-letter"}, strings.NewReader(eventsFixture), &stdout)
+
+	stdout.Reset()
+	// When
+	err := run(context.TODO(), []string{
+		"replay", "-url", busURL, "-from", "orders-dead-letter", "-to", "orders", "-position", "earliest",
+	}, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "replayed 3 events from orders-dead-letter to orders\n", stdout.String())
+	assert.NoError(t, run(context.TODO(), []string{
+		"dump", "-url", busURL, "-channel", "orders", "-position", "earliest", "-output", output,
+	}, nil, &stdout))
+	assert.Equal(t, []string{"1", "2", "3"}, recordIDs(t, output))
+}
+
+func TestReplayWithoutPositionReadsPendingEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	var stdout bytes.Buffer
+
+	_ = run(context.TODO(), []string{"publish", "-url", busURL, "-channel", "orders-dead-letter"}, strings.NewReader(eventsFixture), &stdout)
+
+	stdout.Reset()
+	// When
+	err := run(context.TODO(), []string{"replay", "-url", busURL, "-from", "orders-dead-letter", "-to", "orders"}, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "replayed 3 events from orders-dead-letter to orders\n", stdout.String())
+}
+
+func TestReplayTwiceOnlyPublishesNewEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	output := filepath.Join(t.TempDir(), "orders.ndjson")
+	replayArgs := []string{"replay", "-url", busURL, "-from", "orders-dead-letter", "-to", "orders"}
+	var stdout bytes.Buffer
+
+	_ = run(context.TODO(), []string{"publish", "-url", busURL, "-channel", "orders-dead-letter"}, strings.NewReader(eventsFixture), &stdout)
+	_ = run(context.TODO(), replayArgs, nil, &stdout)
+
+	stdout.Reset()
+	// When
+	err := run(context.TODO(), replayArgs, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "replayed 0 events from orders-dead-letter to orders\n", stdout.String())
+	assert.NoError(t, run(context.TODO(), []string{"dump", "-url", busURL, "-channel", "orders", "-output", output}, nil, &stdout))
+	assert.Equal(t, []string{"1", "2", "3"}, recordIDs(t, output))
+}
+
+func TestDumpSkipsAcknowledgedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	output := filepath.Join(t.TempDir(), "orders.ndjson")
+	var stdout bytes.Buffer
+
+	_ = run(context.TODO(), []string{"publish", "-url", busURL}, strings.NewReader(eventsFixture), &stdout)
+	_ = run(context.TODO(), []string{"ack", "-url", busURL, "-channel", "orders", "orders/0"}, nil, &stdout)
+	// When
+	err := run(context.TODO(), []string{"dump", "-url", busURL, "-channel", "orders", "-output", output}, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, recordIDs(t, output))
+}
+
+func TestCaptureAndPublishCompressedFile(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	busURL := busOf(t)
+	targetURL := busOf(t)
+	output := filepath.Join(t.TempDir(), "incident.ndjson.zst")
+	var stdout bytes.Buffer
+
+	_ = run(ctx, []string{"publish", "-url", busURL}, strings.NewReader(eventsFixture), &stdout)
+
+	stdout.Reset()
+	// When
+	captureErr := run(ctx, []string{
+		"capture", "-url", busURL, "-channel", "orders", "-position", "earliest", "-count", "3", "-output", output,
+	}, nil, &stdout)
+	publishErr := run(ctx, []string{
+		"publish", "-url", targetURL, "-file", output, "-speed", "10",
+	}, nil, &stdout)
+	dumpErr := run(ctx, []string{
+		"dump", "-url", targetURL, "-channel", "orders", "-position", "earliest",
+	}, nil, &stdout)
+	// Then
+	assert.NoError(t, captureErr)
+	assert.NoError(t, publishErr)
+	assert.NoError(t, dumpErr)
+	assert.True(t, strings.HasPrefix(stdout.String(), "published 3 events\n"))
+	assert.Equal(t, 4, strings.Count(stdout.String(), "\n"))
+}
+
+func TestCaptureFiltersEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	busURL := busOf(t)
+	output := filepath.Join(t.TempDir(), "loans.ndjson")
+
+	_ = run(ctx, []string{"publish", "-url", busURL}, strings.NewReader(eventsFixture), new(bytes.Buffer))
+	// When
+	err := run(ctx, []string{
+		"capture", "-url", busURL, "-channel", "orders", "-position", "earliest",
+		"-filter", "domain=loans", "-count", "2", "-output", output,
+	}, nil, new(bytes.Buffer))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "3"}, recordIDs(t, output))
+}
+
+func TestAck(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	busURL := busOf(t)
+	var stdout bytes.Buffer
+
+	_ = run(context.TODO(), []string{"publish", "-url", busURL}, strings.NewReader(eventsFixture), &stdout)
+
+	stdout.Reset()
+	// When
+	err := run(context.TODO(), []string{"ack", "-url", busURL, "-channel", "orders", "orders/0", "orders/1"}, nil, &stdout)
+	unknownErr := run(context.TODO(), []string{"ack", "-url", busURL, "-channel", "orders", "orders/9"}, nil, &stdout)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "acknowledged orders/0\nacknowledged orders/1\n", stdout.String())
+	assert.EqualError(t, unknownErr, `unexpected error acknowledging message: "orders/9": unknown message id`)
+}
+
+func TestRunErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args          []string
+		expectedError error
+	}{
+		"unknown command": {
+			args:          []string{"list"},
+			expectedError: errUnknownCommand,
+		},
+		"missing url": {
+			args:          []string{"dump", "-channel", "orders"},
+			expectedError: errMissingURL,
+		},
+		"missing channel": {
+			args:          []string{"tail", "-url", "memory://errors"},
+			expectedError: errMissingChannel,
+		},
+		"invalid position": {
+			args:          []string{"dump", "-url", "memory://errors", "-channel", "orders", "-position", "yesterday"},
+			expectedError: errInvalidPosition,
+		},
+		"missing replay channels": {
+			args:          []string{"replay", "-url", "memory://errors", "-from", "orders-dead-letter"},
+			expectedError: errMissingReplayChannels,
+		},
+		"missing message ids": {
+			args:          []string{"ack", "-url", "memory://errors", "-channel", "orders"},
+			expectedError: errMissingMessageIDs,
+		},
+		"record without channel": {
+			args:          []string{"publish", "-url", "memory://errors"},
+			expectedError: capture.ErrNoChannel,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			stdin := strings.NewReader(`{"header":{"id":"1"}}`)
+			// When
+			err := run(context.TODO(), test.args, stdin, new(bytes.Buffer))
+			// Then
+			assert.True(t, errors.Is(err, test.expectedError), "got %v", err)
+		})
+	}
+}
+
+// busOf returns the url of a new event bus kept in a file of the test, so every command run by
+// the test shares it like separate pubsubctl invocations do.
+func busOf(t *testing.T) string {
+	t.Helper()
+
+	return "file://" + filepath.ToSlash(filepath.Join(t.TempDir(), "bus.ndjson"))
+}
+
+func recordIDs(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	defer file.Close()
+
+	var ids []string
+
+	reader := capture.NewReader(file)
+
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			return ids
+		}
+
+		ids = append(ids, record.Event.Header.ID)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+)
+
+// publish publishes the records of a capture file or stdin, -channel overrides their channels
+// and -speed replays them with their captured timing.
+func publish(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := newFlagSet("publish")
+	busURL := flags.String("url", "", "event bus url")
+	channel := flags.String("channel", "", "channel to publish into, defaults to the record channel")
+	file := flags.String("file", "-", "ndjson file with the events, .gz and .zst files are decompressed, - reads stdin")
+	speed := flags.Float64("speed", capture.AsFastAsPossible,
+		"1 keeps the captured time between events, 10 is ten times faster, 0 does not wait")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *busURL == "" {
+		return errMissingURL
+	}
+
+	eventBus, err := eventbus.OpenPublisher(ctx, *busURL)
+	if err != nil {
+		return err
+	}
+
+	reader := capture.NewReader(stdin)
+
+	if *file != "-" {
+		opened, err := capture.Open(*file)
+		if err != nil {
+			return err
+		}
+
+		defer opened.Close()
+
+		reader = opened.Reader
+	}
+
+	count, err := capture.Replay(ctx, reader, eventBus, capture.ReplaySettings{Speed: *speed, Channel: *channel})
+
+	fmt.Fprintf(stdout, "published %d events\n", count)
+
+	return err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+)
+
+var errMissingReplayChannels = errors.New("-from and -to are required")
+
+// replay publishes the events of the dead letter channel into the target channel, every event
+// is acknowledged on the dead letter channel once it is published, so replaying again only
+// publishes the events that arrived since.
+func replay(ctx context.Context, args []string, _ io.Reader, stdout io.Writer) error {
+	flags := newFlagSet("replay")
+	busURL := flags.String("url", "", "event bus url")
+	from := flags.String("from", "", "dead letter channel to replay")
+	to := flags.String("to", "", "channel to publish the events into")
+	position := flags.String("position", "", pendingUsage)
+	group := flags.String("group", "", "consumer group of the dead letter channel")
+	count := flags.Int("count", 0, "maximum number of events to replay, zero replays them all")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return errMissingReplayChannels
+	}
+
+	subscriber, err := subscribe(ctx, subscription{url: *busURL, channels: []string{*from}, group: *group, position: *position})
+	if err != nil {
+		return err
+	}
+
+	eventBus, err := eventbus.OpenPublisher(ctx, *busURL)
+	if err != nil {
+		return err
+	}
+
+	publisher := publishers.New(eventBus)
+
+	var replayed int
+
+	err = pullAll(ctx, subscriber, *count, func(event messages.Event) error {
+		messageID := event.Header.MessageID
+		event.Header.MessageID = ""
+		event.Header.Channel = ""
+
+		err := publisher.Publish(ctx, publishers.EventMessage{ChannelName: *to, Event: event})
+		if err != nil {
+			return err
+		}
+
+		replayed++
+
+		return subscriber.Acknowledge(ctx, messageID)
+	})
+
+	fmt.Fprintf(stdout, "replayed %d events from %s to %s\n", replayed, *from, *to)
+
+	return err
+}
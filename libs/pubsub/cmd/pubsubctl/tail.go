@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+)
+
+// tail prints the events of the channels as they arrive until interrupted or -count events.
+func tail(ctx context.Context, args []string, _ io.Reader, stdout io.Writer) error {
+	var channels, filters stringsFlag
+
+	flags := newFlagSet("tail")
+	busURL := flags.String("url", "", "event bus url")
+	flags.Var(&channels, "channel", "channel or channel pattern to tail, can be repeated")
+	flags.Var(&filters, "filter", "filter like domain=loans or version>=2, can be repeated")
+	position := flags.String("position", "latest", positionUsage)
+	group := flags.String("group", "", "consumer group, leave it empty to get every event")
+	count := flags.Int("count", 0, "stop after this number of events, zero tails forever")
+	acknowledge := flags.Bool("ack", false, "acknowledge the printed events")
+	ndjson := flags.Bool("ndjson", false, "print ndjson records instead of pretty headers")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	filter, err := routing.ParseFilter(filters)
+	if err != nil {
+		return err
+	}
+
+	subscriber, err := subscribe(ctx, subscription{url: *busURL, channels: channels, group: *group, filter: filter, position: *position})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := subscriber.Stream(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := capture.NewWriter(stdout)
+
+	var printed int
+
+	for event := range stream {
+		if *ndjson {
+			err = writer.Write(capture.Record{Channel: event.Header.Channel, Event: event})
+		} else {
+			err = printEvent(stdout, event)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if *acknowledge {
+			err = subscriber.Acknowledge(ctx, event.Header.MessageID)
+			if err != nil {
+				return err
+			}
+		}
+
+		printed++
+		if *count > 0 && printed == *count {
+			return nil
+		}
+	}
+
+	return subscriber.Err()
+}
+
+// printEvent prints the headers aligned and the data as indented json, text or base64.
+func printEvent(writer io.Writer, event messages.Event) error {
+	header := event.Header
+	lines := [][2]string{
+		{"channel", header.Channel},
+		{"id", header.ID},
+		{"domain", header.Domain},
+		{"eventtype", header.EventType},
+		{"version", header.Version},
+		{"application", header.Application},
+		{"messageid", header.MessageID},
+		{"orderingkey", header.OrderingKey},
+	}
+
+	keys := make([]string, 0, len(header.Attributes))
+	for key := range header.Attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lines = append(lines, [2]string{"attributes." + key, header.Attributes[key]})
+	}
+
+	var output strings.Builder
+
+	output.WriteString("---\n")
+
+	for _, line := range lines {
+		if line[1] != "" {
+			fmt.Fprintf(&output, "%-14s %s\n", line[0]+":", line[1])
+		}
+	}
+
+	output.WriteString(formatData(event.Data))
+	output.WriteString("\n")
+
+	_, err := io.WriteString(writer, output.String())
+
+	return err
+}
+
+func formatData(data []byte) string {
+	var indented bytes.Buffer
+
+	if json.Indent(&indented, data, "", "  ") == nil {
+		return indented.String()
+	}
+
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	return "base64:" + base64.StdEncoding.EncodeToString(data)
+}
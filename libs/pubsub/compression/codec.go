@@ -0,0 +1,158 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// MaxDecompressedSize limits the decompressed data size to protect consumers from
+// decompression bombs.
+const MaxDecompressedSize = 64 << 20
+
+var errTooLarge = errors.New("decompressed data exceeds the maximum size")
+
+// Codec compresses and decompresses data.
+type Codec interface {
+	// Name is recorded in the event header, it must be unique among codecs.
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	// Gzip codec using the standard library gzip implementation.
+	Gzip Codec = gzipCodec{level: gzip.DefaultCompression}
+	// Zstd codec using zstandard at its default level.
+	Zstd Codec = newZstdCodec()
+	// Snappy codec using the snappy block format.
+	Snappy Codec = snappyCodec{}
+)
+
+// NewGzip returns a gzip codec with the given compression level.
+func NewGzip(level int) (Codec, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return nil, errors.Errorf("invalid gzip compression level %d", level)
+	}
+
+	return gzipCodec{level: level}, nil
+}
+
+type gzipCodec struct {
+	level int
+}
+
+func (g gzipCodec) Name() string {
+	return "gzip"
+}
+
+func (g gzipCodec) Compress(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer, err := gzip.NewWriterLevel(&buffer, g.level)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create gzip writer")
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not gzip data")
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not gzip data")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (g gzipCodec) Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read gzip data")
+	}
+
+	defer reader.Close()
+
+	result, err := io.ReadAll(io.LimitReader(reader, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not gunzip data")
+	}
+
+	if len(result) > MaxDecompressedSize {
+		return nil, errTooLarge
+	}
+
+	return result, nil
+}
+
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// newZstdCodec creates the shared zstd encoder and decoder, both are safe for concurrent
+// use through EncodeAll and DecodeAll.
+func newZstdCodec() zstdCodec {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecompressedSize))
+	if err != nil {
+		panic(err)
+	}
+
+	return zstdCodec{encoder: encoder, decoder: decoder}
+}
+
+func (z zstdCodec) Name() string {
+	return "zstd"
+}
+
+func (z zstdCodec) Compress(data []byte) ([]byte, error) {
+	return z.encoder.EncodeAll(data, nil), nil
+}
+
+func (z zstdCodec) Decompress(data []byte) ([]byte, error) {
+	result, err := z.decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decompress zstd data")
+	}
+
+	return result, nil
+}
+
+type snappyCodec struct{}
+
+func (s snappyCodec) Name() string {
+	return "snappy"
+}
+
+func (s snappyCodec) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (s snappyCodec) Decompress(data []byte) ([]byte, error) {
+	length, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read snappy data")
+	}
+
+	if length > MaxDecompressedSize {
+		return nil, errTooLarge
+	}
+
+	result, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decompress snappy data")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package compression
+
+import (
+	"context"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EncodingAttribute header attribute with the name of the codec the data is compressed with.
+	EncodingAttribute = "contentencoding"
+
+	// DefaultThreshold data size in bytes below which compressing does not pay off.
+	DefaultThreshold = 1 << 10
+)
+
+var errUnknownEncoding = errors.New("unknown content encoding")
+
+// Settings contains the publisher compression configuration.
+type Settings struct {
+	// Codec used to compress the data.
+	Codec Codec
+	// Threshold data size in bytes from which data is compressed, DefaultThreshold when it
+	// is zero.
+	Threshold int
+}
+
+// Publisher returns a publisher middleware that compresses the data of events larger than the
+// threshold. Data is published as it is when compressing does not make it smaller.
+func Publisher(settings Settings) publishers.Middleware {
+	threshold := settings.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	return publishers.Transform(
+		func(_ context.Context, _ string, event messages.Event) (messages.Event, error) {
+			if len(event.Data) < threshold || event.Header.Attribute(EncodingAttribute) != "" {
+				return event, nil
+			}
+
+			compressed, err := settings.Codec.Compress(event.Data)
+			if err != nil {
+				return messages.Event{}, errors.WithMessagef(err, "could not compress event %q", event.Header.ID)
+			}
+
+			if len(compressed) >= len(event.Data) {
+				return event, nil
+			}
+
+			event.Header = event.Header.WithAttribute(EncodingAttribute, settings.Codec.Name())
+			event.Data = compressed
+
+			return event, nil
+		},
+	)
+}
+
+// Subscriber returns a subscriber middleware that decompresses events with the codec named in
+// their header. It knows every codec of this package when no codec is given.
+func Subscriber(codecs ...Codec) subscribers.Middleware {
+	if len(codecs) == 0 {
+		codecs = []Codec{Gzip, Zstd, Snappy}
+	}
+
+	byName := make(map[string]Codec, len(codecs))
+	for _, codec := range codecs {
+		byName[codec.Name()] = codec
+	}
+
+	return subscribers.Transform(
+		func(_ context.Context, event messages.Event) (messages.Event, error) {
+			encoding := event.Header.Attribute(EncodingAttribute)
+			if encoding == "" {
+				return event, nil
+			}
+
+			codec, ok := byName[encoding]
+			if !ok {
+				return messages.Event{}, errors.WithMessagef(errUnknownEncoding, "%q", encoding)
+			}
+
+			data, err := codec.Decompress(event.Data)
+			if err != nil {
+				return messages.Event{}, errors.WithMessagef(err, "could not decompress event %q", event.Header.ID)
+			}
+
+			event.Header = event.Header.WithoutAttribute(EncodingAttribute)
+			event.Data = data
+
+			return event, nil
+		},
+	)
+}
@@ -0,0 +1,205 @@
+package compression_test
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/compression"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, codec := range []compression.Codec{compression.Gzip, compression.Zstd, compression.Snappy} {
+		codec := codec
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			ctx := context.TODO()
+			expectedEvent := eventMessageFixture(transactionBatchFixture(100))
+			eventBus := new(eventBusMock)
+			publisher := publishers.New(publishers.Chain(eventBus, compression.Publisher(
+				compression.Settings{Codec: codec},
+			)))
+			subscriber := subscribers.New(subscribers.Settings{
+				EventBus:        subscribers.Chain(eventBus, compression.Subscriber()),
+				MessagesPerPull: 1,
+			})
+			// When
+			err := publisher.Publish(ctx, publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got, err := subscriber.Pull(ctx)
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, []messages.Event{expectedEvent}, got)
+			assert.Equal(t, codec.Name(), eventBus.events[0].Header.Attribute(compression.EncodingAttribute))
+			assert.Less(t, len(eventBus.events[0].Data), len(expectedEvent.Data))
+		})
+	}
+}
+
+func TestIncompressibleDataIsPublishedRaw(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	data := make([]byte, 4096)
+	_, _ = rand.Read(data)
+	expectedEvent := eventMessageFixture(data)
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, compression.Publisher(
+		compression.Settings{Codec: compression.Gzip},
+	)))
+	// When
+	err := publisher.Publish(context.TODO(), publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, eventBus.events[0])
+}
+
+func TestSmallDataIsPublishedRaw(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := eventMessageFixture([]byte(`{"value_one": "one"}`))
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, compression.Publisher(
+		compression.Settings{Codec: compression.Zstd},
+	)))
+	// When
+	err := publisher.Publish(context.TODO(), publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, eventBus.events[0])
+}
+
+func TestUnknownEncodingIsNotDelivered(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	event := eventMessageFixture([]byte("data"))
+	event.Header = event.Header.WithAttribute(compression.EncodingAttribute, "brotli")
+	eventBus := &eventBusMock{events: []messages.Event{event}}
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, compression.Subscriber(compression.Gzip)),
+		MessagesPerPull: 1,
+	})
+	// When
+	got, err := subscriber.Pull(context.TODO())
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func BenchmarkCompress(b *testing.B) {
+	data := transactionBatchFixture(1000)
+
+	for _, codec := range []compression.Codec{compression.Gzip, compression.Zstd, compression.Snappy} {
+		codec := codec
+
+		b.Run(codec.Name(), func(b *testing.B) {
+			var compressed []byte
+
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				compressed, _ = codec.Compress(data)
+			}
+
+			b.ReportMetric(float64(len(compressed))/float64(len(data)), "ratio")
+		})
+	}
+}
+
+func BenchmarkDecompress(b *testing.B) {
+	data := transactionBatchFixture(1000)
+
+	for _, codec := range []compression.Codec{compression.Gzip, compression.Zstd, compression.Snappy} {
+		codec := codec
+
+		b.Run(codec.Name(), func(b *testing.B) {
+			compressed, err := codec.Compress(data)
+			if err != nil {
+				b.Fatalf("unexpected error: %s", err)
+			}
+
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_, _ = codec.Decompress(compressed)
+			}
+		})
+	}
+}
+
+// transactionBatchFixture returns a json array of transactions like the ones the exporter ships.
+func transactionBatchFixture(size int) []byte {
+	data := []byte("[")
+
+	for i := 0; i < size; i++ {
+		if i > 0 {
+			data = append(data, ',')
+		}
+
+		data = append(data, fmt.Sprintf(
+			`{"tx_id":"tx-%06d","account":"account-%03d","amount":"%d.%02d","currency":"BTC","status":"confirmed"}`,
+			i, i%50, i*7, i%100)...)
+	}
+
+	return append(data, ']')
+}
+
+func eventMessageFixture(data []byte) messages.Event {
+	header := messages.Header{
+		ID:          "123-456-789",
+		Domain:      "loans",
+		EventType:   "orders",
+		Version:     "0.1.0",
+		Application: "core-app",
+		MessageID:   "1",
+	}
+
+	return messages.Event{
+		Header: header,
+		Data:   data,
+	}
+}
+
+// eventBusMock delivers published events to its subscribers.
+type eventBusMock struct {
+	events []messages.Event
+}
+
+func (e *eventBusMock) Publish(_ context.Context, _ string, message interface{}) error {
+	e.events = append(e.events, message.(messages.Event))
+
+	return nil
+}
+
+func (e *eventBusMock) Pull(_ context.Context, _ uint8) ([]messages.Event, error) {
+	return e.events, nil
+}
+
+func (e *eventBusMock) Stream(_ context.Context) (<-chan messages.Event, error) {
+	return nil, nil
+}
+
+func (e *eventBusMock) Subscribe(_ context.Context, _ string) error {
+	return nil
+}
+
+func (e *eventBusMock) Acknowledge(_ context.Context, _ string) error {
+	return nil
+}
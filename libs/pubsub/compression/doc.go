@@ -0,0 +1,3 @@
+// Package compression provides compression of event data. Publishers record the codec they
+// used in the event header and subscribers pick the matching codec to decompress it.
+package compression
@@ -0,0 +1,292 @@
+package consumers
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+// Handler processes an event, the event is acknowledged when it returns nil.
+type Handler func(ctx context.Context, event messages.Event) error
+
+// Settings contains the consumer configuration.
+type Settings struct {
+	// Subscriber already subscribed to the channel to consume.
+	Subscriber *subscribers.Subscriber
+	Handler    Handler
+	// Workers number of events handled concurrently, defaults to 1. Events with the same
+	// ordering key are always handled one after the other by the same worker.
+	Workers int
+	// RetryDelay rejects events whose handler fails so they are delivered again after the
+	// delay. When it is zero they are left unacknowledged for the event bus to redeliver.
+	// Events with an ordering key are retried by their worker instead, after the delay or
+	// DefaultOrderedRetryDelay when it is zero, so the next events of the key wait for them.
+	RetryDelay time.Duration
+	// Heartbeat interval between message deadline extensions while the handler runs, every
+	// extension gives twice the interval. Zero disables it.
+	Heartbeat time.Duration
+}
+
+// DefaultOrderedRetryDelay time between the attempts of a failed event with an ordering key when
+// there is no retry delay.
+const DefaultOrderedRetryDelay = time.Second
+
+// Consumer consumes events from a subscriber.
+type Consumer struct {
+	subscriber *subscribers.Subscriber
+	handler    Handler
+	workers    int
+	retryDelay time.Duration
+	heartbeat  time.Duration
+	next       int
+	mu         sync.Mutex
+	shutdown   bool
+	stop       context.CancelFunc // stops streaming new events.
+	abort      context.CancelFunc // cancels the handlers still running.
+	stopped    chan struct{}      // closed once Run returns.
+}
+
+// ErrConsumerShutdown is returned when running a consumer that was shut down.
+var ErrConsumerShutdown = errors.New("consumer is shut down")
+
+// New instances a new consumer.
+func New(settings Settings) *Consumer {
+	workers := settings.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	newConsumer := Consumer{
+		subscriber: settings.Subscriber,
+		handler:    settings.Handler,
+		workers:    workers,
+		retryDelay: settings.RetryDelay,
+		heartbeat:  settings.Heartbeat,
+	}
+
+	return &newConsumer
+}
+
+// Run streams events and hands them to the workers until the stream ends, the context is done
+// or Shutdown is called, then it waits for the workers to finish the events they are handling.
+// It returns the error that ended the stream, if any.
+//
+// Events with an ordering key go to the worker picked by the key hash, so they are handled
+// sequentially while different keys are handled in parallel. Events without ordering key are
+// spread over every worker. Events without ordering key whose handler fails are not
+// acknowledged, the event bus delivers them again, after the retry delay when it is set. Events
+// with an ordering key are retried in place until they succeed or the consumer is shut down, so
+// a failing event holds back the later events of its key.
+func (c *Consumer) Run(ctx context.Context) error {
+	handleCtx, abort := context.WithCancel(ctx)
+	ctx, stop := context.WithCancel(ctx)
+
+	defer abort()
+	defer stop()
+
+	stopped, err := c.start(stop, abort)
+	if err != nil {
+		return err
+	}
+
+	defer close(stopped)
+
+	stream, err := c.subscriber.Stream(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "could not start consuming")
+	}
+
+	queues := make([]chan messages.Event, c.workers)
+
+	var workers sync.WaitGroup
+
+	for idx := range queues {
+		queues[idx] = make(chan messages.Event)
+
+		workers.Add(1)
+
+		go func(queue <-chan messages.Event) {
+			defer workers.Done()
+
+			for event := range queue {
+				c.handle(handleCtx, event)
+			}
+		}(queues[idx])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+
+		workers.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-stream:
+			if !ok {
+				return errors.WithMessage(c.subscriber.Err(), "consuming stopped")
+			}
+
+			select {
+			case queues[c.worker(event)] <- event:
+			case <-ctx.Done():
+				c.reject(handleCtx, event)
+
+				return nil
+			}
+		}
+	}
+}
+
+// Shutdown stops streaming new events and waits for the handlers to finish and acknowledge the
+// events they are handling, the event received but not handed to a handler yet is rejected for
+// the event bus to deliver it again right away. When the context is done first the running
+// handlers are cancelled. The consumer can not run again once shut down.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.shutdown = true
+	stop, abort, stopped := c.stop, c.abort, c.stopped
+	c.mu.Unlock()
+
+	if stopped == nil {
+		return nil
+	}
+
+	stop()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		abort()
+
+		return errors.Wrap(ctx.Err(), "could not wait for the handlers to finish")
+	}
+}
+
+// start registers the running consumer, it returns the channel to close once it stops.
+func (c *Consumer) start(stop, abort context.CancelFunc) (chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shutdown {
+		return nil, ErrConsumerShutdown
+	}
+
+	c.stop, c.abort, c.stopped = stop, abort, make(chan struct{})
+
+	return c.stopped, nil
+}
+
+func (c *Consumer) handle(ctx context.Context, event messages.Event) {
+	if c.heartbeat > 0 {
+		stop := c.subscriber.Heartbeat(ctx, event.Header.MessageID, c.heartbeat, 2*c.heartbeat)
+		defer stop()
+	}
+
+	for {
+		err := c.handler(ctx, event)
+		if err == nil {
+			break
+		}
+
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "consumers.Consumer.handle",
+		)
+
+		switch {
+		case event.Header.OrderingKey == "" && c.retryDelay > 0:
+			c.retry(ctx, event)
+
+			return
+		case event.Header.OrderingKey == "":
+			c.subscriber.Release(event.Header.MessageID)
+
+			return
+		case !c.wait(ctx):
+			c.reject(ctx, event)
+
+			return
+		}
+	}
+
+	err := c.subscriber.Acknowledge(ctx, event.Header.MessageID)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "consumers.Consumer.handle",
+		)
+	}
+}
+
+// wait waits before retrying an event with ordering key, it reports false when the context is
+// done first.
+func (c *Consumer) wait(ctx context.Context) bool {
+	delay := c.retryDelay
+	if delay <= 0 {
+		delay = DefaultOrderedRetryDelay
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *Consumer) retry(ctx context.Context, event messages.Event) {
+	err := c.subscriber.Nack(ctx, event.Header.MessageID, c.retryDelay)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "consumers.Consumer.retry",
+		)
+	}
+}
+
+// reject gives back an event that will not be handled, its flow slot is released even when the
+// event bus fails to reject it.
+func (c *Consumer) reject(ctx context.Context, event messages.Event) {
+	err := c.subscriber.Nack(ctx, event.Header.MessageID, 0)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "consumers.Consumer.reject",
+		)
+
+		c.subscriber.Release(event.Header.MessageID)
+	}
+}
+
+// worker returns the index of the worker that handles the event.
+func (c *Consumer) worker(event messages.Event) int {
+	if event.Header.OrderingKey == "" {
+		c.next = (c.next + 1) % c.workers
+
+		return c.next
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(event.Header.OrderingKey))
+
+	return int(hash.Sum32() % uint32(c.workers))
+}
@@ -0,0 +1,435 @@
+package consumers_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunKeepsOrderPerKey(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	var events []messages.Event
+
+	for idx := 0; idx < 5; idx++ {
+		events = append(events,
+			eventFixture(fmt.Sprint("a-", idx), "account-a"),
+			eventFixture(fmt.Sprint("b-", idx), "account-b"),
+		)
+	}
+
+	eventBus := newEventBusMock(events)
+	handled := make(map[string][]string)
+
+	var (
+		mu                sync.Mutex
+		running, parallel int32
+	)
+
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriberFixture(ctx, t, eventBus),
+		Workers:    4,
+		Handler: func(_ context.Context, event messages.Event) error {
+			if atomic.AddInt32(&running, 1) > 1 {
+				atomic.StoreInt32(&parallel, 1)
+			}
+
+			defer atomic.AddInt32(&running, -1)
+
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			handled[event.Header.OrderingKey] = append(handled[event.Header.OrderingKey], event.Header.ID)
+			mu.Unlock()
+
+			return nil
+		},
+	})
+	// When
+	err := consumer.Run(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a-0", "a-1", "a-2", "a-3", "a-4"}, handled["account-a"])
+	assert.Equal(t, []string{"b-0", "b-1", "b-2", "b-3", "b-4"}, handled["account-b"])
+	assert.Len(t, eventBus.acknowledged(), 10)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&parallel))
+}
+
+func TestRunRetriesFailedEventsInOrderPerKey(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newEventBusMock([]messages.Event{
+		eventFixture("1", "account-a"), eventFixture("2", "account-a"), eventFixture("3", "account-a"),
+	})
+
+	var (
+		mu      sync.Mutex
+		handled []string
+		failed  int32
+	)
+
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriberFixture(ctx, t, eventBus),
+		Workers:    2,
+		RetryDelay: 10 * time.Millisecond,
+		Handler: func(_ context.Context, event messages.Event) error {
+			if event.Header.ID == "2" && atomic.AddInt32(&failed, 1) <= 2 {
+				return errors.New("error")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			handled = append(handled, event.Header.ID)
+
+			return nil
+		},
+	})
+	// When
+	err := consumer.Run(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, handled)
+	assert.Equal(t, []string{"message-1", "message-2", "message-3"}, eventBus.acknowledged())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&failed))
+}
+
+func TestRunDoesNotAcknowledgeFailedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newEventBusMock([]messages.Event{eventFixture("1", ""), eventFixture("2", "")})
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriberFixture(ctx, t, eventBus),
+		Workers:    2,
+		Handler: func(_ context.Context, event messages.Event) error {
+			if event.Header.ID == "1" {
+				return errors.New("error")
+			}
+
+			return nil
+		},
+	})
+	// When
+	err := consumer.Run(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"message-2"}, eventBus.acknowledged())
+}
+
+func TestRunRetriesFailedEventsAfterDelay(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newEventBusMock([]messages.Event{eventFixture("1", "")})
+	eventBus.keepOpen = true
+
+	var attempts int32
+
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriberFixture(ctx, t, eventBus),
+		RetryDelay: 10 * time.Millisecond,
+		Handler: func(_ context.Context, _ messages.Event) error {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				return errors.New("error")
+			}
+
+			cancel()
+
+			return nil
+		},
+	})
+	// When
+	err := consumer.Run(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	assert.Equal(t, []string{"message-1"}, eventBus.acknowledged())
+}
+
+func TestRunFailedEventsDoNotStallTheStream(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newEventBusMock([]messages.Event{eventFixture("1", ""), eventFixture("2", ""), eventFixture("3", "")})
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus, MaxInFlightMessages: 1})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+
+	var attempts int32
+
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriber,
+		Handler: func(_ context.Context, _ messages.Event) error {
+			atomic.AddInt32(&attempts, 1)
+
+			return errors.New("error")
+		},
+	})
+	// When
+	err := consumer.Run(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	assert.Empty(t, eventBus.acknowledged())
+}
+
+func TestShutdownDrainsEventsBeingHandled(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newEventBusMock([]messages.Event{eventFixture("1", ""), eventFixture("2", "")})
+	eventBus.keepOpen = true
+	started, release := make(chan struct{}), make(chan struct{})
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriberFixture(ctx, t, eventBus),
+		Handler: func(ctx context.Context, _ messages.Event) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+
+			<-release
+
+			return ctx.Err()
+		},
+	})
+	ran := make(chan error, 1)
+
+	go func() { ran <- consumer.Run(ctx) }()
+
+	<-started
+	// When
+	shutdown := make(chan error, 1)
+
+	go func() { shutdown <- consumer.Shutdown(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	// Then
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-ran)
+	assert.Equal(t, []string{"message-1"}, eventBus.acknowledged())
+	assert.True(t, errors.Is(consumer.Run(ctx), consumers.ErrConsumerShutdown))
+}
+
+func TestShutdownRejectsEventsNotHandedToAWorker(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := &nackEventBusMock{eventBusMock: newEventBusMock([]messages.Event{eventFixture("1", ""), eventFixture("2", "")})}
+	eventBus.keepOpen = true
+	started, release := make(chan struct{}), make(chan struct{})
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriber,
+		Handler: func(context.Context, messages.Event) error {
+			close(started)
+			<-release
+
+			return nil
+		},
+	})
+	ran := make(chan error, 1)
+
+	go func() { ran <- consumer.Run(ctx) }()
+
+	<-started
+	time.Sleep(20 * time.Millisecond)
+	// When
+	shutdown := make(chan error, 1)
+
+	go func() { shutdown <- consumer.Shutdown(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	// Then
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-ran)
+	assert.Equal(t, []string{"message-1"}, eventBus.acknowledged())
+	assert.Equal(t, []string{"message-2"}, eventBus.rejected())
+}
+
+func TestShutdownCancelsHandlersWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newEventBusMock([]messages.Event{eventFixture("1", "")})
+	eventBus.keepOpen = true
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriberFixture(ctx, t, eventBus),
+		Handler: func(ctx context.Context, _ messages.Event) error {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+
+			return ctx.Err()
+		},
+	})
+
+	go func() { _ = consumer.Run(ctx) }()
+
+	<-started
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+
+	defer shutdownCancel()
+	// When
+	err := consumer.Shutdown(shutdownCtx)
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	<-cancelled
+	assert.Empty(t, eventBus.acknowledged())
+}
+
+func subscriberFixture(ctx context.Context, t *testing.T, eventBus *eventBusMock) *subscribers.Subscriber {
+	t.Helper()
+
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+
+	err := subscriber.Subscribe(ctx, "orders-topic")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return subscriber
+}
+
+func eventFixture(id, orderingKey string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{
+			ID:          id,
+			Domain:      "loans",
+			EventType:   "orders",
+			Version:     "0.1.0",
+			Application: "core-app",
+			MessageID:   "message-" + id,
+			OrderingKey: orderingKey,
+		},
+		Data: []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
+
+// eventBusMock streams the given events and records the acknowledged ones, the stream ends
+// after the events unless keepOpen is set.
+type eventBusMock struct {
+	events   []messages.Event
+	keepOpen bool
+	mu       sync.Mutex
+	acks     []string
+}
+
+func newEventBusMock(events []messages.Event) *eventBusMock {
+	return &eventBusMock{events: events}
+}
+
+func (e *eventBusMock) Pull(_ context.Context, _ uint8) ([]messages.Event, error) {
+	return e.events, nil
+}
+
+func (e *eventBusMock) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	stream := make(chan messages.Event)
+
+	go func() {
+		defer close(stream)
+
+		for _, event := range e.events {
+			select {
+			case <-ctx.Done():
+				return
+			case stream <- event:
+			}
+		}
+
+		if e.keepOpen {
+			<-ctx.Done()
+		}
+	}()
+
+	return stream, nil
+}
+
+func (e *eventBusMock) Subscribe(_ context.Context, _ string) error {
+	return nil
+}
+
+func (e *eventBusMock) Acknowledge(_ context.Context, id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.acks = append(e.acks, id)
+
+	return nil
+}
+
+func (e *eventBusMock) acknowledged() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string(nil), e.acks...)
+}
+
+// nackEventBusMock records the rejected events.
+type nackEventBusMock struct {
+	*eventBusMock
+	nacks []string
+}
+
+func (e *nackEventBusMock) Nack(_ context.Context, id string, _ time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.nacks = append(e.nacks, id)
+
+	return nil
+}
+
+func (e *nackEventBusMock) rejected() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string(nil), e.nacks...)
+}
@@ -0,0 +1,3 @@
+// Package consumers provides a runtime that consumes events from a subscriber, hands them to
+// a handler and acknowledges them once they are handled.
+package consumers
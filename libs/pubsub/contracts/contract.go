@@ -0,0 +1,185 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FieldType json type of a field.
+type FieldType string
+
+const (
+	String  FieldType = "string"
+	Number  FieldType = "number"
+	Boolean FieldType = "boolean"
+	Object  FieldType = "object"
+	Array   FieldType = "array"
+	// Any accepts every type, the field only has to be present.
+	Any FieldType = "any"
+)
+
+// fileExtension extension of the contract files.
+const fileExtension = ".json"
+
+var (
+	errInvalidName      = errors.New("invalid name, it can not be empty nor contain path separators")
+	errInvalidFieldType = errors.New("invalid field type")
+	errInvalidPath      = errors.New("invalid field path")
+)
+
+// Contract events a consumer expects from a producer.
+type Contract struct {
+	Consumer string    `json:"consumer"`
+	Producer string    `json:"producer"`
+	Messages []Message `json:"messages"`
+}
+
+// Message an event the consumer relies on, identified by its domain, event type and version.
+type Message struct {
+	Description string `json:"description,omitempty"`
+	Domain      string `json:"domain"`
+	EventType   string `json:"eventtype"`
+	Version     string `json:"version"`
+	// Attributes header attributes the consumer reads.
+	Attributes []string `json:"attributes,omitempty"`
+	// Fields data fields the consumer reads, data must be a json object.
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// Field a data field the consumer reads. Paths are dot separated keys, [] steps into every
+// element of an array, like loan.installments[].amount.
+type Field struct {
+	Path     string    `json:"path"`
+	Type     FieldType `json:"type"`
+	Optional bool      `json:"optional,omitempty"`
+}
+
+// Name returns the message name used in violations, domain.eventtype.vversion.
+func (m Message) Name() string {
+	return m.Domain + "." + m.EventType + ".v" + m.Version
+}
+
+// Validate checks the contract can be stored and verified.
+func (c Contract) Validate() error {
+	for _, name := range []string{c.Consumer, c.Producer} {
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return errors.WithMessagef(errInvalidName, "%q", name)
+		}
+	}
+
+	for _, message := range c.Messages {
+		for _, field := range message.Fields {
+			if _, err := parsePath(field.Path); err != nil {
+				return errors.WithMessagef(err, "message %s", message.Name())
+			}
+
+			switch field.Type {
+			case String, Number, Boolean, Object, Array, Any:
+			default:
+				return errors.WithMessagef(errInvalidFieldType, "%q of %s in message %s", field.Type, field.Path, message.Name())
+			}
+		}
+	}
+
+	return nil
+}
+
+// Path returns where the contract is stored in the contracts directory,
+// <dir>/<producer>/<consumer>.json.
+func Path(dir string, contract Contract) string {
+	return filepath.Join(dir, contract.Producer, contract.Consumer+fileExtension)
+}
+
+// Save writes the contract into its file of the contracts directory.
+func Save(dir string, contract Contract) error {
+	err := contract.Validate()
+	if err != nil {
+		return err
+	}
+
+	content, err := Marshal(contract)
+	if err != nil {
+		return err
+	}
+
+	path := Path(dir, contract)
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return errors.Wrap(err, "could not create contracts directory")
+	}
+
+	return errors.Wrap(os.WriteFile(path, content, 0o600), "could not write contract")
+}
+
+// Marshal returns the contract as it is stored, indented json.
+func Marshal(contract Contract) ([]byte, error) {
+	content, err := json.MarshalIndent(contract, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode contract")
+	}
+
+	return append(content, '\n'), nil
+}
+
+// Load reads a contract file.
+func Load(path string) (Contract, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Contract{}, errors.Wrap(err, "could not read contract")
+	}
+
+	var contract Contract
+
+	err = json.Unmarshal(content, &contract)
+	if err != nil {
+		return Contract{}, errors.Wrapf(err, "invalid contract %s", path)
+	}
+
+	err = contract.Validate()
+	if err != nil {
+		return Contract{}, errors.WithMessagef(err, "invalid contract %s", path)
+	}
+
+	return contract, nil
+}
+
+// LoadProducer reads every contract of the producer from the contracts directory, sorted by
+// consumer. A producer without consumers has no contracts.
+func LoadProducer(dir, producer string) ([]Contract, error) {
+	var result []Contract
+
+	root := filepath.Join(dir, producer)
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == root {
+			return filepath.SkipDir
+		}
+
+		if err != nil || entry.IsDir() || filepath.Ext(path) != fileExtension {
+			return err
+		}
+
+		contract, err := Load(path)
+		if err != nil {
+			return err
+		}
+
+		result = append(result, contract)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "could not load contracts of %q", producer)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Consumer < result[j].Consumer })
+
+	return result, nil
+}
@@ -0,0 +1,217 @@
+package contracts_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/contracts"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/contracts/contractstest"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+// contractsDir is the contracts folder of the repository, the one services keep theirs in.
+const contractsDir = "../../../contracts"
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		data               string
+		attributes         map[string]string
+		version            string
+		expectedViolations []string
+	}{
+		"honoured": {
+			data:       `{"loan":{"id":"1","amount":10,"installments":[{"amount":5},{"amount":5}]},"owner":"ana"}`,
+			attributes: map[string]string{"region": "eu-west"},
+		},
+		"missing field and attribute": {
+			data: `{"loan":{"id":"1","installments":[]},"owner":"ana"}`,
+			expectedViolations: []string{
+				"audit expects loans.created.v2 attributes.region: missing",
+				"audit expects loans.created.v2 loan.amount: missing",
+			},
+		},
+		"wrong types": {
+			data:       `{"loan":{"id":1,"amount":"10","installments":[{"amount":5},{"amount":"5"}]},"owner":null}`,
+			attributes: map[string]string{"region": "eu-west"},
+			expectedViolations: []string{
+				"audit expects loans.created.v2 loan.id: expected string, got number",
+				"audit expects loans.created.v2 loan.amount: expected number, got string",
+				"audit expects loans.created.v2 owner: missing",
+				"analytics expects loans.created.v2 loan.installments[].amount: loan.installments[1].amount: expected number, got string",
+			},
+		},
+		"data is not json": {
+			data:       `not json`,
+			attributes: map[string]string{"region": "eu-west"},
+			expectedViolations: []string{
+				"audit expects loans.created.v2 data: not json: invalid character 'o' in literal null (expecting 'u')",
+				"analytics expects loans.created.v2 data: not json: invalid character 'o' in literal null (expecting 'u')",
+			},
+		},
+		"version no longer produced": {
+			data:    `{}`,
+			version: "3",
+			expectedViolations: []string{
+				"audit expects loans.created.v2: no event of this domain, event type and version was produced",
+				"analytics expects loans.created.v2: no event of this domain, event type and version was produced",
+			},
+		},
+	}
+
+	all, err := contracts.LoadProducer(contractsDir, "credit")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			event := loanCreatedFixture(test.data)
+			event.Header.Attributes = test.attributes
+
+			if test.version != "" {
+				event.Header.Version = test.version
+			}
+
+			var violations []string
+			// When
+			for _, contract := range []contracts.Contract{all[1], all[0]} {
+				for _, violation := range contracts.Verify(contract, event) {
+					violations = append(violations, violation.String())
+				}
+			}
+			// Then
+			assert.Equal(t, test.expectedViolations, violations)
+		})
+	}
+}
+
+func TestProducerHonoursContracts(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	event := loanCreatedFixture(`{"loan":{"id":"1","amount":10,"installments":[{"amount":10}]},"owner":"ana"}`)
+	event.Header = event.Header.WithAttribute("region", "eu-west")
+	// Then
+	contractstest.AssertVerified(t, contractsDir, "credit", event)
+}
+
+func TestConsumerContractIsUpToDate(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	contract := contracts.Contract{
+		Consumer: "audit",
+		Producer: "credit",
+		Messages: []contracts.Message{
+			{
+				Description: "every created loan is recorded with its amount and owner",
+				Domain:      "loans",
+				EventType:   "created",
+				Version:     "2",
+				Attributes:  []string{"region"},
+				Fields: []contracts.Field{
+					{Path: "loan.id", Type: contracts.String},
+					{Path: "loan.amount", Type: contracts.Number},
+					{Path: "owner", Type: contracts.String},
+				},
+			},
+		},
+	}
+	// Then
+	contractstest.AssertContract(t, contractsDir, contract)
+}
+
+func TestExampleHonoursItsContract(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	all, err := contracts.LoadProducer(contractsDir, "credit")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, contract := range all {
+		for _, message := range contract.Messages {
+			// When
+			example := message.Example()
+			// Then
+			assert.Empty(t, contracts.Verify(contract, example), "example of %s", message.Name())
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	dir := t.TempDir()
+	contract := contracts.Contract{
+		Consumer: "exporter",
+		Producer: "credit",
+		Messages: []contracts.Message{{Domain: "loans", EventType: "paid", Version: "1"}},
+	}
+	// When
+	err := contracts.Save(dir, contract)
+	loaded, loadErr := contracts.Load(filepath.Join(dir, "credit", "exporter.json"))
+	missing, missingErr := contracts.LoadProducer(dir, "payments")
+	// Then
+	assert.NoError(t, err)
+	assert.NoError(t, loadErr)
+	assert.NoError(t, missingErr)
+	assert.Equal(t, contract, loaded)
+	assert.Empty(t, missing)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		contract      contracts.Contract
+		expectedError string
+	}{
+		"consumer with separators": {
+			contract:      contracts.Contract{Consumer: "../audit", Producer: "credit"},
+			expectedError: `"../audit": invalid name, it can not be empty nor contain path separators`,
+		},
+		"invalid path": {
+			contract: contracts.Contract{Consumer: "audit", Producer: "credit", Messages: []contracts.Message{
+				{Domain: "loans", EventType: "created", Version: "1", Fields: []contracts.Field{{Path: "loan..id", Type: contracts.String}}},
+			}},
+			expectedError: `message loans.created.v1: "loan..id": invalid field path`,
+		},
+		"invalid type": {
+			contract: contracts.Contract{Consumer: "audit", Producer: "credit", Messages: []contracts.Message{
+				{Domain: "loans", EventType: "created", Version: "1", Fields: []contracts.Field{{Path: "loan", Type: "map"}}},
+			}},
+			expectedError: `"map" of loan in message loans.created.v1: invalid field type`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			err := test.contract.Validate()
+			// Then
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
+
+func loanCreatedFixture(data string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{ID: "1", Domain: "loans", EventType: "created", Version: "2"},
+		Data:   []byte(data),
+	}
+}
@@ -0,0 +1,67 @@
+// Package contractstest provides the test helpers consumers use to keep their contracts in
+// the repository and producers use to verify them.
+package contractstest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/contracts"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/stretchr/testify/assert"
+)
+
+// UpdateEnv environment variable that rewrites the contract files with the contracts declared
+// by the consumer tests when it is set to 1.
+const UpdateEnv = "UPDATE_CONTRACTS"
+
+// AssertContract checks the contract file of the contracts directory matches the contract the
+// consumer declares, so changing what a consumer relies on updates the file producers verify.
+func AssertContract(t *testing.T, dir string, contract contracts.Contract) {
+	t.Helper()
+
+	got, err := contracts.Marshal(contract)
+	if err != nil {
+		t.Fatalf("could not encode contract: %s", err)
+	}
+
+	path := contracts.Path(dir, contract)
+
+	if os.Getenv(UpdateEnv) == "1" {
+		err = contracts.Save(dir, contract)
+		if err != nil {
+			t.Fatalf("could not update %s: %s", path, err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s, run with %s=1 to create it: %s", path, UpdateEnv, err)
+	}
+
+	assert.JSONEq(t, string(expected), string(got), "contract %s is outdated, run with %s=1 to update it", path, UpdateEnv)
+}
+
+// AssertVerified checks the events the producer publishes honour every contract its consumers
+// keep in the contracts directory.
+func AssertVerified(t *testing.T, dir, producer string, events ...messages.Event) {
+	t.Helper()
+
+	all, err := contracts.LoadProducer(dir, producer)
+	if err != nil {
+		t.Fatalf("could not load contracts: %s", err)
+	}
+
+	var violations []string
+
+	for _, contract := range all {
+		for _, violation := range contracts.Verify(contract, events...) {
+			violations = append(violations, violation.String())
+		}
+	}
+
+	if len(violations) > 0 {
+		t.Errorf("%s breaks the contracts of its consumers:\n  %s", producer, strings.Join(violations, "\n  "))
+	}
+}
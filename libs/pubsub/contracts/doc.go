@@ -0,0 +1,4 @@
+// Package contracts provides consumer driven contracts for events. Consumers declare the
+// events and fields they rely on, contracts are kept as json files in the repository and
+// producers verify the events they publish still honour every contract of their consumers.
+package contracts
@@ -0,0 +1,94 @@
+package contracts
+
+import (
+	"encoding/json"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+)
+
+// Example returns an event with every attribute and field of the message, so consumers can
+// check their handlers work with no more than what the contract asks for. Optional fields are
+// left out.
+func (m Message) Example() messages.Event {
+	event := messages.Event{
+		Header: messages.Header{
+			ID:        "example",
+			Domain:    m.Domain,
+			EventType: m.EventType,
+			Version:   m.Version,
+		},
+	}
+
+	for _, key := range m.Attributes {
+		event.Header = event.Header.WithAttribute(key, "example")
+	}
+
+	if len(m.Fields) == 0 {
+		return event
+	}
+
+	data := make(map[string]interface{})
+
+	for _, field := range m.Fields {
+		if field.Optional {
+			continue
+		}
+
+		steps, err := parsePath(field.Path)
+		if err != nil {
+			continue
+		}
+
+		data = place(data, steps, exampleOf(field.Type)).(map[string]interface{})
+	}
+
+	event.Data, _ = json.Marshal(data)
+
+	return event
+}
+
+// place sets the value at the path steps of the container, arrays get a single element.
+func place(container interface{}, steps []string, value interface{}) interface{} {
+	if len(steps) == 0 {
+		if container != nil {
+			return container
+		}
+
+		return value
+	}
+
+	if steps[0] == arrayStep {
+		elements, _ := container.([]interface{})
+		if len(elements) == 0 {
+			elements = []interface{}{nil}
+		}
+
+		elements[0] = place(elements[0], steps[1:], value)
+
+		return elements
+	}
+
+	object, ok := container.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+
+	object[steps[0]] = place(object[steps[0]], steps[1:], value)
+
+	return object
+}
+
+func exampleOf(fieldType FieldType) interface{} {
+	switch fieldType {
+	case Number:
+		return 1
+	case Boolean:
+		return true
+	case Object:
+		return map[string]interface{}{}
+	case Array:
+		return []interface{}{}
+	default:
+		return "example"
+	}
+}
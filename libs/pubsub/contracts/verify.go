@@ -0,0 +1,210 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+// arrayStep path step going into every element of an array.
+const arrayStep = "[]"
+
+// Violation a broken expectation of a contract.
+type Violation struct {
+	Consumer string
+	Message  string
+	// Path of the field or attributes.<key>, empty when no event of the message was given.
+	Path    string
+	Problem string
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return fmt.Sprintf("%s expects %s: %s", v.Consumer, v.Message, v.Problem)
+	}
+
+	return fmt.Sprintf("%s expects %s %s: %s", v.Consumer, v.Message, v.Path, v.Problem)
+}
+
+// Verify checks the events a producer publishes honour the contract, every message of the
+// contract needs at least one event with its domain, event type and version and every event
+// of it must have the expected attributes and fields.
+func Verify(contract Contract, events ...messages.Event) []Violation {
+	var violations []Violation
+
+	for _, message := range contract.Messages {
+		var found bool
+
+		for _, event := range events {
+			header := event.Header
+			if header.Domain != message.Domain || header.EventType != message.EventType || header.Version != message.Version {
+				continue
+			}
+
+			found = true
+
+			for _, problem := range check(message, event) {
+				problem.Consumer, problem.Message = contract.Consumer, message.Name()
+				violations = append(violations, problem)
+			}
+		}
+
+		if !found {
+			violations = append(violations, Violation{
+				Consumer: contract.Consumer,
+				Message:  message.Name(),
+				Problem:  "no event of this domain, event type and version was produced",
+			})
+		}
+	}
+
+	return violations
+}
+
+// check returns the problems of the event, without consumer nor message.
+func check(message Message, event messages.Event) []Violation {
+	var problems []Violation
+
+	for _, key := range message.Attributes {
+		if _, ok := event.Header.Attributes[key]; !ok {
+			problems = append(problems, Violation{Path: "attributes." + key, Problem: "missing"})
+		}
+	}
+
+	if len(message.Fields) == 0 {
+		return problems
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(event.Data))
+	decoder.UseNumber()
+
+	var data interface{}
+
+	err := decoder.Decode(&data)
+	if err != nil {
+		return append(problems, Violation{Path: "data", Problem: "not json: " + err.Error()})
+	}
+
+	for _, field := range message.Fields {
+		steps, _ := parsePath(field.Path)
+
+		for _, problem := range lookup(data, steps, "", field) {
+			problems = append(problems, Violation{Path: field.Path, Problem: problem})
+		}
+	}
+
+	return problems
+}
+
+// lookup follows the path steps into the value and returns the problems found, at is the
+// path walked so far.
+func lookup(value interface{}, steps []string, at string, field Field) []string {
+	if len(steps) == 0 {
+		if !typeOf(value, field.Type) {
+			return []string{fmt.Sprintf("%sexpected %s, got %s", prefix(at), field.Type, jsonType(value))}
+		}
+
+		return nil
+	}
+
+	step := steps[0]
+
+	if step == arrayStep {
+		elements, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%sexpected array, got %s", prefix(at), jsonType(value))}
+		}
+
+		var problems []string
+
+		for idx, element := range elements {
+			problems = append(problems, lookup(element, steps[1:], fmt.Sprintf("%s[%d]", at, idx), field)...)
+		}
+
+		return problems
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%sexpected object, got %s", prefix(at), jsonType(value))}
+	}
+
+	child, ok := object[step]
+	if !ok || child == nil {
+		if field.Optional {
+			return nil
+		}
+
+		return []string{prefix(join(at, step)) + "missing"}
+	}
+
+	return lookup(child, steps[1:], join(at, step), field)
+}
+
+func typeOf(value interface{}, fieldType FieldType) bool {
+	return fieldType == Any || jsonType(value) == fieldType
+}
+
+func jsonType(value interface{}) FieldType {
+	switch value.(type) {
+	case string:
+		return String
+	case json.Number:
+		return Number
+	case bool:
+		return Boolean
+	case map[string]interface{}:
+		return Object
+	case []interface{}:
+		return Array
+	default:
+		return "null"
+	}
+}
+
+// parsePath splits a path like loan.installments[].amount into its steps.
+func parsePath(path string) ([]string, error) {
+	if path == "" {
+		return nil, errors.WithMessagef(errInvalidPath, "%q", path)
+	}
+
+	var steps []string
+
+	for _, segment := range strings.Split(path, ".") {
+		key := strings.TrimRight(segment, arrayStep)
+		arrays := (len(segment) - len(key)) / len(arrayStep)
+
+		if key == "" || key+strings.Repeat(arrayStep, arrays) != segment || strings.ContainsAny(key, "[]") {
+			return nil, errors.WithMessagef(errInvalidPath, "%q", path)
+		}
+
+		steps = append(steps, key)
+
+		for idx := 0; idx < arrays; idx++ {
+			steps = append(steps, arrayStep)
+		}
+	}
+
+	return steps, nil
+}
+
+func join(at, key string) string {
+	if at == "" {
+		return key
+	}
+
+	return at + "." + key
+}
+
+// prefix returns the walked path as prefix of a problem when it is not the field path itself.
+func prefix(at string) string {
+	if strings.Contains(at, "[") {
+		return at + ": "
+	}
+
+	return ""
+}
@@ -0,0 +1,7 @@
+// Package encryption provides envelope encryption of event data with AES-GCM.
+//
+// Every event is encrypted with a fresh data key, the data key is encrypted by a key provider
+// master key and travels in the event header together with the master key id. Consumers ask
+// the key provider to decrypt the data key, so master keys can rotate without downtime as
+// long as the provider keeps the old keys for decrypting.
+package encryption
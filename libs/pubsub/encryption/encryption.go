@@ -0,0 +1,113 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AlgorithmAttribute header attribute with the algorithm the data is encrypted with.
+	AlgorithmAttribute = "encryption"
+	// KeyIDAttribute header attribute with the id of the master key that encrypted the data key.
+	KeyIDAttribute = "encryptionkeyid"
+	// DataKeyAttribute header attribute with the base64 encoded encrypted data key.
+	DataKeyAttribute = "encrypteddatakey"
+
+	algorithmAESGCM = "aes256gcm"
+)
+
+var (
+	errUnsupportedAlgorithm = errors.New("unsupported encryption algorithm")
+	errMissingDataKey       = errors.New("encrypted event has no data key")
+	errAlreadyEncrypted     = errors.New("event is already encrypted")
+)
+
+// Encrypt encrypts the event data with a new data key and records the encrypted data key and
+// its master key id in the header. The event id is authenticated along with the data.
+func Encrypt(ctx context.Context, keys KeyProvider, event messages.Event) (messages.Event, error) {
+	if IsEncrypted(event) {
+		return messages.Event{}, errAlreadyEncrypted
+	}
+
+	dataKey, err := keys.GenerateDataKey(ctx)
+	if err != nil {
+		return messages.Event{}, errors.WithMessage(err, "could not get a data key")
+	}
+
+	ciphertext, err := seal(dataKey.Plaintext, event.Data, []byte(event.Header.ID))
+	if err != nil {
+		return messages.Event{}, errors.WithMessagef(err, "could not encrypt event %q", event.Header.ID)
+	}
+
+	event.Header = event.Header.
+		WithAttribute(AlgorithmAttribute, algorithmAESGCM).
+		WithAttribute(KeyIDAttribute, dataKey.KeyID).
+		WithAttribute(DataKeyAttribute, base64.StdEncoding.EncodeToString(dataKey.Ciphertext))
+	event.Data = ciphertext
+
+	return event, nil
+}
+
+// Decrypt decrypts an event encrypted by Encrypt and removes the encryption attributes,
+// events that are not encrypted are returned as they are.
+func Decrypt(ctx context.Context, keys KeyProvider, event messages.Event) (messages.Event, error) {
+	if !IsEncrypted(event) {
+		return event, nil
+	}
+
+	algorithm := event.Header.Attribute(AlgorithmAttribute)
+	if algorithm != algorithmAESGCM {
+		return messages.Event{}, errors.WithMessagef(errUnsupportedAlgorithm, "%q", algorithm)
+	}
+
+	encryptedKey, err := base64.StdEncoding.DecodeString(event.Header.Attribute(DataKeyAttribute))
+	if err != nil || len(encryptedKey) == 0 {
+		return messages.Event{}, errMissingDataKey
+	}
+
+	dataKey, err := keys.DecryptDataKey(ctx, event.Header.Attribute(KeyIDAttribute), encryptedKey)
+	if err != nil {
+		return messages.Event{}, errors.WithMessagef(err, "could not decrypt data key of %q", event.Header.ID)
+	}
+
+	plaintext, err := open(dataKey, event.Data, []byte(event.Header.ID))
+	if err != nil {
+		return messages.Event{}, errors.WithMessagef(err, "could not decrypt event %q", event.Header.ID)
+	}
+
+	event.Header = event.Header.
+		WithoutAttribute(AlgorithmAttribute).
+		WithoutAttribute(KeyIDAttribute).
+		WithoutAttribute(DataKeyAttribute)
+	event.Data = plaintext
+
+	return event, nil
+}
+
+// IsEncrypted reports whether the event data is encrypted.
+func IsEncrypted(event messages.Event) bool {
+	return event.Header.Attribute(AlgorithmAttribute) != ""
+}
+
+// Publisher returns a publisher middleware that encrypts every event.
+func Publisher(keys KeyProvider) publishers.Middleware {
+	return publishers.Transform(
+		func(ctx context.Context, _ string, event messages.Event) (messages.Event, error) {
+			return Encrypt(ctx, keys, event)
+		},
+	)
+}
+
+// Subscriber returns a subscriber middleware that decrypts every encrypted event.
+func Subscriber(keys KeyProvider) subscribers.Middleware {
+	return subscribers.Transform(
+		func(ctx context.Context, event messages.Event) (messages.Event, error) {
+			return Decrypt(ctx, keys, event)
+		},
+	)
+}
@@ -0,0 +1,175 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/encryption"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptThroughMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	expectedEvent := eventMessageFixture()
+	keyring := newKeyring(t)
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, encryption.Publisher(keyring)))
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, encryption.Subscriber(keyring)),
+		MessagesPerPull: 1,
+	})
+	// When
+	err := publisher.Publish(ctx, publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []messages.Event{expectedEvent}, got)
+	assert.NotContains(t, string(eventBus.events[0].Data), "value_one")
+	assert.Equal(t, "key-1", eventBus.events[0].Header.Attribute(encryption.KeyIDAttribute))
+}
+
+func TestDecryptAfterKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	expectedEvent := eventMessageFixture()
+	keyring := newKeyring(t)
+
+	encrypted, err := encryption.Encrypt(ctx, keyring, expectedEvent)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// When
+	err = keyring.Rotate("key-2", bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rotated, err := encryption.Encrypt(ctx, keyring, expectedEvent)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := encryption.Decrypt(ctx, keyring, encrypted)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+	assert.Equal(t, "key-2", rotated.Header.Attribute(encryption.KeyIDAttribute))
+}
+
+func TestDecryptTamperedEvent(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	keyring := newKeyring(t)
+
+	encrypted, err := encryption.Encrypt(ctx, keyring, eventMessageFixture())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	encrypted.Header.ID = "another-id"
+	// When
+	_, err = encryption.Decrypt(ctx, keyring, encrypted)
+	// Then
+	assert.EqualError(t, err, `could not decrypt event "another-id": could not decrypt: `+
+		"cipher: message authentication failed")
+}
+
+func TestDecryptWithRemovedKey(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	keyring := newKeyring(t)
+
+	encrypted, err := encryption.Encrypt(ctx, keyring, eventMessageFixture())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_ = keyring.Rotate("key-2", bytes.Repeat([]byte{2}, 32))
+	_ = keyring.Remove("key-1")
+	// When
+	_, err = encryption.Decrypt(ctx, keyring, encrypted)
+	// Then
+	assert.EqualError(t, err, `could not decrypt data key of "123-456-789": "key-1": unknown master key`)
+}
+
+func TestDecryptPlainEvent(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := eventMessageFixture()
+	// When
+	got, err := encryption.Decrypt(context.TODO(), newKeyring(t), expectedEvent)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, got)
+}
+
+func newKeyring(t *testing.T) *encryption.Keyring {
+	t.Helper()
+
+	keyring, err := encryption.NewKeyring("key-1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return keyring
+}
+
+func eventMessageFixture() messages.Event {
+	header := messages.Header{
+		ID:          "123-456-789",
+		Domain:      "loans",
+		EventType:   "orders",
+		Version:     "0.1.0",
+		Application: "core-app",
+	}
+
+	return messages.Event{
+		Header: header,
+		Data:   []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
+
+// eventBusMock delivers published events to its subscribers.
+type eventBusMock struct {
+	events []messages.Event
+}
+
+func (e *eventBusMock) Publish(_ context.Context, _ string, message interface{}) error {
+	e.events = append(e.events, message.(messages.Event))
+
+	return nil
+}
+
+func (e *eventBusMock) Pull(_ context.Context, _ uint8) ([]messages.Event, error) {
+	return e.events, nil
+}
+
+func (e *eventBusMock) Stream(_ context.Context) (<-chan messages.Event, error) {
+	return nil, nil
+}
+
+func (e *eventBusMock) Subscribe(_ context.Context, _ string) error {
+	return nil
+}
+
+func (e *eventBusMock) Acknowledge(_ context.Context, _ string) error {
+	return nil
+}
@@ -0,0 +1,184 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const dataKeySize = 32 // AES-256
+
+var (
+	errUnknownKey    = errors.New("unknown master key")
+	errNoCurrentKey  = errors.New("keyring has no current master key")
+	errInvalidKey    = errors.New("master keys must be 32 bytes long")
+	errRemoveCurrent = errors.New("cannot remove the current master key")
+	errShortMessage  = errors.New("ciphertext is too short")
+)
+
+// DataKey is a key used to encrypt the data of a single event.
+type DataKey struct {
+	// KeyID id of the master key that encrypted the data key.
+	KeyID string
+	// Plaintext the data key, it must never leave the process.
+	Plaintext []byte
+	// Ciphertext the data key encrypted by the master key.
+	Ciphertext []byte
+}
+
+// KeyProvider generates and decrypts data keys, it follows the shape of a KMS so cloud key
+// management services can be plugged in.
+type KeyProvider interface {
+	// GenerateDataKey returns a new data key encrypted under the current master key.
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+	// DecryptDataKey decrypts a data key encrypted under the given master key.
+	DecryptDataKey(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// Keyring is a local key provider holding the master keys in memory, meant for tests and
+// local environments.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+// NewKeyring instances a new keyring using the given master key as the current one.
+func NewKeyring(keyID string, masterKey []byte) (*Keyring, error) {
+	newKeyring := Keyring{
+		keys: make(map[string][]byte),
+	}
+
+	err := newKeyring.Rotate(keyID, masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newKeyring, nil
+}
+
+// Rotate adds a master key and makes it the current one, previous keys are kept to decrypt
+// events already published with them.
+func (k *Keyring) Rotate(keyID string, masterKey []byte) error {
+	if len(masterKey) != dataKeySize {
+		return errInvalidKey
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[keyID] = append([]byte(nil), masterKey...)
+	k.current = keyID
+
+	return nil
+}
+
+// Remove drops a retired master key, events encrypted with it cannot be decrypted anymore.
+func (k *Keyring) Remove(keyID string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if keyID == k.current {
+		return errRemoveCurrent
+	}
+
+	delete(k.keys, keyID)
+
+	return nil
+}
+
+// GenerateDataKey returns a new random data key encrypted under the current master key.
+func (k *Keyring) GenerateDataKey(_ context.Context) (DataKey, error) {
+	k.mu.RLock()
+	keyID, masterKey := k.current, k.keys[k.current]
+	k.mu.RUnlock()
+
+	if masterKey == nil {
+		return DataKey{}, errNoCurrentKey
+	}
+
+	plaintext := make([]byte, dataKeySize)
+
+	_, err := io.ReadFull(rand.Reader, plaintext)
+	if err != nil {
+		return DataKey{}, errors.Wrap(err, "could not generate data key")
+	}
+
+	ciphertext, err := seal(masterKey, plaintext, []byte(keyID))
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	return DataKey{
+		KeyID:      keyID,
+		Plaintext:  plaintext,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// DecryptDataKey decrypts a data key encrypted under the given master key.
+func (k *Keyring) DecryptDataKey(_ context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	k.mu.RLock()
+	masterKey := k.keys[keyID]
+	k.mu.RUnlock()
+
+	if masterKey == nil {
+		return nil, errors.WithMessagef(errUnknownKey, "%q", keyID)
+	}
+
+	return open(masterKey, ciphertext, []byte(keyID))
+}
+
+// seal encrypts the plaintext with AES-GCM, the random nonce is prepended to the result.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate nonce")
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a ciphertext produced by seal.
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errShortMessage
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt")
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid key")
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	return aead, errors.Wrap(err, "could not create aes-gcm cipher")
+}
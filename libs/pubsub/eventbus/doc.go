@@ -0,0 +1,4 @@
+// Package eventbus opens event bus publishers and subscribers from urls, like memory://orders
+// or file:///tmp/orders.ndjson, the url scheme picks the adapter and the rest of the url
+// configures it.
+package eventbus
@@ -0,0 +1,115 @@
+package eventbus
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedScheme is returned when no adapter is registered for the url scheme.
+var ErrUnsupportedScheme = errors.New("unsupported event bus scheme")
+
+// PublisherOpener opens the publisher of the event bus the url points to.
+type PublisherOpener func(ctx context.Context, busURL *url.URL) (publishers.EventBusPublisher, error)
+
+// SubscriberOpener opens a subscriber of the event bus the url points to.
+type SubscriberOpener func(ctx context.Context, busURL *url.URL) (subscribers.EventBusSubscriber, error)
+
+var (
+	mu                sync.RWMutex
+	publisherOpeners  = make(map[string]PublisherOpener)
+	subscriberOpeners = make(map[string]SubscriberOpener)
+)
+
+// RegisterPublisher registers the publisher opener of the url scheme, it replaces the one
+// already registered.
+func RegisterPublisher(scheme string, opener PublisherOpener) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	publisherOpeners[scheme] = opener
+}
+
+// RegisterSubscriber registers the subscriber opener of the url scheme, it replaces the one
+// already registered.
+func RegisterSubscriber(scheme string, opener SubscriberOpener) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	subscriberOpeners[scheme] = opener
+}
+
+// OpenPublisher opens the publisher of the event bus url.
+func OpenPublisher(ctx context.Context, rawURL string) (publishers.EventBusPublisher, error) {
+	busURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid event bus url")
+	}
+
+	mu.RLock()
+	opener, ok := publisherOpeners[busURL.Scheme]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, errors.WithMessagef(ErrUnsupportedScheme, "%q", busURL.Scheme)
+	}
+
+	publisher, err := opener(ctx, busURL)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "could not open %s publisher", busURL.Scheme)
+	}
+
+	return publisher, nil
+}
+
+// OpenSubscriber opens a subscriber of the event bus url.
+func OpenSubscriber(ctx context.Context, rawURL string) (subscribers.EventBusSubscriber, error) {
+	busURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid event bus url")
+	}
+
+	mu.RLock()
+	opener, ok := subscriberOpeners[busURL.Scheme]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, errors.WithMessagef(ErrUnsupportedScheme, "%q", busURL.Scheme)
+	}
+
+	subscriber, err := opener(ctx, busURL)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "could not open %s subscriber", busURL.Scheme)
+	}
+
+	return subscriber, nil
+}
+
+// Schemes returns the url schemes with a registered publisher or subscriber.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	found := make(map[string]bool)
+	for scheme := range publisherOpeners {
+		found[scheme] = true
+	}
+
+	for scheme := range subscriberOpeners {
+		found[scheme] = true
+	}
+
+	result := make([]string, 0, len(found))
+	for scheme := range found {
+		result = append(result, scheme)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
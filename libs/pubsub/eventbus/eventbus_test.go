@@ -0,0 +1,70 @@
+package eventbus_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMemoryBusByName(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	publisher, errPublisher := eventbus.OpenPublisher(ctx, "memory://eventbus-test")
+	subscriber, errSubscriber := eventbus.OpenSubscriber(ctx, "memory://eventbus-test")
+	other, _ := eventbus.OpenSubscriber(ctx, "memory://eventbus-other")
+	_ = subscriber.Subscribe(ctx, "orders")
+	_ = other.Subscribe(ctx, "orders")
+	// When
+	_ = publisher.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: "1"}})
+	got, _ := subscriber.Pull(ctx, 0)
+	otherGot, _ := other.Pull(ctx, 0)
+	// Then
+	assert.NoError(t, errPublisher)
+	assert.NoError(t, errSubscriber)
+	assert.Len(t, got, 1)
+	assert.Empty(t, otherGot)
+	assert.Contains(t, eventbus.Schemes(), eventbus.MemoryScheme)
+}
+
+func TestOpenFileBusByPath(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	busURL := "file://" + filepath.ToSlash(filepath.Join(t.TempDir(), "bus.ndjson"))
+	publisher, errPublisher := eventbus.OpenPublisher(ctx, busURL)
+	_ = publisher.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: "1"}})
+	first, errSubscriber := eventbus.OpenSubscriber(ctx, busURL)
+	_ = first.Subscribe(ctx, "orders")
+	acknowledged, _ := first.Pull(ctx, 0)
+	_ = first.Acknowledge(ctx, acknowledged[0].Header.MessageID)
+	// When
+	_ = publisher.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: "2"}})
+	second, _ := eventbus.OpenSubscriber(ctx, busURL)
+	_ = second.Subscribe(ctx, "orders")
+	got, _ := second.Pull(ctx, 0)
+	// Then
+	assert.NoError(t, errPublisher)
+	assert.NoError(t, errSubscriber)
+	assert.Len(t, got, 1)
+	assert.Equal(t, "2", got[0].Header.ID)
+	assert.Contains(t, eventbus.Schemes(), eventbus.FileScheme)
+}
+
+func TestOpenUnsupportedScheme(t *testing.T) {
+	t.Parallel()
+
+	// When
+	_, errPublisher := eventbus.OpenPublisher(context.TODO(), "carrier-pigeon://orders")
+	_, errSubscriber := eventbus.OpenSubscriber(context.TODO(), "carrier-pigeon://orders")
+	// Then
+	assert.True(t, errors.Is(errPublisher, eventbus.ErrUnsupportedScheme))
+	assert.True(t, errors.Is(errSubscriber, eventbus.ErrUnsupportedScheme))
+}
@@ -0,0 +1,290 @@
+package eventbustest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// ordering checks events with the same ordering key arrive in publishing order.
+func (s suite) ordering(t *testing.T) {
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	events := eventsFixture(10)
+
+	s.publish(ctx, t, channel, events...)
+
+	received := s.receive(ctx, t, subscriber, len(events))
+	assert.Equal(t, ids(events), ids(received))
+	s.acknowledgeAll(ctx, t, subscriber, received)
+}
+
+// headerRoundTrip checks every header field and binary data arrive as published, and the
+// event bus sets the message id and channel.
+func (s suite) headerRoundTrip(t *testing.T) {
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	event := eventFixture("1")
+
+	s.publish(ctx, t, channel, event)
+
+	received := s.receive(ctx, t, subscriber, 1)
+	header := received[0].Header
+	assert.NotEmpty(t, header.MessageID)
+	assert.Equal(t, channel, header.Channel)
+
+	roundTripped := received[0]
+	roundTripped.Header.MessageID, roundTripped.Header.Channel = "", ""
+	assert.Equal(t, event, roundTripped)
+	s.acknowledgeAll(ctx, t, subscriber, received)
+}
+
+// pullBatchSize checks Pull never returns more events than asked for and no event is lost
+// or duplicated across pulls.
+func (s suite) pullBatchSize(t *testing.T) {
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	events := eventsFixture(5)
+
+	s.publish(ctx, t, channel, events...)
+
+	var received []messages.Event
+
+	deadline := time.Now().Add(s.Timeout)
+
+	for len(received) < len(events) && time.Now().Before(deadline) {
+		pulled, err := subscriber.Pull(ctx, 2)
+		if err != nil {
+			t.Fatalf("unexpected error pulling events: %s", err)
+		}
+
+		assert.LessOrEqual(t, len(pulled), 2)
+
+		if len(pulled) == 0 {
+			time.Sleep(pollInterval)
+		}
+
+		s.acknowledgeAll(ctx, t, subscriber, pulled)
+		received = append(received, pulled...)
+	}
+
+	assert.ElementsMatch(t, ids(events), ids(received))
+}
+
+// acknowledge checks acknowledged events are not delivered again, so it needs an event bus
+// redelivering the unacknowledged ones.
+func (s suite) acknowledge(t *testing.T) {
+	if !s.features.Redelivery {
+		t.Skip("event bus does not redeliver unacknowledged events")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+
+	s.publish(ctx, t, channel, eventFixture("1"))
+
+	received := s.receive(ctx, t, subscriber, 1)
+	s.acknowledgeAll(ctx, t, subscriber, received)
+
+	time.Sleep(s.RedeliveryTimeout)
+	s.assertQuiet(ctx, t, subscriber)
+}
+
+// acknowledgeUnknown checks acknowledging a message the event bus never delivered fails.
+func (s suite) acknowledgeUnknown(t *testing.T) {
+	ctx := s.context(t)
+	subscriber, _ := s.subscribe(ctx, t)
+
+	err := subscriber.Acknowledge(ctx, unknownMessageID)
+	assert.Error(t, err)
+}
+
+// redelivery checks unacknowledged events are delivered again after the redelivery timeout.
+func (s suite) redelivery(t *testing.T) {
+	if !s.features.Redelivery {
+		t.Skip("event bus does not redeliver unacknowledged events")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+
+	s.publish(ctx, t, channel, eventFixture("1"))
+
+	first := s.receive(ctx, t, subscriber, 1)
+
+	time.Sleep(s.RedeliveryTimeout)
+
+	again := s.receive(ctx, t, subscriber, 1)
+	assert.Equal(t, ids(first), ids(again))
+	s.acknowledgeAll(ctx, t, subscriber, again)
+}
+
+// streamCancellation checks the stream delivers events and is closed once its context is done.
+func (s suite) streamCancellation(t *testing.T) {
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	defer cancel()
+
+	stream, err := subscriber.Stream(streamCtx)
+	if err != nil {
+		t.Fatalf("unexpected error streaming: %s", err)
+	}
+
+	s.publish(ctx, t, channel, eventFixture("1"))
+
+	select {
+	case event := <-stream:
+		assert.Equal(t, "1", event.Header.ID)
+		s.acknowledgeAll(ctx, t, subscriber, []messages.Event{event})
+	case <-time.After(s.Timeout):
+		t.Fatalf("no event streamed in %s", s.Timeout)
+	}
+
+	cancel()
+
+	timeout := time.After(s.Timeout)
+
+	for {
+		select {
+		case _, ok := <-stream:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("stream not closed %s after cancelling its context", s.Timeout)
+		}
+	}
+}
+
+// errorWrapping checks errors keep their cause so callers can tell them apart with errors.Is.
+func (s suite) errorWrapping(t *testing.T) {
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	cancelled, cancel := context.WithCancel(ctx)
+
+	cancel()
+
+	unsupportedErr := s.Publisher.Publish(ctx, channel, "not an event")
+	publishErr := s.Publisher.Publish(cancelled, channel, eventFixture("1"))
+	_, pullErr := subscriber.Pull(cancelled, 1)
+
+	assert.True(t, errors.Is(unsupportedErr, messages.ErrUnsupportedMessage), "publishing a string: %v", unsupportedErr)
+	assert.True(t, errors.Is(publishErr, context.Canceled), "publishing with a done context: %v", publishErr)
+	assert.True(t, errors.Is(pullErr, context.Canceled), "pulling with a done context: %v", pullErr)
+}
+
+// nack checks rejected events are delivered again.
+func (s suite) nack(t *testing.T) {
+	if !s.features.Nack {
+		t.Skip("event bus does not implement subscribers.NackEventBusSubscriber")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+
+	s.publish(ctx, t, channel, eventFixture("1"))
+
+	first := s.receive(ctx, t, subscriber, 1)
+
+	err := subscriber.(subscribers.NackEventBusSubscriber).Nack(ctx, first[0].Header.MessageID, 0)
+	if err != nil {
+		t.Fatalf("unexpected error rejecting %q: %s", first[0].Header.MessageID, err)
+	}
+
+	again := s.receive(ctx, t, subscriber, 1)
+	assert.Equal(t, ids(first), ids(again))
+	s.acknowledgeAll(ctx, t, subscriber, again)
+}
+
+// seek checks seeking to the earliest position delivers the channel history again.
+func (s suite) seek(t *testing.T) {
+	if !s.features.Seek {
+		t.Skip("event bus does not implement subscribers.SeekEventBusSubscriber")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	events := eventsFixture(2)
+
+	s.publish(ctx, t, channel, events...)
+	s.acknowledgeAll(ctx, t, subscriber, s.receive(ctx, t, subscriber, len(events)))
+
+	err := subscriber.(subscribers.SeekEventBusSubscriber).Seek(ctx, channel, subscribers.Earliest())
+	if err != nil {
+		t.Fatalf("unexpected error seeking %q: %s", channel, err)
+	}
+
+	again := s.receive(ctx, t, subscriber, len(events))
+	assert.Equal(t, ids(events), ids(again))
+	s.acknowledgeAll(ctx, t, subscriber, again)
+}
+
+// unsubscribe checks no event of a channel arrives after unsubscribing from it.
+func (s suite) unsubscribe(t *testing.T) {
+	if !s.features.Unsubscribe {
+		t.Skip("event bus does not implement subscribers.UnsubscribeEventBusSubscriber")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+
+	err := subscriber.(subscribers.UnsubscribeEventBusSubscriber).Unsubscribe(ctx, channel)
+	if err != nil {
+		t.Fatalf("unexpected error unsubscribing from %q: %s", channel, err)
+	}
+
+	s.publish(ctx, t, channel, eventFixture("1"))
+	s.assertQuiet(ctx, t, subscriber)
+}
+
+// publishBatch checks every event of a native batch is delivered.
+func (s suite) publishBatch(t *testing.T) {
+	if !s.features.Batch {
+		t.Skip("event bus does not implement publishers.BatchEventBusPublisher")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	events := eventsFixture(3)
+	batch := make([]interface{}, len(events))
+
+	for idx, event := range events {
+		batch[idx] = event
+	}
+
+	errs := s.Publisher.(publishers.BatchEventBusPublisher).PublishBatch(ctx, channel, batch)
+	assert.Equal(t, make([]error, len(events)), errs)
+
+	received := s.receive(ctx, t, subscriber, len(events))
+	assert.ElementsMatch(t, ids(events), ids(received))
+	s.acknowledgeAll(ctx, t, subscriber, received)
+}
+
+// publishDelayed checks delayed events are not delivered before their delay.
+func (s suite) publishDelayed(t *testing.T) {
+	if !s.features.Delay {
+		t.Skip("event bus does not implement publishers.DelayEventBusPublisher")
+	}
+
+	ctx := s.context(t)
+	subscriber, channel := s.subscribe(ctx, t)
+	delay := time.Second
+	start := time.Now()
+
+	err := s.Publisher.(publishers.DelayEventBusPublisher).PublishDelayed(ctx, channel, eventFixture("1"), delay)
+	if err != nil {
+		t.Fatalf("unexpected error publishing delayed event: %s", err)
+	}
+
+	received := s.receive(ctx, t, subscriber, 1)
+	assert.GreaterOrEqual(t, time.Since(start), delay)
+	s.acknowledgeAll(ctx, t, subscriber, received)
+}
@@ -0,0 +1,236 @@
+// Package eventbustest provides the conformance test suite every event bus adapter runs
+// against itself, so all of them behave the same behind publishers and subscribers.
+//
+//	func TestConformance(t *testing.T) {
+//		features := eventbustest.Run(t, eventbustest.Harness{
+//			Publisher:     bus,
+//			NewSubscriber: func(*testing.T) subscribers.EventBusSubscriber { return bus.Subscriber() },
+//		})
+//		t.Log(features)
+//	}
+package eventbustest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+const (
+	// DefaultTimeout time waiting for events when the harness does not set one.
+	DefaultTimeout = 5 * time.Second
+
+	// quietPeriod time waited to check no event arrives.
+	quietPeriod = 100 * time.Millisecond
+	// pollInterval time between pulls returning no events.
+	pollInterval = 10 * time.Millisecond
+	// unknownMessageID message id no event bus delivers.
+	unknownMessageID = "conformance-unknown-message-id"
+)
+
+var invalidChannelCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Harness gives the suite access to the event bus under test.
+type Harness struct {
+	// Publisher publishes into the event bus under test.
+	Publisher publishers.EventBusPublisher
+	// NewSubscriber returns a new subscriber of the event bus under test, every call gets its
+	// own subscription that receives every event of the channels it subscribes to.
+	NewSubscriber func(t *testing.T) subscribers.EventBusSubscriber
+	// NewChannel returns a channel no other test uses, the channel is named after the test when
+	// it is nil. Event buses that need channels created beforehand create them here.
+	NewChannel func(t *testing.T) string
+	// RedeliveryTimeout time after which the event bus delivers unacknowledged events again,
+	// zero when it does not redeliver them.
+	RedeliveryTimeout time.Duration
+	// Timeout maximum time waiting for events, DefaultTimeout when it is zero.
+	Timeout time.Duration
+}
+
+// Run runs the conformance suite as subtests of t. Checks of optional features the event bus
+// does not support are skipped, the returned features tell which ones it supports.
+func Run(t *testing.T, harness Harness) Features {
+	t.Helper()
+
+	if harness.Timeout <= 0 {
+		harness.Timeout = DefaultTimeout
+	}
+
+	if harness.NewChannel == nil {
+		harness.NewChannel = channelOf
+	}
+
+	suite := suite{Harness: harness, features: detect(harness.Publisher, harness.NewSubscriber(t))}
+	suite.features.Redelivery = harness.RedeliveryTimeout > 0
+
+	t.Run("ordering", suite.ordering)
+	t.Run("header round trip", suite.headerRoundTrip)
+	t.Run("pull batch size", suite.pullBatchSize)
+	t.Run("acknowledge", suite.acknowledge)
+	t.Run("acknowledge unknown message", suite.acknowledgeUnknown)
+	t.Run("redelivery", suite.redelivery)
+	t.Run("stream cancellation", suite.streamCancellation)
+	t.Run("error wrapping", suite.errorWrapping)
+	t.Run("nack", suite.nack)
+	t.Run("seek", suite.seek)
+	t.Run("unsubscribe", suite.unsubscribe)
+	t.Run("publish batch", suite.publishBatch)
+	t.Run("publish delayed", suite.publishDelayed)
+
+	t.Log("supported features:", suite.features)
+
+	return suite.features
+}
+
+type suite struct {
+	Harness
+	features Features
+}
+
+// channelOf names the channel after the test with the characters every event bus accepts.
+func channelOf(t *testing.T) string {
+	name := invalidChannelCharacters.ReplaceAllString(strings.ToLower(t.Name()), "-")
+
+	return "conformance-" + strings.Trim(name, "-")
+}
+
+// subscribe returns a new subscriber of a new channel.
+func (s suite) subscribe(ctx context.Context, t *testing.T) (subscribers.EventBusSubscriber, string) {
+	t.Helper()
+
+	channel := s.NewChannel(t)
+	subscriber := s.NewSubscriber(t)
+
+	err := subscriber.Subscribe(ctx, channel)
+	if err != nil {
+		t.Fatalf("unexpected error subscribing to %q: %s", channel, err)
+	}
+
+	return subscriber, channel
+}
+
+func (s suite) publish(ctx context.Context, t *testing.T, channel string, events ...messages.Event) {
+	t.Helper()
+
+	for _, event := range events {
+		err := s.Publisher.Publish(ctx, channel, event)
+		if err != nil {
+			t.Fatalf("unexpected error publishing event %q: %s", event.Header.ID, err)
+		}
+	}
+}
+
+// receive pulls until count events arrive or the timeout is over.
+func (s suite) receive(ctx context.Context, t *testing.T, subscriber subscribers.EventBusSubscriber, count int) []messages.Event {
+	t.Helper()
+
+	var result []messages.Event
+
+	deadline := time.Now().Add(s.Timeout)
+
+	for len(result) < count && time.Now().Before(deadline) {
+		missing := count - len(result)
+		if missing > math.MaxUint8 {
+			missing = math.MaxUint8
+		}
+
+		events, err := subscriber.Pull(ctx, uint8(missing))
+		if err != nil {
+			t.Fatalf("unexpected error pulling events: %s", err)
+		}
+
+		if len(events) == 0 {
+			time.Sleep(pollInterval)
+		}
+
+		result = append(result, events...)
+	}
+
+	if len(result) < count {
+		t.Fatalf("expected %d events, got %d in %s", count, len(result), s.Timeout)
+	}
+
+	return result
+}
+
+// assertQuiet fails when the subscriber receives any event in the quiet period.
+func (s suite) assertQuiet(ctx context.Context, t *testing.T, subscriber subscribers.EventBusSubscriber) {
+	t.Helper()
+
+	deadline := time.Now().Add(quietPeriod)
+
+	for time.Now().Before(deadline) {
+		events, err := subscriber.Pull(ctx, 1)
+		if err != nil {
+			t.Fatalf("unexpected error pulling events: %s", err)
+		}
+
+		if len(events) > 0 {
+			t.Fatalf("unexpected event %q", events[0].Header.ID)
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
+
+func (s suite) acknowledgeAll(ctx context.Context, t *testing.T, subscriber subscribers.EventBusSubscriber, events []messages.Event) {
+	t.Helper()
+
+	for _, event := range events {
+		err := subscriber.Acknowledge(ctx, event.Header.MessageID)
+		if err != nil {
+			t.Fatalf("unexpected error acknowledging %q: %s", event.Header.MessageID, err)
+		}
+	}
+}
+
+func (s suite) context(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 4*s.Timeout+2*s.RedeliveryTimeout)
+	t.Cleanup(cancel)
+
+	return ctx
+}
+
+// eventFixture returns an event using every header field.
+func eventFixture(id string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{
+			ID:          id,
+			Domain:      "conformance",
+			EventType:   "checked",
+			Version:     "1",
+			Application: "eventbustest",
+			OrderingKey: "conformance",
+			Attributes:  map[string]string{"suite": "conformance", "run": "1"},
+		},
+		Data: []byte{0x00, 0xff, '{', '"', 0x7f},
+	}
+}
+
+func eventsFixture(count int) []messages.Event {
+	events := make([]messages.Event, count)
+
+	for idx := range events {
+		events[idx] = eventFixture(fmt.Sprintf("%03d", idx))
+	}
+
+	return events
+}
+
+func ids(events []messages.Event) []string {
+	result := make([]string, len(events))
+
+	for idx, event := range events {
+		result[idx] = event.Header.ID
+	}
+
+	return result
+}
@@ -0,0 +1,81 @@
+package eventbustest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+// Features tells which optional features an event bus supports.
+type Features struct {
+	// Batch publishers.BatchEventBusPublisher.
+	Batch bool
+	// Delay publishers.DelayEventBusPublisher.
+	Delay bool
+	// Nack subscribers.NackEventBusSubscriber.
+	Nack bool
+	// Deadline subscribers.DeadlineEventBusSubscriber.
+	Deadline bool
+	// Seek subscribers.SeekEventBusSubscriber.
+	Seek bool
+	// Filter subscribers.FilterEventBusSubscriber.
+	Filter bool
+	// Unsubscribe subscribers.UnsubscribeEventBusSubscriber.
+	Unsubscribe bool
+	// Group subscribers.GroupEventBusSubscriber.
+	Group bool
+	// StreamErr subscribers.ErrEventBusSubscriber.
+	StreamErr bool
+	// Redelivery unacknowledged events are delivered again.
+	Redelivery bool
+}
+
+func detect(publisher publishers.EventBusPublisher, subscriber subscribers.EventBusSubscriber) Features {
+	var features Features
+
+	_, features.Batch = publisher.(publishers.BatchEventBusPublisher)
+	_, features.Delay = publisher.(publishers.DelayEventBusPublisher)
+	_, features.Nack = subscriber.(subscribers.NackEventBusSubscriber)
+	_, features.Deadline = subscriber.(subscribers.DeadlineEventBusSubscriber)
+	_, features.Seek = subscriber.(subscribers.SeekEventBusSubscriber)
+	_, features.Filter = subscriber.(subscribers.FilterEventBusSubscriber)
+	_, features.Unsubscribe = subscriber.(subscribers.UnsubscribeEventBusSubscriber)
+	_, features.Group = subscriber.(subscribers.GroupEventBusSubscriber)
+	_, features.StreamErr = subscriber.(subscribers.ErrEventBusSubscriber)
+
+	return features
+}
+
+// String lists the features as name=yes or name=no.
+func (f Features) String() string {
+	features := []struct {
+		name      string
+		supported bool
+	}{
+		{"batch", f.Batch},
+		{"delay", f.Delay},
+		{"nack", f.Nack},
+		{"deadline", f.Deadline},
+		{"seek", f.Seek},
+		{"filter", f.Filter},
+		{"unsubscribe", f.Unsubscribe},
+		{"group", f.Group},
+		{"streamerr", f.StreamErr},
+		{"redelivery", f.Redelivery},
+	}
+
+	result := make([]string, len(features))
+
+	for idx, feature := range features {
+		answer := "no"
+		if feature.supported {
+			answer = "yes"
+		}
+
+		result[idx] = fmt.Sprintf("%s=%s", feature.name, answer)
+	}
+
+	return strings.Join(result, " ")
+}
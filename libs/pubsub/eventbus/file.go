@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"sync"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/file"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+// FileScheme scheme of the event buses kept in a file, file:///tmp/bus.ndjson opens the bus
+// kept in that file and file://bus.ndjson a relative one. Events outlive the process and are
+// shared with every process opening the same file.
+const FileScheme = "file"
+
+var (
+	fileMu    sync.Mutex
+	fileBuses = make(map[string]*file.Bus)
+)
+
+func init() {
+	RegisterPublisher(FileScheme, func(_ context.Context, busURL *url.URL) (publishers.EventBusPublisher, error) {
+		bus, err := fileBus(busURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return bus, nil
+	})
+	RegisterSubscriber(FileScheme, func(_ context.Context, busURL *url.URL) (subscribers.EventBusSubscriber, error) {
+		bus, err := fileBus(busURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return bus.Subscriber(), nil
+	})
+}
+
+func fileBus(busURL *url.URL) (*file.Bus, error) {
+	path := filepath.Clean(filepath.FromSlash(busURL.Host + busURL.Path))
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	bus, ok := fileBuses[path]
+	if ok {
+		return bus, nil
+	}
+
+	bus, err := file.New(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileBuses[path] = bus
+
+	return bus, nil
+}
@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+// MemoryScheme scheme of the in-memory event buses, memory://name opens the bus with that name
+// shared by the whole process.
+const MemoryScheme = "memory"
+
+var (
+	memoryMu    sync.Mutex
+	memoryBuses = make(map[string]*memory.Bus)
+)
+
+func init() {
+	RegisterPublisher(MemoryScheme, func(_ context.Context, busURL *url.URL) (publishers.EventBusPublisher, error) {
+		return memoryBus(busURL), nil
+	})
+	RegisterSubscriber(MemoryScheme, func(_ context.Context, busURL *url.URL) (subscribers.EventBusSubscriber, error) {
+		return memoryBus(busURL).Subscriber(), nil
+	})
+}
+
+func memoryBus(busURL *url.URL) *memory.Bus {
+	memoryMu.Lock()
+	defer memoryMu.Unlock()
+
+	bus, ok := memoryBuses[busURL.Host]
+	if !ok {
+		bus = memory.New()
+		memoryBuses[busURL.Host] = bus
+	}
+
+	return bus
+}
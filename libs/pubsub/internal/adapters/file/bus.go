@@ -0,0 +1,436 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/capture"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+// DefaultPollInterval how often streams look for events appended by other processes.
+const DefaultPollInterval = 200 * time.Millisecond
+
+const (
+	filePermissions = 0o644
+	// acknowledgementsSuffix suffix of the file keeping the acknowledged message ids.
+	acknowledgementsSuffix = ".acks"
+	messageIDSeparator     = "/"
+)
+
+var errInvalidMessageID = errors.New("invalid message id")
+
+// Bus is an event bus kept in a file, every published event is appended to it as a
+// capture.Record line. Events appended by other processes are read before every operation,
+// message ids are the ones of the memory event bus, so they are the same for every process.
+// Acknowledged message ids are appended to a second file, the path followed by .acks.
+type Bus struct {
+	path         string
+	pollInterval time.Duration
+	now          func() time.Time
+
+	mu           sync.Mutex                  // serializes reading the files into the bus.
+	offset       int64                       // bytes of the events file already read.
+	acksOffset   int64                       // bytes of the acknowledgements file already read.
+	acknowledged map[string]map[int]struct{} // acknowledged offsets by channel.
+	channels     map[string]struct{}
+	memory       *memory.Bus
+}
+
+// New opens the event bus kept in the file, the file is created when it does not exist.
+func New(path string) (*Bus, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, filePermissions)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open event bus file")
+	}
+
+	_ = file.Close()
+
+	newBus := Bus{
+		path:         path,
+		pollInterval: DefaultPollInterval,
+		now:          time.Now,
+		acknowledged: make(map[string]map[int]struct{}),
+		channels:     make(map[string]struct{}),
+		memory:       memory.New(),
+	}
+
+	return &newBus, nil
+}
+
+// Publish appends the event to the file.
+func (b *Bus) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "could not publish event")
+	}
+
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	event.Header.Channel = messageChannel
+
+	line, err := (capture.Record{Channel: messageChannel, Time: b.now(), Event: event}).MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "could not encode event")
+	}
+
+	file, err := os.OpenFile(b.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, filePermissions)
+	if err != nil {
+		return errors.Wrap(err, "could not open event bus file")
+	}
+
+	// the line is written at once, so concurrent writers never interleave their events.
+	_, err = file.Write(append(line, '\n'))
+	closeErr := file.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "could not publish event")
+	}
+
+	return b.sync(ctx)
+}
+
+// Events returns the events published into the channel.
+func (b *Bus) Events(messageChannel string) ([]messages.Event, error) {
+	err := b.sync(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return b.memory.Events(messageChannel), nil
+}
+
+// Subscriber returns a new subscriber of the bus, every subscriber reads the channels on
+// its own.
+func (b *Bus) Subscriber() *Subscriber {
+	newSubscriber := Subscriber{
+		bus:     b,
+		memory:  b.memory.Subscriber(),
+		resumed: make(map[string]struct{}),
+		closing: make(chan struct{}),
+	}
+
+	return &newSubscriber
+}
+
+// sync publishes the complete lines appended to the events file since the last sync into the
+// memory bus, in file order, and reads the message ids acknowledged since then.
+func (b *Bus) sync(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := readLines(b.path, &b.offset, func(line []byte) error {
+		var record capture.Record
+
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return errors.Wrapf(err, "invalid record at byte %d of %s", b.offset, b.path)
+		}
+
+		b.channels[record.Channel] = struct{}{}
+
+		return b.memory.PublishAt(ctx, record.Channel, record.Event, record.Time)
+	})
+	if err != nil {
+		return err
+	}
+
+	acksPath := b.path + acknowledgementsSuffix
+
+	err = readLines(acksPath, &b.acksOffset, func(line []byte) error {
+		channel, offset, err := parseMessageID(string(line))
+		if err != nil {
+			return errors.Wrapf(err, "invalid acknowledgement at byte %d of %s", b.acksOffset, acksPath)
+		}
+
+		b.markAcknowledged(channel, offset)
+
+		return nil
+	})
+	if os.IsNotExist(errors.Cause(err)) {
+		return nil
+	}
+
+	return err
+}
+
+// acknowledge appends the message id to the acknowledgements file.
+func (b *Bus) acknowledge(messageID string) error {
+	channel, offset, err := parseMessageID(messageID)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.acknowledged[channel][offset]; ok {
+		return nil
+	}
+
+	file, err := os.OpenFile(b.path+acknowledgementsSuffix, os.O_WRONLY|os.O_APPEND|os.O_CREATE, filePermissions)
+	if err != nil {
+		return errors.Wrap(err, "could not open acknowledgements file")
+	}
+
+	_, err = file.Write([]byte(messageID + "\n"))
+	closeErr := file.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "could not acknowledge message")
+	}
+
+	b.markAcknowledged(channel, offset)
+
+	return nil
+}
+
+// markAcknowledged records the acknowledged offset of the channel. The lock must be held.
+func (b *Bus) markAcknowledged(channel string, offset int) {
+	if b.acknowledged[channel] == nil {
+		b.acknowledged[channel] = make(map[int]struct{})
+	}
+
+	b.acknowledged[channel][offset] = struct{}{}
+}
+
+// resumeOffsets returns the offset of the first unacknowledged event of every known channel
+// matching the given channel.
+func (b *Bus) resumeOffsets(channel string) map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make(map[string]int)
+
+	for name := range b.channels {
+		if !subscribers.MatchChannel(channel, name) {
+			continue
+		}
+
+		offset := 0
+		for _, ok := b.acknowledged[name][offset]; ok; _, ok = b.acknowledged[name][offset] {
+			offset++
+		}
+
+		result[name] = offset
+	}
+
+	return result
+}
+
+// readLines hands the complete lines appended to the file since the offset to the function,
+// the offset is moved past every handled line. A line without its line break is still being
+// written, it is read the next time.
+func readLines(path string, offset *int64, handle func(line []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open %s", path)
+	}
+
+	defer file.Close()
+
+	_, err = file.Seek(*offset, io.SeekStart)
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+
+	for {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			return nil
+		}
+
+		line := bytes.TrimSpace(content[:end])
+		content = content[end+1:]
+
+		if len(line) > 0 {
+			err = handle(line)
+			if err != nil {
+				return err
+			}
+		}
+
+		*offset += int64(end + 1)
+	}
+}
+
+// parseMessageID returns the channel and offset of the memory event bus message id.
+func parseMessageID(messageID string) (string, int, error) {
+	idx := strings.LastIndex(messageID, messageIDSeparator)
+	if idx < 0 {
+		return "", 0, errors.WithMessagef(errInvalidMessageID, "%q", messageID)
+	}
+
+	offset, err := strconv.Atoi(messageID[idx+1:])
+	if err != nil || offset < 0 {
+		return "", 0, errors.WithMessagef(errInvalidMessageID, "%q", messageID)
+	}
+
+	return messageID[:idx], offset, nil
+}
+
+// Subscriber reads the bus channels it is subscribed to. Events are delivered once, there is
+// no redelivery of unacknowledged events, yet acknowledgements are shared by every subscriber
+// of the file, so later subscriptions start after the acknowledged events.
+type Subscriber struct {
+	bus       *Bus
+	memory    *memory.Subscriber
+	mu        sync.Mutex
+	resumed   map[string]struct{} // channels already moved after their acknowledged events.
+	closeOnce sync.Once
+	closing   chan struct{} // closed by Close to stop polling the file.
+}
+
+// Subscribe starts reading the channels matching the given channel from their first
+// unacknowledged event, like a consumer group resuming from its committed offsets. Seeking
+// to the earliest position reads the acknowledged events again.
+func (s *Subscriber) Subscribe(ctx context.Context, channel string) error {
+	err := s.bus.sync(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.memory.Subscribe(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// channels already read through another subscription keep their position.
+	for name, offset := range s.bus.resumeOffsets(channel) {
+		if _, ok := s.resumed[name]; ok {
+			continue
+		}
+
+		err = s.memory.Seek(ctx, name, subscribers.AtOffset(strconv.Itoa(offset)))
+		if err != nil {
+			return err
+		}
+
+		s.resumed[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// Unsubscribe stops reading the channels matching the given channel.
+func (s *Subscriber) Unsubscribe(ctx context.Context, channel string) error {
+	return s.memory.Unsubscribe(ctx, channel)
+}
+
+// Pull returns up to the given number of unread events, every unread event when it is zero.
+func (s *Subscriber) Pull(ctx context.Context, numberOfMessages uint8) ([]messages.Event, error) {
+	err := s.bus.sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.memory.Pull(ctx, numberOfMessages)
+}
+
+// Stream streams the unread events and then every event appended to the file until the
+// context is done or the subscriber is closed. The file is polled for events appended by
+// other processes.
+func (s *Subscriber) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	err := s.bus.sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.memory.Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.poll(ctx)
+
+	return result, nil
+}
+
+// Acknowledge keeps the message id in the acknowledgements file once it checks the message
+// was published, so subscriptions of any process start after it.
+func (s *Subscriber) Acknowledge(ctx context.Context, messageID string) error {
+	err := s.bus.sync(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.memory.Acknowledge(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	return s.bus.acknowledge(messageID)
+}
+
+// Close ends the open streams, pulling or streaming afterwards fails. The file is kept.
+func (s *Subscriber) Close(ctx context.Context) error {
+	err := s.memory.Close(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.closeOnce.Do(func() { close(s.closing) })
+
+	return nil
+}
+
+// Seek moves the channels matching the given channel to the position.
+func (s *Subscriber) Seek(ctx context.Context, channel string, position subscribers.Position) error {
+	err := s.bus.sync(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.memory.Seek(ctx, channel, position)
+}
+
+// poll reads the events appended to the file until the context is done or the subscriber is
+// closed, the memory bus hands them to the open streams.
+func (s *Subscriber) poll(ctx context.Context) {
+	ticker := time.NewTicker(s.bus.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.closing:
+			return
+		case <-ticker.C:
+		}
+
+		err := s.bus.sync(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println("error", err, "method", "file.Subscriber.poll")
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package file_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/file"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsOutliveTheBus(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "bus.ndjson")
+	publisher, _ := file.New(path)
+	_ = publisher.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: "1"}, Data: []byte(`{}`)})
+	_ = publisher.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: "2"}})
+	// When
+	reopened, err := file.New(path)
+	subscriber := reopened.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders")
+	_ = subscriber.Seek(ctx, "orders", subscribers.Earliest())
+	got, _ := subscriber.Pull(ctx, 0)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []messages.Event{
+		{Header: messages.Header{ID: "1", MessageID: "orders/0", Channel: "orders"}, Data: []byte(`{}`)},
+		{Header: messages.Header{ID: "2", MessageID: "orders/1", Channel: "orders"}},
+	}, got)
+	assert.NoError(t, subscriber.Acknowledge(ctx, "orders/1"))
+	assert.Error(t, subscriber.Acknowledge(ctx, "orders/2"))
+}
+
+func TestStreamReadsEventsOfOtherBuses(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "bus.ndjson")
+	publisher, _ := file.New(path)
+	reader, _ := file.New(path)
+	subscriber := reader.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders")
+	stream, _ := subscriber.Stream(ctx)
+	// When
+	_ = publisher.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: "1"}})
+	got := <-stream
+	// Then
+	assert.Equal(t, "1", got.Header.ID)
+	assert.NoError(t, subscriber.Close(ctx))
+}
+
+func TestPartialLinesWaitForTheirLineBreak(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	path := filepath.Join(t.TempDir(), "bus.ndjson")
+	_ = os.WriteFile(path, []byte(`{"channel":"orders","header":{"id":"1"}}`), 0o600)
+	bus, _ := file.New(path)
+	before, _ := bus.Events("orders")
+	// When
+	writer, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	_, _ = writer.WriteString("\n")
+	_ = writer.Close()
+	after, err := bus.Events("orders")
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, before)
+	assert.Equal(t, []messages.Event{{Header: messages.Header{ID: "1", MessageID: "orders/0", Channel: "orders"}}}, after)
+}
+
+func TestSubscriptionsStartAfterAcknowledgedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "bus.ndjson")
+	bus, _ := file.New(path)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_ = bus.Publish(ctx, "orders", messages.Event{Header: messages.Header{ID: id}})
+	}
+
+	subscriber := bus.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders")
+	_ = subscriber.Acknowledge(ctx, "orders/0")
+	_ = subscriber.Acknowledge(ctx, "orders/1")
+	_ = subscriber.Acknowledge(ctx, "orders/3")
+	// When
+	reopened, err := file.New(path)
+	resumed := reopened.Subscriber()
+	_ = resumed.Subscribe(ctx, "orders")
+	got, _ := resumed.Pull(ctx, 0)
+	_ = resumed.Seek(ctx, "orders", subscribers.Earliest())
+	history, _ := resumed.Pull(ctx, 0)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, ids(got))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids(history))
+}
+
+func ids(events []messages.Event) []string {
+	result := make([]string, 0, len(events))
+
+	for _, event := range events {
+		result = append(result, event.Header.ID)
+	}
+
+	return result
+}
@@ -0,0 +1,28 @@
+package file_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus/eventbustest"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/file"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	bus, err := file.New(filepath.Join(t.TempDir(), "bus.ndjson"))
+	// When
+	features := eventbustest.Run(t, eventbustest.Harness{
+		Publisher: bus,
+		NewSubscriber: func(*testing.T) subscribers.EventBusSubscriber {
+			return bus.Subscriber()
+		},
+	})
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, eventbustest.Features{Seek: true, Unsubscribe: true}, features)
+}
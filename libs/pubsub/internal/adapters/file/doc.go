@@ -0,0 +1,5 @@
+// Package file provides an event bus that keeps every published event in a newline delimited
+// json file, so events outlive the process and every process opening the file shares them.
+// Acknowledged message ids are kept next to it, subscriptions start after them.
+// It is meant for local development and command line tools.
+package file
@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+)
+
+// Topic returns the kafka subscription topic of the channel. Channel patterns become the
+// anchored regular expressions kafka clients subscribe to when the topic starts with ^.
+func Topic(channel string) string {
+	if !subscribers.IsChannelPattern(channel) {
+		return channel
+	}
+
+	segments := strings.Split(channel, subscribers.ChannelSeparator)
+	expressions := make([]string, len(segments))
+
+	for idx, segment := range segments {
+		switch {
+		case segment == subscribers.MultiSegmentWildcard && idx == len(segments)-1:
+			expressions[idx] = `.+`
+		case segment == subscribers.SingleSegmentWildcard:
+			expressions[idx] = `[^.]+`
+		default:
+			expressions[idx] = regexp.QuoteMeta(segment)
+		}
+	}
+
+	return "^" + strings.Join(expressions, regexp.QuoteMeta(subscribers.ChannelSeparator)) + "$"
+}
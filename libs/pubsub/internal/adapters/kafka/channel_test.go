@@ -0,0 +1,58 @@
+package kafka_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopic(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		channel  string
+		matches  []string
+		rejected []string
+	}{
+		"single segment wildcard": {
+			channel:  "credit.*.created",
+			matches:  []string{"credit.loan.created", "credit.card.created"},
+			rejected: []string{"credit.loan.updated", "credit.loan.card.created", "creditxloan.created"},
+		},
+		"multi segment wildcard": {
+			channel:  "credit.>",
+			matches:  []string{"credit.loan", "credit.loan.created"},
+			rejected: []string{"credit", "debit.loan"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			got := regexp.MustCompile(kafka.Topic(test.channel))
+			// Then
+			for _, topic := range test.matches {
+				assert.True(t, got.MatchString(topic), topic)
+			}
+
+			for _, topic := range test.rejected {
+				assert.False(t, got.MatchString(topic), topic)
+			}
+		})
+	}
+}
+
+func TestTopicWithoutPattern(t *testing.T) {
+	t.Parallel()
+
+	// When
+	got := kafka.Topic("credit.loan.created")
+	// Then
+	assert.Equal(t, "credit.loan.created", got)
+}
@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const messageIDSeparator = "/"
+
+var (
+	errInvalidOffset    = errors.New("invalid offset")
+	errUnknownPosition  = errors.New("unknown position kind")
+	errUnknownMessageID = errors.New("unknown message id")
+	errClosed           = errors.New("subscriber is closed")
+)
+
+// Bus is an in-memory event bus, every channel keeps the events published into it in order.
+type Bus struct {
+	mu        sync.Mutex
+	channels  map[string][]record
+	published chan struct{} // closed and replaced on every publish.
+	now       func() time.Time
+}
+
+type record struct {
+	event       messages.Event
+	publishedAt time.Time
+}
+
+// New instances a new in-memory event bus.
+func New() *Bus {
+	newBus := Bus{
+		channels:  make(map[string][]record),
+		published: make(chan struct{}),
+		now:       time.Now,
+	}
+
+	return &newBus
+}
+
+// Publish appends the event to the channel.
+func (b *Bus) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	return b.PublishAt(ctx, messageChannel, message, time.Time{})
+}
+
+// PublishAt appends the event to the channel as published at the given time, or now when it is
+// zero. Event buses keeping their events elsewhere use it to restore them.
+func (b *Bus) PublishAt(ctx context.Context, messageChannel string, message interface{}, publishedAt time.Time) error {
+	if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), "could not publish event")
+	}
+
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if publishedAt.IsZero() {
+		publishedAt = b.now()
+	}
+
+	offset := len(b.channels[messageChannel])
+	event.Header.Channel = messageChannel
+	event.Header.MessageID = messageChannel + messageIDSeparator + strconv.Itoa(offset)
+	b.channels[messageChannel] = append(b.channels[messageChannel], record{event: event, publishedAt: publishedAt})
+
+	close(b.published)
+	b.published = make(chan struct{})
+
+	return nil
+}
+
+// Events returns the events published into the channel.
+func (b *Bus) Events(messageChannel string) []messages.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]messages.Event, 0, len(b.channels[messageChannel]))
+	for _, published := range b.channels[messageChannel] {
+		result = append(result, published.event)
+	}
+
+	return result
+}
+
+// Subscriber returns a new subscriber of the bus, every subscriber reads the channels on
+// its own.
+func (b *Bus) Subscriber() *Subscriber {
+	newSubscriber := Subscriber{
+		bus:       b,
+		positions: make(map[string]int),
+		closing:   make(chan struct{}),
+	}
+
+	return &newSubscriber
+}
+
+// Subscriber reads the bus channels it is subscribed to. Events are delivered once, there is
+// no redelivery of unacknowledged events.
+type Subscriber struct {
+	bus           *Bus
+	subscriptions []string
+	positions     map[string]int // next offset to read by channel.
+	closed        bool
+	closing       chan struct{} // closed by Close to end the open streams.
+}
+
+// Subscribe starts reading the channels matching the given channel from their current end.
+func (s *Subscriber) Subscribe(_ context.Context, channel string) error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.subscriptions = append(s.subscriptions, channel)
+
+	for name, records := range s.bus.channels {
+		if _, ok := s.positions[name]; !ok && subscribers.MatchChannel(channel, name) {
+			s.positions[name] = len(records)
+		}
+	}
+
+	return nil
+}
+
+// Unsubscribe stops reading the channels matching the given channel.
+func (s *Subscriber) Unsubscribe(_ context.Context, channel string) error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for idx, subscription := range s.subscriptions {
+		if subscription == channel {
+			s.subscriptions = append(s.subscriptions[:idx:idx], s.subscriptions[idx+1:]...)
+
+			break
+		}
+	}
+
+	for name := range s.positions {
+		if !s.matches(name) {
+			delete(s.positions, name)
+		}
+	}
+
+	return nil
+}
+
+// Pull returns up to the given number of unread events, every unread event when it is zero.
+func (s *Subscriber) Pull(ctx context.Context, numberOfMessages uint8) ([]messages.Event, error) {
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "could not pull events")
+	}
+
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if s.closed {
+		return nil, errors.Wrap(errClosed, "could not pull events")
+	}
+
+	result, _ := s.read(int(numberOfMessages))
+
+	return result, nil
+}
+
+// Stream streams the unread events and then every event published into the subscribed
+// channels until the context is done or the subscriber is closed.
+func (s *Subscriber) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	s.bus.mu.Lock()
+	closed := s.closed
+	s.bus.mu.Unlock()
+
+	if closed {
+		return nil, errors.Wrap(errClosed, "could not stream events")
+	}
+
+	result := make(chan messages.Event)
+
+	go func() {
+		defer close(result)
+
+		for {
+			s.bus.mu.Lock()
+			events, published := s.read(0)
+			s.bus.mu.Unlock()
+
+			for _, event := range events {
+				select {
+				case <-ctx.Done():
+					return
+				case <-s.closing:
+					return
+				case result <- event:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.closing:
+				return
+			case <-published:
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+// Acknowledge checks the message was published, events are never redelivered. The error does
+// not repeat the message id, subscribers.Subscriber already tells it.
+func (s *Subscriber) Acknowledge(_ context.Context, messageID string) error {
+	idx := strings.LastIndex(messageID, messageIDSeparator)
+	if idx < 0 {
+		return errUnknownMessageID
+	}
+
+	offset, err := strconv.Atoi(messageID[idx+1:])
+
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if err != nil || offset < 0 || offset >= len(s.bus.channels[messageID[:idx]]) {
+		return errUnknownMessageID
+	}
+
+	return nil
+}
+
+// Close ends the open streams, pulling or streaming afterwards fails. The published events
+// are kept in the bus.
+func (s *Subscriber) Close(_ context.Context) error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if s.closed {
+		return errClosed
+	}
+
+	s.closed = true
+	close(s.closing)
+
+	return nil
+}
+
+// Seek moves the channels matching the given channel to the position.
+func (s *Subscriber) Seek(_ context.Context, channel string, position subscribers.Position) error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for name, records := range s.bus.channels {
+		if !subscribers.MatchChannel(channel, name) {
+			continue
+		}
+
+		offset, err := offsetOf(records, position)
+		if err != nil {
+			return err
+		}
+
+		s.positions[name] = offset
+	}
+
+	return nil
+}
+
+// read returns up to max unread events, or every one when max is zero, sorted by channel name
+// and offset, and the channel closed on the next publish. The bus lock must be held.
+func (s *Subscriber) read(max int) ([]messages.Event, <-chan struct{}) {
+	var result []messages.Event
+
+	names := make([]string, 0, len(s.bus.channels))
+
+	for name := range s.bus.channels {
+		if s.matches(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		records := s.bus.channels[name]
+
+		for offset := s.positions[name]; offset < len(records); offset++ {
+			if max > 0 && len(result) == max {
+				return result, s.bus.published
+			}
+
+			result = append(result, records[offset].event)
+			s.positions[name] = offset + 1
+		}
+	}
+
+	return result, s.bus.published
+}
+
+// matches reports whether the channel matches any subscription. The bus lock must be held.
+func (s *Subscriber) matches(name string) bool {
+	for _, subscription := range s.subscriptions {
+		if subscribers.MatchChannel(subscription, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func offsetOf(records []record, position subscribers.Position) (int, error) {
+	switch position.Kind {
+	case subscribers.PositionEarliest:
+		return 0, nil
+	case subscribers.PositionLatest:
+		return len(records), nil
+	case subscribers.PositionTime:
+		for offset, published := range records {
+			if !published.publishedAt.Before(position.Time) {
+				return offset, nil
+			}
+		}
+
+		return len(records), nil
+	case subscribers.PositionOffset:
+		offset, err := strconv.Atoi(position.Offset)
+		if err != nil || offset < 0 {
+			return 0, errors.WithMessagef(errInvalidOffset, "%q", position.Offset)
+		}
+
+		if offset > len(records) {
+			offset = len(records)
+		}
+
+		return offset, nil
+	default:
+		return 0, errors.WithMessagef(errUnknownPosition, "%d", position.Kind)
+	}
+}
@@ -0,0 +1,185 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishAndPull(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	bus := memory.New()
+	subscriber := bus.Subscriber()
+	_ = bus.Publish(ctx, "credit.loan.created", eventFixture("0"))
+	_ = subscriber.Subscribe(ctx, "credit.*.created")
+	_ = bus.Publish(ctx, "credit.loan.created", eventFixture("1"))
+	_ = bus.Publish(ctx, "credit.card.created", eventFixture("2"))
+	_ = bus.Publish(ctx, "credit.card.updated", eventFixture("3"))
+	// When
+	got, err := subscriber.Pull(ctx, 0)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "1"}, ids(got))
+	assert.Equal(t, "credit.card.created", got[0].Header.Channel)
+	assert.Equal(t, "credit.loan.created/1", got[1].Header.MessageID)
+	assert.NoError(t, subscriber.Acknowledge(ctx, got[1].Header.MessageID))
+	assert.Error(t, subscriber.Acknowledge(ctx, "credit.loan.created/9"))
+}
+
+func TestSeek(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	bus := memory.New()
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("0"))
+	time.Sleep(2 * time.Millisecond)
+
+	afterFirst := time.Now()
+
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("1"))
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("2"))
+
+	tests := map[string]struct {
+		position subscribers.Position
+		expected []string
+	}{
+		"earliest": {position: subscribers.Earliest(), expected: []string{"0", "1", "2"}},
+		"latest":   {position: subscribers.Latest(), expected: nil},
+		"time":     {position: subscribers.AtTime(afterFirst), expected: []string{"1", "2"}},
+		"offset":   {position: subscribers.AtOffset("2"), expected: []string{"2"}},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			subscriber := bus.Subscriber()
+			_ = subscriber.Subscribe(ctx, "orders-topic")
+			// When
+			err := subscriber.Seek(ctx, "orders-topic", test.position)
+			got, _ := subscriber.Pull(ctx, 0)
+			// Then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, ids(got))
+		})
+	}
+}
+
+func TestSeekInvalidOffset(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	bus := memory.New()
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("0"))
+	subscriber := bus.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	err := subscriber.Seek(ctx, "orders-topic", subscribers.AtOffset("first"))
+	// Then
+	assert.Error(t, err)
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	bus := memory.New()
+	subscriber := bus.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("0"))
+	// When
+	stream, err := subscriber.Stream(ctx)
+	first := <-stream
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("1"))
+	second := <-stream
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "0", first.Header.ID)
+	assert.Equal(t, "1", second.Header.ID)
+}
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	bus := memory.New()
+	subscriber := bus.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	stream, _ := subscriber.Stream(ctx)
+	// When
+	err := subscriber.Close(ctx)
+
+	for range stream {
+	}
+	// Then
+	assert.NoError(t, err)
+	assert.Error(t, subscriber.Close(ctx))
+
+	_, err = subscriber.Pull(ctx, 0)
+	assert.Error(t, err)
+
+	_, err = subscriber.Stream(ctx)
+	assert.Error(t, err)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	bus := memory.New()
+	subscriber := bus.Subscriber()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	_ = subscriber.Subscribe(ctx, "payments-topic")
+	// When
+	err := subscriber.Unsubscribe(ctx, "orders-topic")
+	_ = bus.Publish(ctx, "orders-topic", eventFixture("0"))
+	_ = bus.Publish(ctx, "payments-topic", eventFixture("1"))
+	got, _ := subscriber.Pull(ctx, 0)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, ids(got))
+}
+
+func eventFixture(id string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{
+			ID:          id,
+			Domain:      "loans",
+			EventType:   "orders",
+			Version:     "0.1.0",
+			Application: "core-app",
+		},
+		Data: []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
+
+func ids(events []messages.Event) []string {
+	var result []string
+
+	for _, event := range events {
+		result = append(result, event.Header.ID)
+	}
+
+	return result
+}
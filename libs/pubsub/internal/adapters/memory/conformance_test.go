@@ -0,0 +1,26 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/eventbus/eventbustest"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	bus := memory.New()
+	// When
+	features := eventbustest.Run(t, eventbustest.Harness{
+		Publisher: bus,
+		NewSubscriber: func(*testing.T) subscribers.EventBusSubscriber {
+			return bus.Subscriber()
+		},
+	})
+	// Then
+	assert.Equal(t, eventbustest.Features{Seek: true, Unsubscribe: true}, features)
+}
@@ -0,0 +1,3 @@
+// Package memory provides an in-memory event bus that retains every published event, meant
+// for tests and local development.
+package memory
@@ -0,0 +1,68 @@
+package sns
+
+import (
+	"encoding/json"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+	"github.com/pkg/errors"
+)
+
+// FilterPolicy returns the subscription filter policy of the filter, header fields and
+// attributes are published as message attributes named like the filter fields. It returns
+// false when part of the filter has no filter policy equivalent, like version comparisons or
+// several conditions on the same field, so it must also be applied client side. Comparisons
+// are never pushed down, sns numeric matching does not understand versions like "2.1.0".
+func FilterPolicy(filter routing.Filter) (string, bool, error) {
+	policy := make(map[string][]interface{})
+	complete := true
+
+	for _, condition := range filter {
+		name := condition.Field
+		if key, ok := routing.AttributeKey(condition.Field); ok {
+			name = key
+		}
+
+		rules, ok := policyRules(condition)
+		if _, duplicated := policy[name]; !ok || duplicated || condition.Field == routing.FieldChannel {
+			complete = false
+
+			continue
+		}
+
+		policy[name] = rules
+	}
+
+	if len(policy) == 0 {
+		return "", false, nil
+	}
+
+	content, err := json.Marshal(policy)
+	if err != nil {
+		return "", false, errors.Wrap(err, "could not encode filter policy")
+	}
+
+	return string(content), complete, nil
+}
+
+func policyRules(condition routing.Condition) ([]interface{}, bool) {
+	var rules []interface{}
+
+	switch condition.Operator {
+	case routing.OperatorEqual:
+		for _, value := range condition.Values {
+			rules = append(rules, value)
+		}
+	case routing.OperatorNotEqual:
+		rules = append(rules, map[string]interface{}{"anything-but": condition.Values})
+	case routing.OperatorPrefix:
+		for _, value := range condition.Values {
+			rules = append(rules, map[string]interface{}{"prefix": value})
+		}
+	case routing.OperatorExists:
+		rules = append(rules, map[string]interface{}{"exists": true})
+	default:
+		return nil, false
+	}
+
+	return rules, true
+}
@@ -0,0 +1,75 @@
+package sns_test
+
+import (
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/sns"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		filter           routing.Filter
+		expectedPolicy   string
+		expectedComplete bool
+	}{
+		"exact and exists": {
+			filter: routing.Filter{
+				routing.Equals(routing.FieldDomain, "loans"),
+				routing.Exists(routing.Attribute("tenant")),
+			},
+			expectedPolicy:   `{"domain":["loans"],"tenant":[{"exists":true}]}`,
+			expectedComplete: true,
+		},
+		"major version": {
+			filter: routing.Filter{
+				routing.Equals(routing.FieldDomain, "loans"),
+				routing.AtLeast(routing.FieldVersion, "2"),
+			},
+			expectedPolicy:   `{"domain":["loans"]}`,
+			expectedComplete: false,
+		},
+		"semantic version": {
+			filter: routing.Filter{
+				routing.NotEquals(routing.FieldEventType, "loan-deleted"),
+				routing.AtLeast(routing.FieldVersion, "2.1.0"),
+			},
+			expectedPolicy:   `{"eventtype":[{"anything-but":["loan-deleted"]}]}`,
+			expectedComplete: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			policy, complete, err := sns.FilterPolicy(test.filter)
+			// Then
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.expectedPolicy, policy)
+			assert.Equal(t, test.expectedComplete, complete)
+		})
+	}
+}
+
+func TestFilterPolicyLeavesVersionsToTheClient(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	filter := routing.Filter{routing.AtLeast(routing.FieldVersion, "2")}
+	header := messages.Header{Version: "2.1.0"}
+	// When
+	policy, complete, err := sns.FilterPolicy(filter)
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, policy)
+	assert.False(t, complete)
+	assert.True(t, filter.Match(header))
+}
@@ -0,0 +1,163 @@
+package lifecycle
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ShutdownFunc stops a component, it must return once the component is stopped or the
+// context is done.
+type ShutdownFunc func(ctx context.Context) error
+
+// Settings contains the coordinator configuration.
+type Settings struct {
+	// DrainDelay time to wait after reporting not-ready and before stopping the first stage,
+	// so load balancers stop sending traffic to the service. Zero means no delay.
+	DrainDelay time.Duration
+}
+
+// Coordinator shuts down the registered stages in the order they were added. Stages are
+// usually added as consumers first, then async publishers, publishers, subscribers and finally
+// the event bus adapters, so events being handled can still be acknowledged and published.
+type Coordinator struct {
+	mu           sync.Mutex
+	stages       []stage
+	drainDelay   time.Duration
+	shuttingDown bool
+	done         chan struct{}
+	err          error
+}
+
+type stage struct {
+	name     string
+	shutdown ShutdownFunc
+}
+
+// NewCoordinator instances a new shutdown coordinator.
+func NewCoordinator(settings Settings) *Coordinator {
+	newCoordinator := Coordinator{
+		drainDelay: settings.DrainDelay,
+		done:       make(chan struct{}),
+	}
+
+	return &newCoordinator
+}
+
+// Add registers a stage to shut down after the ones already added.
+func (c *Coordinator) Add(name string, shutdown ShutdownFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stages = append(c.stages, stage{name: name, shutdown: shutdown})
+}
+
+// ShuttingDown reports whether the shutdown started, health checks use it to report the
+// service as not-ready.
+func (c *Coordinator) ShuttingDown() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.shuttingDown
+}
+
+// Shutdown stops every stage in order sharing the context deadline. A failing stage does not
+// prevent the next ones from stopping, the first error is returned. Calling it again waits for
+// the first call and returns its result.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if c.shuttingDown {
+		c.mu.Unlock()
+
+		return c.wait(ctx)
+	}
+
+	c.shuttingDown = true
+	stages := append([]stage(nil), c.stages...)
+	c.mu.Unlock()
+
+	err := c.drain(ctx)
+
+	for _, current := range stages {
+		stageErr := current.shutdown(ctx)
+		if stageErr == nil {
+			continue
+		}
+
+		log.Println(
+			"error", stageErr,
+			"stage", current.name,
+			"method", "lifecycle.Coordinator.Shutdown",
+		)
+
+		if err == nil {
+			err = errors.WithMessagef(stageErr, "shutting down %s", current.name)
+		}
+	}
+
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	close(c.done)
+
+	return err
+}
+
+// ShutdownOnSignal waits for one of the signals, SIGINT and SIGTERM by default, or the context
+// to be done and then shuts down giving the stages up to the timeout.
+func (c *Coordinator) ShutdownOnSignal(ctx context.Context, timeout time.Duration, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+
+	defer signal.Stop(received)
+
+	select {
+	case <-ctx.Done():
+	case <-received:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.Shutdown(shutdownCtx)
+}
+
+// drain waits for the drain delay or the context to be done.
+func (c *Coordinator) drain(ctx context.Context) error {
+	if c.drainDelay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(c.drainDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "could not wait for the drain delay")
+	}
+}
+
+// wait waits for the running shutdown and returns its result.
+func (c *Coordinator) wait(ctx context.Context) error {
+	select {
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "could not wait for the shutdown")
+	}
+}
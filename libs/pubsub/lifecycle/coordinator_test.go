@@ -0,0 +1,147 @@
+package lifecycle_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/lifecycle"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownRunsStagesInOrder(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	calls := new(callsRecorder)
+	coordinator := lifecycle.NewCoordinator(lifecycle.Settings{})
+	coordinator.Add("consumer", calls.stage("consumer", nil))
+	coordinator.Add("publisher", calls.stage("publisher", nil))
+	coordinator.Add("subscriber", calls.stage("subscriber", nil))
+	// When
+	shuttingDownBefore := coordinator.ShuttingDown()
+	err := coordinator.Shutdown(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.False(t, shuttingDownBefore)
+	assert.True(t, coordinator.ShuttingDown())
+	assert.Equal(t, []string{"consumer", "publisher", "subscriber"}, calls.names())
+}
+
+func TestShutdownContinuesAfterFailedStage(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	firstErr, secondErr := errors.New("first"), errors.New("second")
+	calls := new(callsRecorder)
+	coordinator := lifecycle.NewCoordinator(lifecycle.Settings{})
+	coordinator.Add("consumer", calls.stage("consumer", firstErr))
+	coordinator.Add("publisher", calls.stage("publisher", secondErr))
+	coordinator.Add("subscriber", calls.stage("subscriber", nil))
+	// When
+	err := coordinator.Shutdown(ctx)
+	again := coordinator.Shutdown(ctx)
+	// Then
+	assert.True(t, errors.Is(err, firstErr))
+	assert.Equal(t, "shutting down consumer: first", err.Error())
+	assert.Equal(t, err, again)
+	assert.Equal(t, []string{"consumer", "publisher", "subscriber"}, calls.names())
+}
+
+func TestShutdownDrainDelayHonoursDeadline(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+
+	defer cancel()
+
+	calls := new(callsRecorder)
+	coordinator := lifecycle.NewCoordinator(lifecycle.Settings{DrainDelay: time.Hour})
+	coordinator.Add("consumer", calls.stage("consumer", nil))
+	// When
+	err := coordinator.Shutdown(ctx)
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, []string{"consumer"}, calls.names())
+}
+
+func TestShutdownDrainsEventsInFlight(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	bus := memory.New()
+	eventBus := bus.Subscriber()
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	publisher := publishers.New(bus)
+	started, release := make(chan struct{}), make(chan struct{})
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: subscriber,
+		Handler: func(ctx context.Context, event messages.Event) error {
+			close(started)
+			<-release
+
+			return publisher.Publish(ctx, publishers.EventMessage{
+				ChannelName: "invoices-topic",
+				Event:       messages.Event{Header: messages.Header{ID: event.Header.ID}},
+			})
+		},
+	})
+	coordinator := lifecycle.NewCoordinator(lifecycle.Settings{})
+	coordinator.Add("consumer", consumer.Shutdown)
+	coordinator.Add("publisher", publisher.Close)
+	coordinator.Add("subscriber", subscriber.Close)
+	coordinator.Add("event bus", eventBus.Close)
+
+	go func() { _ = consumer.Run(ctx) }()
+
+	_ = bus.Publish(ctx, "orders-topic", messages.Event{Header: messages.Header{ID: "1"}})
+	<-started
+	// When
+	shutdown := make(chan error, 1)
+
+	go func() { shutdown <- coordinator.Shutdown(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	// Then
+	assert.NoError(t, <-shutdown)
+	assert.Len(t, bus.Events("invoices-topic"), 1)
+}
+
+type callsRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *callsRecorder) stage(name string, err error) lifecycle.ShutdownFunc {
+	return func(context.Context) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.calls = append(c.calls, name)
+
+		return err
+	}
+}
+
+func (c *callsRecorder) names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.calls...)
+}
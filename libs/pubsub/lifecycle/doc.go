@@ -0,0 +1,3 @@
+// Package lifecycle shuts down publishers, subscribers and consumers in order when the
+// service is stopping, so the events in flight are handled before the process exits.
+package lifecycle
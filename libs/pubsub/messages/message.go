@@ -1,5 +1,7 @@
 package messages
 
+import "github.com/pkg/errors"
+
 // Header contains event metadata.
 type Header struct {
 	ID          string // id correlation id.
@@ -8,6 +10,10 @@ type Header struct {
 	Version     string // Version it is the event type version.
 	Application string // AppName name of the sender application
 	MessageID   string // MessageID id used for message acknowledge.
+	OrderingKey string // OrderingKey events with the same key are delivered in order.
+	Channel     string // Channel the event was received from, set by the event bus.
+	// Attributes extra metadata carried along with the event, keys are lowercase alphanumeric.
+	Attributes map[string]string
 }
 
 // Event contains data related to the event.
@@ -15,3 +21,63 @@ type Event struct {
 	Header Header
 	Data   []byte
 }
+
+// Attribute returns the value of the given header attribute or an empty string.
+func (h Header) Attribute(key string) string {
+	return h.Attributes[key]
+}
+
+// WithAttribute returns a copy of the header with the given attribute set,
+// the original attributes map is not modified.
+func (h Header) WithAttribute(key, value string) Header {
+	attributes := make(map[string]string, len(h.Attributes)+1)
+	for k, v := range h.Attributes {
+		attributes[k] = v
+	}
+
+	attributes[key] = value
+	h.Attributes = attributes
+
+	return h
+}
+
+// WithoutAttribute returns a copy of the header without the given attribute,
+// the original attributes map is not modified.
+func (h Header) WithoutAttribute(key string) Header {
+	if _, ok := h.Attributes[key]; !ok {
+		return h
+	}
+
+	attributes := make(map[string]string, len(h.Attributes))
+
+	for k, v := range h.Attributes {
+		if k != key {
+			attributes[k] = v
+		}
+	}
+
+	if len(attributes) == 0 {
+		attributes = nil
+	}
+
+	h.Attributes = attributes
+
+	return h
+}
+
+// ErrUnsupportedMessage is returned when an event bus receives a message that is not an event.
+var ErrUnsupportedMessage = errors.New("message must be a messages.Event")
+
+// AsEvent returns the event carried by a message given to an event bus publisher.
+func AsEvent(message interface{}) (Event, error) {
+	switch event := message.(type) {
+	case Event:
+		return event, nil
+	case *Event:
+		if event != nil {
+			return *event, nil
+		}
+	}
+
+	return Event{}, errors.WithMessagef(ErrUnsupportedMessage, "got %T", message)
+}
@@ -0,0 +1,302 @@
+package publishers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BackpressurePolicy defines what the async publisher does when its buffer is full.
+type BackpressurePolicy int
+
+const (
+	// Block waits until there is room in the buffer or the context is done.
+	Block BackpressurePolicy = iota
+	// Drop discards the new event, its delivery fails with ErrEventDropped.
+	Drop
+	// Fail returns ErrBufferFull to the caller.
+	Fail
+)
+
+const (
+	defaultCapacity      = 1000
+	defaultFlushSize     = 100
+	defaultFlushInterval = time.Second
+)
+
+var (
+	// ErrBufferFull is returned by the Fail policy when the buffer is full.
+	ErrBufferFull = errors.New("publisher buffer is full")
+	// ErrEventDropped is the delivery error of events discarded by the Drop policy.
+	ErrEventDropped = errors.New("event dropped, publisher buffer is full")
+	// ErrPublisherClosed is returned when publishing into a closed publisher.
+	ErrPublisherClosed = errors.New("publisher is closed")
+)
+
+// AsyncSettings contains the async publisher configuration, zero values take defaults.
+type AsyncSettings struct {
+	// Capacity maximum number of buffered events, defaults to 1000.
+	Capacity int
+	// FlushSize number of buffered events that triggers a flush, defaults to 100.
+	FlushSize int
+	// FlushInterval maximum time an event waits in the buffer, defaults to 1s.
+	FlushInterval time.Duration
+	// Policy what to do when the buffer is full, defaults to Block.
+	Policy BackpressurePolicy
+	// OnDelivery optional callback called with the outcome of every event, it runs in the
+	// flushing goroutine so it must not block. Events dropped by the Drop policy are reported
+	// from the goroutine publishing them, so it may be called concurrently.
+	OnDelivery func(result PublishResult)
+}
+
+// Delivery is the future outcome of an event published asynchronously.
+type Delivery struct {
+	event EventMessage
+	done  chan struct{}
+	err   error
+}
+
+// AsyncPublisher buffers events in memory and publishes them in batches in the background.
+type AsyncPublisher struct {
+	publisher  *Publisher
+	settings   AsyncSettings
+	queue      chan *Delivery
+	flushes    chan chan struct{}
+	closing    chan struct{}
+	drain      chan struct{}
+	stopped    chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mu         sync.RWMutex
+	closed     bool
+	publishing sync.WaitGroup
+}
+
+// NewAsync instances a new async publisher and starts its flushing goroutine.
+func NewAsync(eventBus EventBusPublisher, settings AsyncSettings) *AsyncPublisher {
+	settings = withAsyncDefaults(settings)
+	ctx, cancel := context.WithCancel(context.Background())
+	newPublisher := AsyncPublisher{
+		publisher: New(eventBus),
+		settings:  settings,
+		queue:     make(chan *Delivery, settings.Capacity),
+		flushes:   make(chan chan struct{}),
+		closing:   make(chan struct{}),
+		drain:     make(chan struct{}),
+		stopped:   make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+
+	go newPublisher.run()
+
+	return &newPublisher
+}
+
+// Publish buffers the event and returns its delivery, what happens when the buffer is full
+// depends on the backpressure policy.
+func (a *AsyncPublisher) Publish(ctx context.Context, event EventMessage) (*Delivery, error) {
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+
+		return nil, ErrPublisherClosed
+	}
+
+	a.publishing.Add(1)
+	a.mu.RUnlock()
+
+	defer a.publishing.Done()
+
+	delivery := &Delivery{event: event, done: make(chan struct{})}
+
+	select {
+	case a.queue <- delivery:
+		return delivery, nil
+	default:
+	}
+
+	switch a.settings.Policy {
+	case Drop:
+		a.resolve(delivery, ErrEventDropped)
+
+		return delivery, nil
+	case Fail:
+		return nil, ErrBufferFull
+	}
+
+	select {
+	case a.queue <- delivery:
+		return delivery, nil
+	case <-a.closing:
+		return nil, ErrPublisherClosed
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "could not buffer event")
+	}
+}
+
+// Flush publishes the buffered events and waits for their deliveries.
+func (a *AsyncPublisher) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+
+	select {
+	case a.flushes <- done:
+	case <-a.stopped:
+		return ErrPublisherClosed
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "could not flush events")
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "could not flush events")
+	}
+}
+
+// Close stops accepting events and publishes every buffered event. When the context is done
+// before that, in flight publishing is cancelled and the pending deliveries fail. Every delivery
+// is resolved, and reported to OnDelivery, before Close returns.
+func (a *AsyncPublisher) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+
+		return ErrPublisherClosed
+	}
+
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.closing)
+	a.publishing.Wait()
+	close(a.drain)
+
+	select {
+	case <-a.stopped:
+		a.cancel()
+
+		return a.publisher.Close(ctx)
+	case <-ctx.Done():
+		a.cancel()
+		<-a.stopped
+
+		return errors.Wrap(ctx.Err(), "could not publish every buffered event")
+	}
+}
+
+// Done is closed once the event is published or failed.
+func (d *Delivery) Done() <-chan struct{} {
+	return d.done
+}
+
+// Err returns the delivery error, it must be called after Done is closed.
+func (d *Delivery) Err() error {
+	return d.err
+}
+
+// Wait waits for the delivery and returns its error.
+func (d *Delivery) Wait(ctx context.Context) error {
+	select {
+	case <-d.done:
+		return d.err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "delivery still pending")
+	}
+}
+
+// Event returns the event of the delivery.
+func (d *Delivery) Event() EventMessage {
+	return d.event
+}
+
+func (a *AsyncPublisher) run() {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(a.settings.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Delivery, 0, a.settings.FlushSize)
+
+	for {
+		select {
+		case delivery := <-a.queue:
+			batch = append(batch, delivery)
+			if len(batch) >= a.settings.FlushSize {
+				batch = a.flush(batch)
+			}
+		case <-ticker.C:
+			batch = a.flush(batch)
+		case done := <-a.flushes:
+			batch = a.flush(a.drainQueue(batch))
+			close(done)
+		case <-a.drain:
+			a.flush(a.drainQueue(batch))
+
+			return
+		}
+	}
+}
+
+// drainQueue appends every queued delivery to the batch without blocking.
+func (a *AsyncPublisher) drainQueue(batch []*Delivery) []*Delivery {
+	for {
+		select {
+		case delivery := <-a.queue:
+			batch = append(batch, delivery)
+		default:
+			return batch
+		}
+	}
+}
+
+// flush publishes the batch, resolves its deliveries and returns the batch emptied.
+func (a *AsyncPublisher) flush(batch []*Delivery) []*Delivery {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	events := make([]EventMessage, len(batch))
+	for idx, delivery := range batch {
+		events[idx] = delivery.event
+	}
+
+	results, _ := a.publisher.PublishBatch(a.ctx, events)
+	for idx, delivery := range batch {
+		a.resolve(delivery, results[idx].Err)
+	}
+
+	return batch[:0]
+}
+
+func (a *AsyncPublisher) resolve(delivery *Delivery, err error) {
+	delivery.err = err
+	close(delivery.done)
+
+	if a.settings.OnDelivery != nil {
+		a.settings.OnDelivery(PublishResult{Event: delivery.event, Err: err})
+	}
+}
+
+func withAsyncDefaults(settings AsyncSettings) AsyncSettings {
+	if settings.Capacity <= 0 {
+		settings.Capacity = defaultCapacity
+	}
+
+	if settings.FlushSize <= 0 {
+		settings.FlushSize = defaultFlushSize
+	}
+
+	if settings.FlushSize > settings.Capacity {
+		settings.FlushSize = settings.Capacity
+	}
+
+	if settings.FlushInterval <= 0 {
+		settings.FlushInterval = defaultFlushInterval
+	}
+
+	return settings
+}
@@ -0,0 +1,267 @@
+package publishers_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncPublishFlushesOnSize(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := new(recordingEventBusMock)
+	publisher := publishers.NewAsync(eventBus, publishers.AsyncSettings{
+		FlushSize:     2,
+		FlushInterval: time.Hour,
+	})
+	// When
+	first, err := publisher.Publish(ctx, batchEventFixture("orders-topic", "1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	second, err := publisher.Publish(ctx, batchEventFixture("orders-topic", "2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Then
+	assert.NoError(t, first.Wait(ctx))
+	assert.NoError(t, second.Wait(ctx))
+	assert.Equal(t, []string{"1", "2"}, eventBus.published())
+}
+
+func TestAsyncPublishFlushesOnInterval(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := new(recordingEventBusMock)
+	publisher := publishers.NewAsync(eventBus, publishers.AsyncSettings{
+		FlushSize:     100,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	// When
+	delivery, err := publisher.Publish(ctx, batchEventFixture("orders-topic", "1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Then
+	assert.NoError(t, delivery.Wait(ctx))
+	assert.Equal(t, []string{"1"}, eventBus.published())
+}
+
+func TestAsyncPublishReportsDeliveryErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	results := make(chan publishers.PublishResult, 1)
+	eventBus := new(eventBusMock).withError(errors.New("error"))
+	publisher := publishers.NewAsync(eventBus, publishers.AsyncSettings{
+		OnDelivery: func(result publishers.PublishResult) { results <- result },
+	})
+	// When
+	delivery, err := publisher.Publish(ctx, batchEventFixture("orders-topic", "1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	flushErr := publisher.Flush(ctx)
+	// Then
+	assert.NoError(t, flushErr)
+	assert.EqualError(t, delivery.Wait(ctx), "could not publish event: : error")
+	assert.Equal(t, "1", (<-results).Event.Event.Header.ID)
+}
+
+func TestAsyncPublishBackpressure(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		policy           publishers.BackpressurePolicy
+		expectedError    error
+		expectedDelivery error
+	}{
+		"fail": {
+			policy:        publishers.Fail,
+			expectedError: publishers.ErrBufferFull,
+		},
+		"drop": {
+			policy:           publishers.Drop,
+			expectedDelivery: publishers.ErrEventDropped,
+		},
+		"block": {
+			policy:        publishers.Block,
+			expectedError: context.DeadlineExceeded,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			eventBus := newBlockingEventBusMock()
+
+			defer eventBus.release()
+
+			publisher := publishers.NewAsync(eventBus, publishers.AsyncSettings{
+				Capacity:  1,
+				FlushSize: 1,
+				Policy:    test.policy,
+			})
+			ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+
+			defer cancel()
+
+			// the first event is being published, the second one fills the buffer.
+			_, _ = publisher.Publish(ctx, batchEventFixture("orders-topic", "1"))
+			<-eventBus.started
+			_, _ = publisher.Publish(ctx, batchEventFixture("orders-topic", "2"))
+			// When
+			delivery, err := publisher.Publish(ctx, batchEventFixture("orders-topic", "3"))
+			// Then
+			if test.expectedError != nil {
+				assert.True(t, errors.Is(err, test.expectedError), "got %v", err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, errors.Is(delivery.Wait(ctx), test.expectedDelivery))
+		})
+	}
+}
+
+func TestAsyncCloseFlushesBufferedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := new(recordingEventBusMock)
+	publisher := publishers.NewAsync(eventBus, publishers.AsyncSettings{FlushInterval: time.Hour})
+
+	for _, id := range []string{"1", "2", "3"} {
+		_, err := publisher.Publish(ctx, batchEventFixture("orders-topic", id))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	// When
+	err := publisher.Close(ctx)
+	_, publishErr := publisher.Publish(ctx, batchEventFixture("orders-topic", "4"))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, eventBus.published())
+	assert.True(t, errors.Is(publishErr, publishers.ErrPublisherClosed))
+}
+
+func TestAsyncCloseHonoursDeadline(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var reported int32
+
+	eventBus := newBlockingEventBusMock()
+	publisher := publishers.NewAsync(eventBus, publishers.AsyncSettings{
+		FlushSize:  1,
+		OnDelivery: func(publishers.PublishResult) { atomic.AddInt32(&reported, 1) },
+	})
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+
+	defer cancel()
+
+	delivery, err := publisher.Publish(context.TODO(), batchEventFixture("orders-topic", "1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	buffered, _ := publisher.Publish(context.TODO(), batchEventFixture("orders-topic", "2"))
+
+	<-eventBus.started
+	// When
+	err = publisher.Close(ctx)
+	reportedOnClose := atomic.LoadInt32(&reported)
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(2), reportedOnClose)
+	assert.True(t, errors.Is(delivery.Wait(context.TODO()), context.Canceled))
+	assert.Error(t, buffered.Wait(context.TODO()))
+}
+
+type recordingEventBusMock struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *recordingEventBusMock) Publish(_ context.Context, _ string, message interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	r.ids = append(r.ids, event.Header.ID)
+
+	return nil
+}
+
+func (r *recordingEventBusMock) published() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.ids...)
+}
+
+// blockingEventBusMock blocks publishing until it is released or the context is done.
+type blockingEventBusMock struct {
+	started  chan struct{}
+	released chan struct{}
+	once     sync.Once
+}
+
+func newBlockingEventBusMock() *blockingEventBusMock {
+	return &blockingEventBusMock{
+		started:  make(chan struct{}, 10),
+		released: make(chan struct{}),
+	}
+}
+
+func (b *blockingEventBusMock) Publish(ctx context.Context, _ string, _ interface{}) error {
+	b.started <- struct{}{}
+
+	select {
+	case <-b.released:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "publish cancelled")
+	}
+}
+
+func (b *blockingEventBusMock) release() {
+	b.once.Do(func() { close(b.released) })
+}
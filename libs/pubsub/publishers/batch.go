@@ -0,0 +1,151 @@
+package publishers
+
+import (
+	"context"
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+// BatchEventBusPublisher is implemented by event buses with a native batch publishing api,
+// like sqs SendMessageBatch, kinesis PutRecords or kafka producer batching.
+type BatchEventBusPublisher interface {
+	EventBusPublisher
+	// PublishBatch pushes the given events into the message channel, it returns one error per
+	// event in the same order, nil for the published ones.
+	PublishBatch(ctx context.Context, messageChannel string, messages []interface{}) []error
+	// MaxBatchSize maximum number of events per PublishBatch call, zero means no limit.
+	MaxBatchSize() int
+}
+
+// PublishResult contains the outcome of publishing an event of a batch.
+type PublishResult struct {
+	Event EventMessage
+	// Err is nil when the event was published.
+	Err error
+}
+
+var (
+	errBatchPartialFailure = errors.New("some events of the batch could not be published")
+	errInvalidBatchResult  = errors.New("event bus returned a wrong number of batch results")
+)
+
+// PublishBatch publishes the given events grouping them by channel, native batch apis are used
+// when the event bus has them. It returns a result per event in the same order as the given
+// events and an error when any of them failed, so callers can retry only the failed ones.
+func (p *Publisher) PublishBatch(ctx context.Context, events []EventMessage) ([]PublishResult, error) {
+	if !p.begin() {
+		return nil, ErrPublisherClosed
+	}
+
+	defer p.publishing.Done()
+
+	results := make([]PublishResult, len(events))
+	channels, groups := groupByChannel(events)
+
+	var failed int
+
+	for _, channel := range channels {
+		indexes := groups[channel]
+		batch := make([]interface{}, len(indexes))
+
+		for idx, eventIdx := range indexes {
+			batch[idx] = events[eventIdx].message()
+		}
+
+		errs := publishBatch(ctx, p.eventBus, channel, batch)
+
+		for idx, eventIdx := range indexes {
+			results[eventIdx] = PublishResult{Event: events[eventIdx]}
+			if errs[idx] == nil {
+				continue
+			}
+
+			failed++
+			results[eventIdx].Err = errors.Wrap(errs[idx], publishingErrorMessage)
+
+			log.Println(
+				"error", "something went wrong pushing event",
+				"content", events[eventIdx],
+				"method", "publishers.Publisher.PublishBatch",
+			)
+		}
+	}
+
+	if failed > 0 {
+		return results, errors.WithMessagef(errBatchPartialFailure, "%d of %d failed", failed, len(events))
+	}
+
+	return results, nil
+}
+
+// Failed returns the events of the results that could not be published.
+func Failed(results []PublishResult) []EventMessage {
+	var failed []EventMessage
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result.Event)
+		}
+	}
+
+	return failed
+}
+
+// publishBatch publishes the messages into the channel with the native batch api of the event
+// bus, split in chunks of its maximum batch size, or one by one when it has no batch api.
+func publishBatch(ctx context.Context, eventBus EventBusPublisher, channel string, batch []interface{}) []error {
+	batchPublisher, ok := eventBus.(BatchEventBusPublisher)
+	if !ok {
+		errs := make([]error, len(batch))
+		for idx, message := range batch {
+			errs[idx] = eventBus.Publish(ctx, channel, message)
+		}
+
+		return errs
+	}
+
+	chunkSize := batchPublisher.MaxBatchSize()
+	if chunkSize <= 0 {
+		chunkSize = len(batch)
+	}
+
+	errs := make([]error, 0, len(batch))
+
+	for start := 0; start < len(batch); start += chunkSize {
+		end := start + chunkSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+
+		chunkErrs := batchPublisher.PublishBatch(ctx, channel, batch[start:end])
+		if len(chunkErrs) != end-start {
+			chunkErrs = make([]error, end-start)
+			for idx := range chunkErrs {
+				chunkErrs[idx] = errInvalidBatchResult
+			}
+		}
+
+		errs = append(errs, chunkErrs...)
+	}
+
+	return errs
+}
+
+// groupByChannel returns the channel names in order of appearance and the indexes of the
+// events of every channel.
+func groupByChannel(events []EventMessage) ([]string, map[string][]int) {
+	var channels []string
+
+	groups := make(map[string][]int)
+
+	for idx, event := range events {
+		if _, ok := groups[event.ChannelName]; !ok {
+			channels = append(channels, event.ChannelName)
+		}
+
+		groups[event.ChannelName] = append(groups[event.ChannelName], idx)
+	}
+
+	return channels, groups
+}
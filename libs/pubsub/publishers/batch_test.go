@@ -0,0 +1,144 @@
+package publishers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishBatchUsesNativeBatches(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedCalls := []batchCall{
+		{channel: "orders-topic", ids: []string{"1", "2"}},
+		{channel: "orders-topic", ids: []string{"4"}},
+		{channel: "payments-topic", ids: []string{"3"}},
+	}
+	eventBus := &batchEventBusMock{maxBatchSize: 2}
+	publisher := publishers.New(eventBus)
+	events := []publishers.EventMessage{
+		batchEventFixture("orders-topic", "1"),
+		batchEventFixture("orders-topic", "2"),
+		batchEventFixture("payments-topic", "3"),
+		batchEventFixture("orders-topic", "4"),
+	}
+	// When
+	results, err := publisher.PublishBatch(context.TODO(), events)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCalls, eventBus.calls)
+	assert.Len(t, results, 4)
+	assert.Empty(t, publishers.Failed(results))
+}
+
+func TestPublishBatchReportsFailedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedFailed := []publishers.EventMessage{batchEventFixture("orders-topic", "2")}
+	eventBus := &batchEventBusMock{failIDs: map[string]bool{"2": true}}
+	publisher := publishers.New(eventBus)
+	events := []publishers.EventMessage{
+		batchEventFixture("orders-topic", "1"),
+		batchEventFixture("orders-topic", "2"),
+		batchEventFixture("payments-topic", "3"),
+	}
+	// When
+	results, err := publisher.PublishBatch(context.TODO(), events)
+	// Then
+	assert.EqualError(t, err, "1 of 3 failed: some events of the batch could not be published")
+	assert.Equal(t, expectedFailed, publishers.Failed(results))
+	assert.EqualError(t, results[1].Err, "could not publish event: throttled")
+	assert.Equal(t, events[2], results[2].Event)
+}
+
+func TestPublishBatchWithoutNativeBatches(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(eventBus)
+	events := []publishers.EventMessage{
+		batchEventFixture("orders-topic", "1"),
+		batchEventFixture("payments-topic", "2"),
+	}
+	// When
+	results, err := publisher.PublishBatch(context.TODO(), events)
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "payments-topic", eventBus.messageChannel)
+}
+
+func TestPublishBatchThroughMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedCalls := []batchCall{{channel: "orders-topic", ids: []string{"1", "2"}}}
+	eventBus := &batchEventBusMock{}
+	noop := publishers.Transform(
+		func(_ context.Context, _ string, event messages.Event) (messages.Event, error) {
+			return event, nil
+		},
+	)
+	publisher := publishers.New(publishers.Chain(eventBus, noop))
+	events := []publishers.EventMessage{
+		batchEventFixture("orders-topic", "1"),
+		batchEventFixture("orders-topic", "2"),
+	}
+	// When
+	_, err := publisher.PublishBatch(context.TODO(), events)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCalls, eventBus.calls)
+}
+
+type batchCall struct {
+	channel string
+	ids     []string
+}
+
+type batchEventBusMock struct {
+	maxBatchSize int
+	failIDs      map[string]bool
+	calls        []batchCall
+}
+
+func (b *batchEventBusMock) Publish(context.Context, string, interface{}) error {
+	return errors.New("batch event bus mock only publishes batches")
+}
+
+func (b *batchEventBusMock) PublishBatch(_ context.Context, channel string, batch []interface{}) []error {
+	call := batchCall{channel: channel}
+	errs := make([]error, len(batch))
+
+	for idx, message := range batch {
+		event, _ := messages.AsEvent(message)
+		call.ids = append(call.ids, event.Header.ID)
+
+		if b.failIDs[event.Header.ID] {
+			errs[idx] = errors.New("throttled")
+		}
+	}
+
+	b.calls = append(b.calls, call)
+
+	return errs
+}
+
+func (b *batchEventBusMock) MaxBatchSize() int {
+	return b.maxBatchSize
+}
+
+func batchEventFixture(channel, id string) publishers.EventMessage {
+	event := eventMessageFixture()
+	event.ChannelName = channel
+	event.Event.Header.ID = id
+
+	return event
+}
@@ -0,0 +1,124 @@
+package publishers
+
+import (
+	"context"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+)
+
+// Middleware decorates an event bus publisher with extra behavior.
+type Middleware func(EventBusPublisher) EventBusPublisher
+
+// TransformFunc changes an event before it is pushed into the event bus.
+type TransformFunc func(ctx context.Context, messageChannel string, event messages.Event) (messages.Event, error)
+
+// Chain wraps the event bus with the given middlewares, the first middleware is the
+// outermost one, so it is the first to see every published event.
+func Chain(eventBus EventBusPublisher, middlewares ...Middleware) EventBusPublisher {
+	for idx := len(middlewares) - 1; idx >= 0; idx-- {
+		eventBus = middlewares[idx](eventBus)
+	}
+
+	return eventBus
+}
+
+// Transform returns a middleware that applies the given function to every event before
+// publishing it, publishing is aborted when the function fails.
+func Transform(transform TransformFunc) Middleware {
+	return func(next EventBusPublisher) EventBusPublisher {
+		return &transformer{
+			next:      next,
+			transform: transform,
+		}
+	}
+}
+
+type transformer struct {
+	next      EventBusPublisher
+	transform TransformFunc
+}
+
+func (t *transformer) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	event, err = t.transform(ctx, messageChannel, event)
+	if err != nil {
+		return err
+	}
+
+	return t.next.Publish(ctx, messageChannel, event)
+}
+
+// PublishBatch transforms every message and keeps using the native batch api of the next event
+// bus, messages that fail to transform are not published.
+func (t *transformer) PublishBatch(ctx context.Context, messageChannel string, batch []interface{}) []error {
+	errs := make([]error, len(batch))
+	transformed := make([]interface{}, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+
+	for idx, message := range batch {
+		event, err := messages.AsEvent(message)
+		if err == nil {
+			event, err = t.transform(ctx, messageChannel, event)
+		}
+
+		if err != nil {
+			errs[idx] = err
+
+			continue
+		}
+
+		transformed = append(transformed, event)
+		indexes = append(indexes, idx)
+	}
+
+	for idx, err := range publishBatch(ctx, t.next, messageChannel, transformed) {
+		errs[indexes[idx]] = err
+	}
+
+	return errs
+}
+
+// MaxBatchSize returns the maximum batch size of the next event bus.
+func (t *transformer) MaxBatchSize() int {
+	if batchPublisher, ok := t.next.(BatchEventBusPublisher); ok {
+		return batchPublisher.MaxBatchSize()
+	}
+
+	return 0
+}
+
+// PublishDelayed transforms the message and keeps using the native delays of the next event bus.
+func (t *transformer) PublishDelayed(
+	ctx context.Context, messageChannel string, message interface{}, delay time.Duration,
+) error {
+	delayPublisher, ok := t.next.(DelayEventBusPublisher)
+	if !ok {
+		return errDelaysNotSupported
+	}
+
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	event, err = t.transform(ctx, messageChannel, event)
+	if err != nil {
+		return err
+	}
+
+	return delayPublisher.PublishDelayed(ctx, messageChannel, event, delay)
+}
+
+// MaxDelay returns the maximum delay of the next event bus.
+func (t *transformer) MaxDelay() time.Duration {
+	if delayPublisher, ok := t.next.(DelayEventBusPublisher); ok {
+		return delayPublisher.MaxDelay()
+	}
+
+	return 0
+}
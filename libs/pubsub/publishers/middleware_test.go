@@ -0,0 +1,57 @@
+package publishers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainOrder(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedData := []byte("first,second")
+	eventBus := new(eventBusMock)
+	appendData := func(value string) publishers.Middleware {
+		return publishers.Transform(
+			func(_ context.Context, _ string, event messages.Event) (messages.Event, error) {
+				if len(event.Data) > 0 {
+					value = "," + value
+				}
+
+				event.Data = append(event.Data, value...)
+
+				return event, nil
+			},
+		)
+	}
+	publisher := publishers.New(publishers.Chain(eventBus, appendData("first"), appendData("second")))
+	// When
+	err := publisher.Publish(context.TODO(), publishers.EventMessage{ChannelName: "orders-topic"})
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedData, eventBus.message.(messages.Event).Data)
+}
+
+func TestTransformError(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedError := "could not publish event: error"
+	eventBus := new(eventBusMock)
+	failing := publishers.Transform(
+		func(context.Context, string, messages.Event) (messages.Event, error) {
+			return messages.Event{}, errors.New("error")
+		},
+	)
+	publisher := publishers.New(publishers.Chain(eventBus, failing))
+	// When
+	err := publisher.Publish(context.TODO(), eventMessageFixture())
+	// Then
+	assert.EqualError(t, err, expectedError)
+	assert.Nil(t, eventBus.message)
+}
@@ -3,6 +3,7 @@ package publishers
 import (
 	"context"
 	"log"
+	"sync"
 
 	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
 	"github.com/pkg/errors"
@@ -20,11 +21,17 @@ type EventMessage struct {
 	ChannelName string
 	// Event event to publish into the event bus.
 	Event messages.Event
+	// OrderingKey events with the same key are consumed in the order they were published,
+	// it overrides the event header ordering key when it is set.
+	OrderingKey string
 }
 
 // Publisher define publishing data and logic.
 type Publisher struct {
-	eventBus EventBusPublisher
+	eventBus   EventBusPublisher
+	mu         sync.RWMutex
+	closed     bool
+	publishing sync.WaitGroup
 }
 
 const (
@@ -42,7 +49,13 @@ func New(eventBus EventBusPublisher) *Publisher {
 
 // Publish push given event into the given channel.
 func (p *Publisher) Publish(ctx context.Context, event EventMessage) error {
-	err := p.eventBus.Publish(ctx, event.ChannelName, event.Event)
+	if !p.begin() {
+		return ErrPublisherClosed
+	}
+
+	defer p.publishing.Done()
+
+	err := p.eventBus.Publish(ctx, event.ChannelName, event.message())
 	if err != nil {
 		log.Println(
 			"error", "something went wrong pushing event",
@@ -55,3 +68,54 @@ func (p *Publisher) Publish(ctx context.Context, event EventMessage) error {
 
 	return nil
 }
+
+// Close stops accepting events and waits for the ones being published. The event bus adapter
+// is not closed, it may be shared with other publishers.
+func (p *Publisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return ErrPublisherClosed
+	}
+
+	p.closed = true
+	p.mu.Unlock()
+
+	published := make(chan struct{})
+
+	go func() {
+		p.publishing.Wait()
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "could not wait for the events being published")
+	}
+}
+
+// begin registers a publishing call, it returns false once the publisher is closed.
+func (p *Publisher) begin() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+
+	p.publishing.Add(1)
+
+	return true
+}
+
+// message returns the event to push into the event bus carrying the ordering key.
+func (e EventMessage) message() messages.Event {
+	if e.OrderingKey != "" {
+		e.Event.Header.OrderingKey = e.OrderingKey
+	}
+
+	return e.Event
+}
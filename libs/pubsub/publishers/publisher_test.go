@@ -3,6 +3,7 @@ package publishers_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
 	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
@@ -70,6 +71,57 @@ func TestPublishFailed(t *testing.T) {
 	assert.Equal(t, expectedMessageChannel, eventBus.messageChannel)
 }
 
+func TestCloseWaitsForEventsBeingPublished(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := newBlockingEventBusMock()
+	publisher := publishers.New(eventBus)
+	published := make(chan error, 1)
+
+	go func() { published <- publisher.Publish(ctx, eventMessageFixture()) }()
+
+	<-eventBus.started
+	// When
+	closed := make(chan error, 1)
+
+	go func() { closed <- publisher.Close(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	eventBus.release()
+	// Then
+	assert.NoError(t, <-published)
+	assert.NoError(t, <-closed)
+	assert.True(t, errors.Is(publisher.Publish(ctx, eventMessageFixture()), publishers.ErrPublisherClosed))
+	assert.True(t, errors.Is(publisher.Close(ctx), publishers.ErrPublisherClosed))
+}
+
+func TestCloseHonoursDeadline(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := newBlockingEventBusMock()
+	publisher := publishers.New(eventBus)
+
+	defer eventBus.release()
+
+	go func() { _ = publisher.Publish(context.TODO(), eventMessageFixture()) }()
+
+	<-eventBus.started
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+
+	defer cancel()
+	// When
+	err := publisher.Close(ctx)
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
 type eventBusMock struct {
 	err            error
 	messageChannel string
@@ -107,3 +159,18 @@ func eventMessageFixture() publishers.EventMessage {
 
 	return event
 }
+
+func TestPublishWithOrderingKey(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	event := eventMessageFixture()
+	event.OrderingKey = "account-1"
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(eventBus)
+	// When
+	err := publisher.Publish(context.TODO(), event)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "account-1", eventBus.message.(messages.Event).Header.OrderingKey)
+}
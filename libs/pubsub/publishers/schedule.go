@@ -0,0 +1,233 @@
+package publishers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPollInterval      = time.Second
+	defaultScheduleBatchSize = 100
+	scheduleIDSize           = 16
+)
+
+var errDelaysNotSupported = errors.New("event bus does not support delays")
+
+// DelayEventBusPublisher is implemented by event buses that can hold an event back before
+// delivering it, like sqs DelaySeconds.
+type DelayEventBusPublisher interface {
+	EventBusPublisher
+	// PublishDelayed pushes the event into the message channel, consumers get it once the delay
+	// is over.
+	PublishDelayed(ctx context.Context, messageChannel string, message interface{}, delay time.Duration) error
+	// MaxDelay longest delay the event bus accepts, zero when it does not support delays.
+	MaxDelay() time.Duration
+}
+
+// SchedulerSettings contains the scheduler configuration.
+type SchedulerSettings struct {
+	// Store keeps the scheduled events until they are due.
+	Store ScheduleStore
+	// PollInterval how often the store is checked for due events, defaults to one second.
+	PollInterval time.Duration
+	// BatchSize maximum number of due events published per poll, defaults to 100.
+	BatchSize int
+	// NativeDelays hands the delays the event bus accepts over to it instead of the store.
+	// Events delayed by the event bus can not be cancelled, so only set it when saving the
+	// store trip matters more than cancelling.
+	NativeDelays bool
+	// Now returns the current time, defaults to time.Now.
+	Now func() time.Time
+}
+
+// Scheduler publishes events in the future. Events are kept in the store until Run publishes
+// them, at least once, when they are due. Short delays use the event bus native delays instead
+// when NativeDelays is set.
+// Only one scheduler must run against the same store, and events must be cancelled through it.
+type Scheduler struct {
+	eventBus     EventBusPublisher
+	publisher    *Publisher
+	store        ScheduleStore
+	pollInterval time.Duration
+	batchSize    int
+	native       bool
+	now          func() time.Time
+	mu           sync.Mutex
+	publishing   map[string]struct{} // ids of the due events being published.
+}
+
+// NewScheduler instances a new scheduler publishing into the event bus.
+func NewScheduler(eventBus EventBusPublisher, settings SchedulerSettings) *Scheduler {
+	newScheduler := Scheduler{
+		eventBus:     eventBus,
+		publisher:    New(eventBus),
+		store:        settings.Store,
+		pollInterval: settings.PollInterval,
+		batchSize:    settings.BatchSize,
+		native:       settings.NativeDelays,
+		now:          settings.Now,
+		publishing:   make(map[string]struct{}),
+	}
+
+	if newScheduler.pollInterval <= 0 {
+		newScheduler.pollInterval = defaultPollInterval
+	}
+
+	if newScheduler.batchSize <= 0 {
+		newScheduler.batchSize = defaultScheduleBatchSize
+	}
+
+	if newScheduler.now == nil {
+		newScheduler.now = time.Now
+	}
+
+	return &newScheduler
+}
+
+// PublishAfter schedules the event to be published once the delay is over, it returns the id
+// to cancel it with. The id is empty when the event can not be cancelled because it was
+// published right away or delayed by the event bus.
+func (s *Scheduler) PublishAfter(ctx context.Context, event EventMessage, delay time.Duration) (string, error) {
+	now := s.now()
+
+	return s.schedule(ctx, event, now.Add(delay), delay)
+}
+
+// PublishAt schedules the event to be published at the given time, it returns the id to
+// cancel it with as PublishAfter does. Events already due are published right away.
+func (s *Scheduler) PublishAt(ctx context.Context, event EventMessage, at time.Time) (string, error) {
+	return s.schedule(ctx, event, at, at.Sub(s.now()))
+}
+
+func (s *Scheduler) schedule(ctx context.Context, event EventMessage, at time.Time, delay time.Duration) (string, error) {
+	if delay <= 0 {
+		return "", s.publisher.Publish(ctx, event)
+	}
+
+	if delayPublisher, ok := s.eventBus.(DelayEventBusPublisher); ok && s.native && delay <= delayPublisher.MaxDelay() {
+		err := delayPublisher.PublishDelayed(ctx, event.ChannelName, event.message(), delay)
+		if err != nil {
+			return "", errors.Wrap(err, publishingErrorMessage)
+		}
+
+		return "", nil
+	}
+
+	id, err := newScheduleID()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.store.Save(ctx, ScheduledEvent{ID: id, Event: event, At: at})
+	if err != nil {
+		return "", errors.WithMessage(err, "could not schedule event")
+	}
+
+	return id, nil
+}
+
+// Cancel removes the scheduled event so it is not published, it fails with
+// ErrScheduleNotFound when the event was already published or it is being published.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.publishing[id]; ok {
+		return errors.WithMessagef(ErrScheduleNotFound, "could not cancel scheduled event %q, it is being published", id)
+	}
+
+	err := s.store.Delete(ctx, id)
+	if err != nil {
+		return errors.WithMessagef(err, "could not cancel scheduled event %q", id)
+	}
+
+	return nil
+}
+
+// Run publishes the due events every poll interval until the context is done. Events that
+// fail to publish stay in the store and are tried again on the next poll.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDue publishes the due events, they are claimed before publishing so they can not be
+// cancelled meanwhile and they leave the store once published.
+func (s *Scheduler) publishDue(ctx context.Context) {
+	due, err := s.claimDue(ctx)
+	if err != nil {
+		log.Println("error", err, "method", "publishers.Scheduler.publishDue")
+
+		return
+	}
+
+	for _, scheduled := range due {
+		err = s.publisher.Publish(ctx, scheduled.Event)
+		if err != nil {
+			log.Println("error", err, "schedule_id", scheduled.ID, "method", "publishers.Scheduler.publishDue")
+			s.release(scheduled.ID)
+
+			continue
+		}
+
+		err = s.store.Delete(ctx, scheduled.ID)
+		if err != nil && !errors.Is(err, ErrScheduleNotFound) {
+			log.Println("error", err, "schedule_id", scheduled.ID, "method", "publishers.Scheduler.publishDue")
+		}
+
+		s.release(scheduled.ID)
+	}
+}
+
+// claimDue returns the due events marked as being published.
+func (s *Scheduler) claimDue(ctx context.Context) ([]ScheduledEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due, err := s.store.Due(ctx, s.now(), s.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scheduled := range due {
+		s.publishing[scheduled.ID] = struct{}{}
+	}
+
+	return due, nil
+}
+
+// release lets the event be cancelled again, failed events are still in the store.
+func (s *Scheduler) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.publishing, id)
+}
+
+func newScheduleID() (string, error) {
+	id := make([]byte, scheduleIDSize)
+
+	_, err := io.ReadFull(rand.Reader, id)
+	if err != nil {
+		return "", errors.Wrap(err, "could not generate schedule id")
+	}
+
+	return hex.EncodeToString(id), nil
+}
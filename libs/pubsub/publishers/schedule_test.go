@@ -0,0 +1,264 @@
+package publishers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishAfterUsesNativeDelays(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := &delayEventBusMock{maxDelay: 15 * time.Minute}
+	store := publishers.NewMemoryScheduleStore()
+	scheduler := publishers.NewScheduler(eventBus, publishers.SchedulerSettings{Store: store, NativeDelays: true})
+	// When
+	shortID, errShort := scheduler.PublishAfter(context.TODO(), batchEventFixture("orders-topic", "1"), 5*time.Minute)
+	longID, errLong := scheduler.PublishAfter(context.TODO(), batchEventFixture("orders-topic", "2"), time.Hour)
+	due, _ := store.Due(context.TODO(), time.Now().Add(2*time.Hour), 0)
+	// Then
+	assert.NoError(t, errShort)
+	assert.NoError(t, errLong)
+	assert.Empty(t, shortID)
+	assert.NoError(t, scheduler.Cancel(context.TODO(), longID))
+	assert.Equal(t, map[string]time.Duration{"1": 5 * time.Minute}, eventBus.delayed)
+	assert.Len(t, due, 1)
+	assert.Equal(t, "2", due[0].Event.Event.Header.ID)
+}
+
+func TestPublishAfterKeepsEventsCancellableByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	eventBus := &delayEventBusMock{maxDelay: 15 * time.Minute}
+	store := publishers.NewMemoryScheduleStore()
+	scheduler := publishers.NewScheduler(eventBus, publishers.SchedulerSettings{Store: store})
+	// When
+	id, err := scheduler.PublishAfter(ctx, batchEventFixture("orders-topic", "1"), 5*time.Minute)
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, eventBus.delayed)
+	assert.NoError(t, scheduler.Cancel(ctx, id))
+}
+
+func TestSchedulerPublishesDueEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	now := newClock(time.Date(2022, time.August, 1, 10, 0, 0, 0, time.UTC))
+	eventBus := new(recordingEventBusMock)
+	scheduler := publishers.NewScheduler(eventBus, publishers.SchedulerSettings{
+		Store:        publishers.NewFileScheduleStore(t.TempDir()),
+		PollInterval: time.Millisecond,
+		Now:          now.get,
+	})
+	_, _ = scheduler.PublishAt(ctx, batchEventFixture("orders-topic", "1"), now.get().Add(time.Minute))
+	_, _ = scheduler.PublishAfter(ctx, batchEventFixture("orders-topic", "2"), time.Hour)
+	cancelled, _ := scheduler.PublishAfter(ctx, batchEventFixture("orders-topic", "3"), time.Minute)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		_ = scheduler.Run(ctx)
+	}()
+	// When
+	errCancel := scheduler.Cancel(ctx, cancelled)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, eventBus.published())
+	now.add(30 * time.Minute)
+	// Then
+	assert.NoError(t, errCancel)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"1"}, eventBus.published())
+	}, time.Second, time.Millisecond)
+	assert.True(t, errors.Is(scheduler.Cancel(ctx, cancelled), publishers.ErrScheduleNotFound))
+	cancel()
+	<-stopped
+}
+
+func TestSchedulerPublishesPastEventsRightAway(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(recordingEventBusMock)
+	scheduler := publishers.NewScheduler(eventBus, publishers.SchedulerSettings{
+		Store: publishers.NewMemoryScheduleStore(),
+	})
+	// When
+	_, err := scheduler.PublishAt(context.TODO(), batchEventFixture("orders-topic", "1"), time.Now().Add(-time.Second))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, eventBus.published())
+}
+
+func TestSchedulerCancelFailsWhileTheEventIsPublished(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	now := newClock(time.Date(2022, time.August, 1, 10, 0, 0, 0, time.UTC))
+	eventBus := newBlockingEventBusMock()
+	scheduler := publishers.NewScheduler(eventBus, publishers.SchedulerSettings{
+		Store:        publishers.NewMemoryScheduleStore(),
+		PollInterval: time.Millisecond,
+		Now:          now.get,
+	})
+	id, _ := scheduler.PublishAfter(ctx, batchEventFixture("orders-topic", "1"), time.Minute)
+	now.add(time.Hour)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		_ = scheduler.Run(ctx)
+	}()
+	// When
+	<-eventBus.started
+
+	err := scheduler.Cancel(ctx, id)
+
+	eventBus.release()
+	// Then
+	assert.True(t, errors.Is(err, publishers.ErrScheduleNotFound))
+	assert.Eventually(t, func() bool {
+		return errors.Is(scheduler.Cancel(ctx, id), publishers.ErrScheduleNotFound)
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-stopped
+}
+
+func TestSchedulerRetriesFailedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	now := newClock(time.Date(2022, time.August, 1, 10, 0, 0, 0, time.UTC))
+	eventBus := &failingOnceEventBusMock{recordingEventBusMock: new(recordingEventBusMock)}
+	scheduler := publishers.NewScheduler(eventBus, publishers.SchedulerSettings{
+		Store:        publishers.NewMemoryScheduleStore(),
+		PollInterval: time.Millisecond,
+		Now:          now.get,
+	})
+	_, _ = scheduler.PublishAfter(ctx, batchEventFixture("orders-topic", "1"), time.Minute)
+	now.add(time.Hour)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		_ = scheduler.Run(ctx)
+	}()
+	// When
+	published := func() bool {
+		return assert.ObjectsAreEqual([]string{"1"}, eventBus.published())
+	}
+	// Then
+	assert.Eventually(t, published, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, published())
+	cancel()
+	<-stopped
+}
+
+func TestFileScheduleStoreRejectsInvalidIDs(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	store := publishers.NewFileScheduleStore(t.TempDir())
+	// When
+	err := store.Delete(context.TODO(), "../orders")
+	// Then
+	assert.True(t, errors.Is(err, publishers.ErrScheduleNotFound))
+}
+
+type delayEventBusMock struct {
+	recordingEventBusMock
+	maxDelay time.Duration
+	delayed  map[string]time.Duration
+}
+
+func (d *delayEventBusMock) PublishDelayed(
+	_ context.Context, _ string, message interface{}, delay time.Duration,
+) error {
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	if d.delayed == nil {
+		d.delayed = make(map[string]time.Duration)
+	}
+
+	d.delayed[event.Header.ID] = delay
+
+	return nil
+}
+
+func (d *delayEventBusMock) MaxDelay() time.Duration {
+	return d.maxDelay
+}
+
+// clock is a settable time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock(now time.Time) *clock {
+	return &clock{now: now}
+}
+
+func (c *clock) get() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) add(duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(duration)
+}
+
+// failingOnceEventBusMock fails the first publish and records the next ones.
+type failingOnceEventBusMock struct {
+	*recordingEventBusMock
+	once sync.Once
+}
+
+func (f *failingOnceEventBusMock) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	failed := false
+
+	f.once.Do(func() { failed = true })
+
+	if failed {
+		return errors.New("event bus unavailable")
+	}
+
+	return f.recordingEventBusMock.Publish(ctx, messageChannel, message)
+}
@@ -0,0 +1,194 @@
+package publishers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const scheduleFileExtension = ".json"
+
+// ErrScheduleNotFound is returned when the scheduled event does not exist, it was already
+// published or cancelled.
+var ErrScheduleNotFound = errors.New("scheduled event not found")
+
+// ScheduledEvent is an event waiting in the store to be published.
+type ScheduledEvent struct {
+	ID    string
+	Event EventMessage
+	At    time.Time
+}
+
+// ScheduleStore keeps the scheduled events, a durable store keeps them across restarts.
+type ScheduleStore interface {
+	Save(ctx context.Context, event ScheduledEvent) error
+	// Delete removes the scheduled event or fails with ErrScheduleNotFound.
+	Delete(ctx context.Context, id string) error
+	// Due returns up to limit events scheduled at or before the given time, oldest first.
+	Due(ctx context.Context, until time.Time, limit int) ([]ScheduledEvent, error)
+}
+
+// MemoryScheduleStore keeps the scheduled events in memory, they are lost on restart.
+type MemoryScheduleStore struct {
+	mu     sync.Mutex
+	events map[string]ScheduledEvent
+}
+
+// NewMemoryScheduleStore instances a new in-memory schedule store.
+func NewMemoryScheduleStore() *MemoryScheduleStore {
+	return &MemoryScheduleStore{events: make(map[string]ScheduledEvent)}
+}
+
+// Save keeps the scheduled event.
+func (m *MemoryScheduleStore) Save(_ context.Context, event ScheduledEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events[event.ID] = event
+
+	return nil
+}
+
+// Delete removes the scheduled event.
+func (m *MemoryScheduleStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.events[id]; !ok {
+		return errors.WithMessagef(ErrScheduleNotFound, "%q", id)
+	}
+
+	delete(m.events, id)
+
+	return nil
+}
+
+// Due returns the due events, oldest first.
+func (m *MemoryScheduleStore) Due(_ context.Context, until time.Time, limit int) ([]ScheduledEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []ScheduledEvent
+
+	for _, event := range m.events {
+		if !event.At.After(until) {
+			result = append(result, event)
+		}
+	}
+
+	return oldest(result, limit), nil
+}
+
+// FileScheduleStore keeps every scheduled event in its own json file of the directory.
+type FileScheduleStore struct {
+	dir string
+}
+
+// NewFileScheduleStore instances a new schedule store in the given directory.
+func NewFileScheduleStore(dir string) *FileScheduleStore {
+	return &FileScheduleStore{dir: dir}
+}
+
+// Save writes the scheduled event file, it is written to a temporary file and renamed so a
+// crash never leaves half written events.
+func (f *FileScheduleStore) Save(_ context.Context, event ScheduledEvent) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not encode scheduled event")
+	}
+
+	path, err := f.path(event.ID)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path+".tmp", content, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "could not write scheduled event")
+	}
+
+	return errors.Wrap(os.Rename(path+".tmp", path), "could not write scheduled event")
+}
+
+// Delete removes the scheduled event file.
+func (f *FileScheduleStore) Delete(_ context.Context, id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return errors.WithMessagef(ErrScheduleNotFound, "%q", id)
+	}
+
+	return errors.Wrap(err, "could not delete scheduled event")
+}
+
+// Due reads every scheduled event file and returns the due ones, oldest first.
+func (f *FileScheduleStore) Due(_ context.Context, until time.Time, limit int) ([]ScheduledEvent, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list scheduled events")
+	}
+
+	var result []ScheduledEvent
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), scheduleFileExtension) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(f.dir, entry.Name()))
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read scheduled event")
+		}
+
+		var event ScheduledEvent
+
+		err = json.Unmarshal(content, &event)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode scheduled event %q", entry.Name())
+		}
+
+		if !event.At.After(until) {
+			result = append(result, event)
+		}
+	}
+
+	return oldest(result, limit), nil
+}
+
+// path returns the file of the scheduled event, ids are generated hex strings so anything
+// else is rejected to keep files inside the directory.
+func (f *FileScheduleStore) path(id string) (string, error) {
+	if id == "" || strings.Trim(id, "0123456789abcdef") != "" {
+		return "", errors.WithMessagef(ErrScheduleNotFound, "%q", id)
+	}
+
+	return filepath.Join(f.dir, id+scheduleFileExtension), nil
+}
+
+// oldest sorts the events by time and returns up to limit of them.
+func oldest(events []ScheduledEvent, limit int) []ScheduledEvent {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events
+}
@@ -0,0 +1,3 @@
+// Package ratelimit limits the rate of published events per channel and of handled events per
+// handler with token buckets, limits can be changed at runtime and wait times are reported.
+package ratelimit
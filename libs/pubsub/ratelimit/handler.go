@@ -0,0 +1,22 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+)
+
+// Handler returns a consumer handler that waits for the limit of the given name before
+// handling every event, events whose context is done while waiting fail and are delivered
+// again.
+func (l *Limiter) Handler(name string, handler consumers.Handler) consumers.Handler {
+	return func(ctx context.Context, event messages.Event) error {
+		err := l.Wait(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		return handler(ctx, event)
+	}
+}
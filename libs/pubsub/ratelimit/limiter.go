@@ -0,0 +1,261 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Limit token bucket limit.
+type Limit struct {
+	// Rate events per second, there is no limit when it is zero.
+	Rate float64
+	// Burst events allowed at once above the rate, at least one.
+	Burst int
+}
+
+// PerSecond returns the limit of the given events per second with a burst of one.
+func PerSecond(events float64) Limit {
+	return Limit{Rate: events, Burst: 1}
+}
+
+// Every returns the limit of one event every interval with a burst of one.
+func Every(interval time.Duration) Limit {
+	return Limit{Rate: float64(time.Second) / float64(interval), Burst: 1}
+}
+
+// Settings contains the limiter configuration.
+type Settings struct {
+	// Default limit of the keys without a limit of their own, no limit when it is zero.
+	Default Limit
+	// Limits limit by key, channel names for publishers and handler names for consumers.
+	Limits map[string]Limit
+	// OnWait optional callback called with the time every event waited for its turn, zero
+	// included, to report it as a metric.
+	OnWait func(key string, wait time.Duration)
+}
+
+// Stats wait statistics of a key.
+type Stats struct {
+	// Events that went through the limiter.
+	Events int
+	// Delayed events that had to wait.
+	Delayed int
+	// TotalWait and MaxWait of all events.
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// Limiter keeps a token bucket by key, it is safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	fallback Limit
+	limits   map[string]Limit
+	buckets  map[string]*bucket
+	stats    map[string]Stats
+	onWait   func(key string, wait time.Duration)
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// New instances a new limiter.
+func New(settings Settings) *Limiter {
+	limits := make(map[string]Limit, len(settings.Limits))
+	for key, limit := range settings.Limits {
+		limits[key] = limit
+	}
+
+	newLimiter := Limiter{
+		fallback: settings.Default,
+		limits:   limits,
+		buckets:  make(map[string]*bucket),
+		stats:    make(map[string]Stats),
+		onWait:   settings.OnWait,
+	}
+
+	return &newLimiter
+}
+
+// Wait blocks until the key allows one more event or the context is done.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	return l.WaitN(ctx, key, 1)
+}
+
+// WaitN blocks until the key allows n more events or the context is done. Batches larger than
+// the burst are allowed, the following events wait until the bucket recovers.
+func (l *Limiter) WaitN(ctx context.Context, key string, n int) error {
+	l.mu.Lock()
+	limitedBucket := l.bucket(key)
+
+	var wait time.Duration
+	if limitedBucket != nil {
+		wait = limitedBucket.reserve(time.Now(), n)
+	}
+	l.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			limitedBucket.tokens += float64(n)
+			l.mu.Unlock()
+
+			return errors.Wrapf(ctx.Err(), "rate limit of %q", key)
+		case <-timer.C:
+		}
+	}
+
+	l.record(key, n, wait)
+
+	return nil
+}
+
+// SetLimit changes the limit of the key at runtime, a zero limit removes it so the key gets the
+// default limit again.
+func (l *Limiter) SetLimit(key string, limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit == (Limit{}) {
+		delete(l.limits, key)
+	} else {
+		l.limits[key] = limit
+	}
+
+	l.adjust(key, l.limitOf(key))
+}
+
+// SetDefault changes the limit of the keys without a limit of their own at runtime.
+func (l *Limiter) SetDefault(limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fallback = limit
+
+	for key := range l.buckets {
+		if _, ok := l.limits[key]; !ok {
+			l.adjust(key, limit)
+		}
+	}
+}
+
+// Limit returns the current limit of the key.
+func (l *Limiter) Limit(key string) Limit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limitOf(key)
+}
+
+// Stats returns the wait statistics of the key.
+func (l *Limiter) Stats(key string) Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats[key]
+}
+
+func (l *Limiter) limitOf(key string) Limit {
+	if limit, ok := l.limits[key]; ok {
+		return limit
+	}
+
+	return l.fallback
+}
+
+// bucket returns the bucket of the key, nil when it has no limit. The lock must be held.
+func (l *Limiter) bucket(key string) *bucket {
+	limit := l.limitOf(key)
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	found, ok := l.buckets[key]
+	if !ok {
+		found = &bucket{limit: normalize(limit), tokens: float64(normalize(limit).Burst), last: time.Now()}
+		l.buckets[key] = found
+	}
+
+	return found
+}
+
+// adjust applies the new limit to the bucket of the key keeping its tokens, the lock must be
+// held.
+func (l *Limiter) adjust(key string, limit Limit) {
+	found, ok := l.buckets[key]
+	if !ok {
+		return
+	}
+
+	if limit.Rate <= 0 {
+		delete(l.buckets, key)
+
+		return
+	}
+
+	found.advance(time.Now())
+	found.limit = normalize(limit)
+	found.tokens = math.Min(found.tokens, float64(found.limit.Burst))
+}
+
+func (l *Limiter) record(key string, n int, wait time.Duration) {
+	l.mu.Lock()
+	stats := l.stats[key]
+	stats.Events += n
+
+	if wait > 0 {
+		stats.Delayed += n
+		stats.TotalWait += wait
+
+		if wait > stats.MaxWait {
+			stats.MaxWait = wait
+		}
+	}
+
+	l.stats[key] = stats
+	l.mu.Unlock()
+
+	if l.onWait != nil {
+		l.onWait(key, wait)
+	}
+}
+
+func normalize(limit Limit) Limit {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	return limit
+}
+
+// reserve takes n tokens, the bucket may go into debt, and returns the time until they are
+// available.
+func (b *bucket) reserve(now time.Time, n int) time.Duration {
+	b.advance(now)
+	b.tokens -= float64(n)
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// advance adds the tokens earned since the last update, up to the burst.
+func (b *bucket) advance(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
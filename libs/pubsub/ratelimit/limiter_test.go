@@ -0,0 +1,167 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/ratelimit"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublisherLimitsEveryChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	bus := memory.New()
+	limiter := ratelimit.New(ratelimit.Settings{
+		Limits: map[string]ratelimit.Limit{"exports": {Rate: 50, Burst: 2}},
+	})
+	publisher := publishers.New(publishers.Chain(bus, limiter.Publisher()))
+	start := time.Now()
+	// When
+	for idx := 0; idx < 4; idx++ {
+		_ = publisher.Publish(context.TODO(), eventMessageFixture("exports"))
+		_ = publisher.Publish(context.TODO(), eventMessageFixture("orders"))
+	}
+	// Then
+	elapsed := time.Since(start)
+	stats := limiter.Stats("exports")
+	assert.GreaterOrEqual(t, elapsed, 30*time.Millisecond, "two events over the burst at 50 per second")
+	assert.Less(t, elapsed, 500*time.Millisecond)
+	assert.Len(t, bus.Events("exports"), 4)
+	assert.Len(t, bus.Events("orders"), 4)
+	assert.Equal(t, 4, stats.Events)
+	assert.Equal(t, 2, stats.Delayed)
+	assert.Greater(t, stats.MaxWait, time.Duration(0))
+	assert.Equal(t, ratelimit.Stats{Events: 4}, limiter.Stats("orders"))
+}
+
+func TestWaitHonoursContext(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	limiter := ratelimit.New(ratelimit.Settings{Default: ratelimit.Every(time.Hour)})
+	ctx, cancel := context.WithTimeout(context.TODO(), 20*time.Millisecond)
+
+	defer cancel()
+
+	_ = limiter.Wait(ctx, "exports")
+	// When
+	err := limiter.Wait(ctx, "exports")
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), `rate limit of "exports"`)
+}
+
+func TestSetLimitAtRuntime(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	limiter := ratelimit.New(ratelimit.Settings{Default: ratelimit.Every(time.Hour)})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+
+	defer cancel()
+
+	_ = limiter.Wait(ctx, "exports")
+	// When
+	limiter.SetLimit("exports", ratelimit.PerSecond(1000))
+	err := limiter.Wait(ctx, "exports")
+	limiter.SetDefault(ratelimit.PerSecond(10))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.PerSecond(1000), limiter.Limit("exports"))
+	assert.Equal(t, ratelimit.PerSecond(10), limiter.Limit("orders"))
+}
+
+func TestSetZeroLimitFallsBackToDefault(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	limiter := ratelimit.New(ratelimit.Settings{
+		Default: ratelimit.PerSecond(1000),
+		Limits:  map[string]ratelimit.Limit{"exports": ratelimit.Every(time.Hour)},
+	})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+
+	defer cancel()
+
+	_ = limiter.Wait(ctx, "exports")
+	// When
+	limiter.SetLimit("exports", ratelimit.Limit{})
+	err := limiter.Wait(ctx, "exports")
+	limitAfterRemoval := limiter.Limit("exports")
+	limiter.SetDefault(ratelimit.PerSecond(10))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, ratelimit.PerSecond(1000), limitAfterRemoval)
+	assert.Equal(t, ratelimit.PerSecond(10), limiter.Limit("exports"))
+}
+
+func TestHandlerReportsWaits(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var (
+		mu    sync.Mutex
+		waits []time.Duration
+	)
+
+	limiter := ratelimit.New(ratelimit.Settings{
+		Limits: map[string]ratelimit.Limit{"exporter": ratelimit.PerSecond(100)},
+		OnWait: func(key string, wait time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, "exporter", key)
+
+			waits = append(waits, wait)
+		},
+	})
+
+	var handled int
+
+	handler := limiter.Handler("exporter", func(context.Context, messages.Event) error {
+		handled++
+
+		return nil
+	})
+	// When
+	for idx := 0; idx < 3; idx++ {
+		_ = handler(context.TODO(), eventMessageFixture("exports").Event)
+	}
+	// Then
+	assert.Equal(t, 3, handled)
+	assert.Len(t, waits, 3)
+	assert.Zero(t, waits[0])
+	assert.Greater(t, waits[1], time.Duration(0))
+}
+
+func TestPublishBatchWaitsForTheWholeBatch(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	limiter := ratelimit.New(ratelimit.Settings{Default: ratelimit.Limit{Rate: 1000, Burst: 10}})
+	publisher := publishers.New(publishers.Chain(memory.New(), limiter.Publisher()))
+	events := []publishers.EventMessage{eventMessageFixture("exports"), eventMessageFixture("exports")}
+	// When
+	_, err := publisher.PublishBatch(context.TODO(), events)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, limiter.Stats("exports").Events)
+}
+
+func eventMessageFixture(channel string) publishers.EventMessage {
+	return publishers.EventMessage{
+		ChannelName: channel,
+		Event: messages.Event{
+			Header: messages.Header{ID: "1", Domain: "loans", EventType: "exported", Version: "1"},
+			Data:   []byte(`{}`),
+		},
+	}
+}
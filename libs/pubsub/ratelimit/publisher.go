@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+)
+
+var errDelaysNotSupported = errors.New("event bus does not support delays")
+
+// Publisher returns a publisher middleware that limits the events published into every
+// channel, keyed by channel name. Native batches and delays of the event bus are kept.
+func (l *Limiter) Publisher() publishers.Middleware {
+	return func(next publishers.EventBusPublisher) publishers.EventBusPublisher {
+		return &publisher{next: next, limiter: l}
+	}
+}
+
+type publisher struct {
+	next    publishers.EventBusPublisher
+	limiter *Limiter
+}
+
+func (p *publisher) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	err := p.limiter.Wait(ctx, messageChannel)
+	if err != nil {
+		return err
+	}
+
+	return p.next.Publish(ctx, messageChannel, message)
+}
+
+// PublishBatch waits for the whole batch and publishes it with the native batch api when the
+// event bus has one.
+func (p *publisher) PublishBatch(ctx context.Context, messageChannel string, batch []interface{}) []error {
+	errs := make([]error, len(batch))
+
+	batchPublisher, ok := p.next.(publishers.BatchEventBusPublisher)
+	if !ok {
+		for idx, message := range batch {
+			errs[idx] = p.Publish(ctx, messageChannel, message)
+		}
+
+		return errs
+	}
+
+	err := p.limiter.WaitN(ctx, messageChannel, len(batch))
+	if err != nil {
+		for idx := range errs {
+			errs[idx] = err
+		}
+
+		return errs
+	}
+
+	return batchPublisher.PublishBatch(ctx, messageChannel, batch)
+}
+
+// MaxBatchSize returns the maximum batch size of the event bus.
+func (p *publisher) MaxBatchSize() int {
+	if batchPublisher, ok := p.next.(publishers.BatchEventBusPublisher); ok {
+		return batchPublisher.MaxBatchSize()
+	}
+
+	return 0
+}
+
+// PublishDelayed waits for the channel limit and publishes the delayed message.
+func (p *publisher) PublishDelayed(
+	ctx context.Context, messageChannel string, message interface{}, delay time.Duration,
+) error {
+	delayPublisher, ok := p.next.(publishers.DelayEventBusPublisher)
+	if !ok {
+		return errDelaysNotSupported
+	}
+
+	err := p.limiter.Wait(ctx, messageChannel)
+	if err != nil {
+		return err
+	}
+
+	return delayPublisher.PublishDelayed(ctx, messageChannel, message, delay)
+}
+
+// MaxDelay returns the maximum delay of the event bus.
+func (p *publisher) MaxDelay() time.Duration {
+	if delayPublisher, ok := p.next.(publishers.DelayEventBusPublisher); ok {
+		return delayPublisher.MaxDelay()
+	}
+
+	return 0
+}
@@ -0,0 +1,3 @@
+// Package requestreply provides request/reply messaging on top of publishers and subscribers,
+// requests carry the channel to reply to and replies the id of the request they answer.
+package requestreply
@@ -0,0 +1,45 @@
+package requestreply
+
+import (
+	"context"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/pkg/errors"
+)
+
+var errNoReplyChannel = errors.New("request has no reply channel")
+
+// Responder answers a request with its reply event.
+type Responder func(ctx context.Context, request messages.Event) (messages.Event, error)
+
+// Reply publishes the reply into the channel of the request, correlated by the request id.
+func Reply(ctx context.Context, publisher *publishers.Publisher, request, reply messages.Event) error {
+	replyChannel := request.Header.Attribute(ReplyToAttribute)
+	if replyChannel == "" {
+		return errors.WithMessagef(errNoReplyChannel, "%q", request.Header.ID)
+	}
+
+	reply.Header = reply.Header.WithAttribute(CorrelationIDAttribute, request.Header.ID)
+
+	err := publisher.Publish(ctx, publishers.EventMessage{ChannelName: replyChannel, Event: reply})
+	if err != nil {
+		return errors.WithMessagef(err, "could not reply to request %q", request.Header.ID)
+	}
+
+	return nil
+}
+
+// Handler returns a consumer handler that answers every request with the responder, requests
+// are acknowledged once their reply is published.
+func Handler(publisher *publishers.Publisher, respond Responder) consumers.Handler {
+	return func(ctx context.Context, request messages.Event) error {
+		reply, err := respond(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		return Reply(ctx, publisher, request, reply)
+	}
+}
@@ -0,0 +1,304 @@
+package requestreply
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReplyToAttribute header attribute with the channel the reply must be published into.
+	ReplyToAttribute = "replyto"
+	// CorrelationIDAttribute header attribute with the id of the request a reply answers.
+	CorrelationIDAttribute = "correlationid"
+
+	// DefaultTimeout time to wait for a reply when no timeout is configured.
+	DefaultTimeout = 30 * time.Second
+	// DefaultReplyChannelPrefix prefix of the per request reply channels.
+	DefaultReplyChannelPrefix = "reply."
+
+	replyChannelSuffixSize = 8
+)
+
+var (
+	// ErrRequesterClosed is returned by requests made after the requester is closed.
+	ErrRequesterClosed = errors.New("requester is closed")
+
+	errMissingRequestID = errors.New("requests must have an id")
+	errStreamClosed     = errors.New("reply stream closed")
+	errNoReplySource    = errors.New("either a reply channel with a subscriber or a subscriber factory is required")
+)
+
+// Settings contains the requester configuration. Set ReplyChannel and Subscriber to share a
+// reply channel between every request, or NewSubscriber to use a channel per request.
+type Settings struct {
+	Publisher *publishers.Publisher
+	// ReplyChannel shared channel replies are published into.
+	ReplyChannel string
+	// Subscriber already subscribed to the shared reply channel.
+	Subscriber *subscribers.Subscriber
+	// NewSubscriber returns a new subscriber for the reply channel of a single request, the
+	// event bus must create channels on subscribe.
+	NewSubscriber func() *subscribers.Subscriber
+	// ReplyChannelPrefix prefix of the per request reply channels, DefaultReplyChannelPrefix
+	// when it is empty.
+	ReplyChannelPrefix string
+	// Timeout time to wait for a reply when the context has no earlier deadline,
+	// DefaultTimeout when it is zero.
+	Timeout time.Duration
+}
+
+// Requester publishes requests and waits for their replies.
+type Requester struct {
+	publisher     *publishers.Publisher
+	replyChannel  string
+	subscriber    *subscribers.Subscriber
+	newSubscriber func() *subscribers.Subscriber
+	prefix        string
+	timeout       time.Duration
+	start         sync.Once
+	startErr      error
+	mu            sync.Mutex
+	pending       map[string]chan messages.Event // reply waiters by request id.
+	closed        bool
+	cancel        context.CancelFunc
+	stopped       chan struct{}
+}
+
+// New instances a new requester.
+func New(settings Settings) (*Requester, error) {
+	if (settings.ReplyChannel == "" || settings.Subscriber == nil) && settings.NewSubscriber == nil {
+		return nil, errNoReplySource
+	}
+
+	newRequester := Requester{
+		publisher:     settings.Publisher,
+		replyChannel:  settings.ReplyChannel,
+		subscriber:    settings.Subscriber,
+		newSubscriber: settings.NewSubscriber,
+		prefix:        settings.ReplyChannelPrefix,
+		timeout:       settings.Timeout,
+		pending:       make(map[string]chan messages.Event),
+		stopped:       make(chan struct{}),
+	}
+
+	if newRequester.prefix == "" {
+		newRequester.prefix = DefaultReplyChannelPrefix
+	}
+
+	if newRequester.timeout <= 0 {
+		newRequester.timeout = DefaultTimeout
+	}
+
+	return &newRequester, nil
+}
+
+// Request publishes the request and waits for its reply until the timeout or the context is
+// done. The request header id correlates the reply, so it must be unique.
+func (r *Requester) Request(ctx context.Context, request publishers.EventMessage) (messages.Event, error) {
+	if request.Event.Header.ID == "" {
+		return messages.Event{}, errMissingRequestID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if r.replyChannel == "" || r.subscriber == nil {
+		return r.requestOnOwnChannel(ctx, request)
+	}
+
+	return r.requestOnSharedChannel(ctx, request)
+}
+
+// Close stops listening to the shared reply channel, waiting requests fail.
+func (r *Requester) Close() {
+	r.mu.Lock()
+	r.closed = true
+	cancel := r.cancel
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-r.stopped
+	}
+}
+
+func (r *Requester) requestOnSharedChannel(ctx context.Context, request publishers.EventMessage) (messages.Event, error) {
+	r.start.Do(r.listen)
+
+	if r.startErr != nil {
+		return messages.Event{}, r.startErr
+	}
+
+	id := request.Event.Header.ID
+	reply := make(chan messages.Event, 1)
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+
+		return messages.Event{}, ErrRequesterClosed
+	}
+
+	r.pending[id] = reply
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, id)
+		r.mu.Unlock()
+	}()
+
+	err := r.publish(ctx, request, r.replyChannel)
+	if err != nil {
+		return messages.Event{}, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return messages.Event{}, errors.Wrapf(ctx.Err(), "no reply to request %q", id)
+	case <-r.stopped:
+		return messages.Event{}, ErrRequesterClosed
+	case event := <-reply:
+		return event, nil
+	}
+}
+
+// listen streams the shared reply channel and hands every reply to its waiting request.
+// Replies nobody waits for, like late ones, are acknowledged and dropped.
+func (r *Requester) listen() {
+	r.mu.Lock()
+	closed := r.closed
+	r.mu.Unlock()
+
+	if closed {
+		close(r.stopped)
+		r.startErr = ErrRequesterClosed
+
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := r.subscriber.Stream(ctx)
+	if err != nil {
+		cancel()
+		close(r.stopped)
+		r.startErr = errors.WithMessage(err, "could not listen to replies")
+
+		return
+	}
+
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	go func() {
+		defer close(r.stopped)
+
+		for event := range stream {
+			r.mu.Lock()
+			reply, ok := r.pending[event.Header.Attribute(CorrelationIDAttribute)]
+			r.mu.Unlock()
+
+			if ok {
+				select {
+				case reply <- event:
+				default:
+				}
+			}
+
+			acknowledge(ctx, r.subscriber, event)
+		}
+	}()
+}
+
+func (r *Requester) requestOnOwnChannel(ctx context.Context, request publishers.EventMessage) (messages.Event, error) {
+	replyChannel, err := r.newReplyChannel()
+	if err != nil {
+		return messages.Event{}, err
+	}
+
+	subscriber := r.newSubscriber()
+
+	err = subscriber.Subscribe(ctx, replyChannel)
+	if err != nil {
+		return messages.Event{}, errors.WithMessage(err, "could not listen to replies")
+	}
+
+	defer func() {
+		err := subscriber.Unsubscribe(context.Background(), replyChannel)
+		if err != nil && !errors.Is(err, subscribers.ErrUnsubscribeNotSupported) {
+			log.Println("error", err, "channel", replyChannel, "method", "requestreply.Requester.requestOnOwnChannel")
+		}
+	}()
+
+	stream, err := subscriber.Stream(ctx)
+	if err != nil {
+		return messages.Event{}, errors.WithMessage(err, "could not listen to replies")
+	}
+
+	err = r.publish(ctx, request, replyChannel)
+	if err != nil {
+		return messages.Event{}, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return messages.Event{}, errors.Wrapf(ctx.Err(), "no reply to request %q", request.Event.Header.ID)
+		case event, ok := <-stream:
+			if !ok {
+				return messages.Event{}, errors.WithMessagef(errStreamClosed, "no reply to request %q", request.Event.Header.ID)
+			}
+
+			acknowledge(ctx, subscriber, event)
+
+			if event.Header.Attribute(CorrelationIDAttribute) == request.Event.Header.ID {
+				return event, nil
+			}
+		}
+	}
+}
+
+func (r *Requester) publish(ctx context.Context, request publishers.EventMessage, replyChannel string) error {
+	request.Event.Header = request.Event.Header.WithAttribute(ReplyToAttribute, replyChannel)
+
+	err := r.publisher.Publish(ctx, request)
+	if err != nil {
+		return errors.WithMessagef(err, "could not send request %q", request.Event.Header.ID)
+	}
+
+	return nil
+}
+
+func (r *Requester) newReplyChannel() (string, error) {
+	suffix := make([]byte, replyChannelSuffixSize)
+
+	_, err := io.ReadFull(rand.Reader, suffix)
+	if err != nil {
+		return "", errors.Wrap(err, "could not generate reply channel")
+	}
+
+	return r.prefix + hex.EncodeToString(suffix), nil
+}
+
+func acknowledge(ctx context.Context, subscriber *subscribers.Subscriber, event messages.Event) {
+	err := subscriber.Acknowledge(ctx, event.Header.MessageID)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "requestreply.acknowledge",
+		)
+	}
+}
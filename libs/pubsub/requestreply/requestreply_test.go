@@ -0,0 +1,136 @@
+package requestreply_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/internal/adapters/memory"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/requestreply"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestOnSharedReplyChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	bus := memory.New()
+	startReplier(ctx, t, bus)
+
+	replies := subscribers.New(subscribers.Settings{EventBus: bus.Subscriber()})
+	_ = replies.Subscribe(ctx, "credit.replies")
+	requester, _ := requestreply.New(requestreply.Settings{
+		Publisher:    publishers.New(bus),
+		ReplyChannel: "credit.replies",
+		Subscriber:   replies,
+	})
+
+	defer requester.Close()
+	// When
+	first, errFirst := requester.Request(ctx, requestFixture("1"))
+	second, errSecond := requester.Request(ctx, requestFixture("2"))
+	// Then
+	assert.NoError(t, errFirst)
+	assert.NoError(t, errSecond)
+	assert.Equal(t, "reply-1", first.Header.ID)
+	assert.Equal(t, "reply-2", second.Header.ID)
+	assert.Equal(t, "1", first.Header.Attribute(requestreply.CorrelationIDAttribute))
+}
+
+func TestRequestOnOwnReplyChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	bus := memory.New()
+	startReplier(ctx, t, bus)
+
+	requester, _ := requestreply.New(requestreply.Settings{
+		Publisher: publishers.New(bus),
+		NewSubscriber: func() *subscribers.Subscriber {
+			return subscribers.New(subscribers.Settings{EventBus: bus.Subscriber()})
+		},
+	})
+	// When
+	got, err := requester.Request(ctx, requestFixture("1"))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "reply-1", got.Header.ID)
+	assert.Contains(t, bus.Events("blockchain.requests")[0].Header.Attribute(requestreply.ReplyToAttribute), "reply.")
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	bus := memory.New()
+	requester, _ := requestreply.New(requestreply.Settings{
+		Publisher: publishers.New(bus),
+		NewSubscriber: func() *subscribers.Subscriber {
+			return subscribers.New(subscribers.Settings{EventBus: bus.Subscriber()})
+		},
+		Timeout: 20 * time.Millisecond,
+	})
+	// When
+	_, err := requester.Request(context.TODO(), requestFixture("1"))
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestNewWithoutReplySource(t *testing.T) {
+	t.Parallel()
+
+	// When
+	_, err := requestreply.New(requestreply.Settings{ReplyChannel: "credit.replies"})
+	// Then
+	assert.Error(t, err)
+}
+
+// startReplier answers every request of the blockchain.requests channel.
+func startReplier(ctx context.Context, t *testing.T, bus *memory.Bus) {
+	t.Helper()
+
+	requests := subscribers.New(subscribers.Settings{EventBus: bus.Subscriber()})
+
+	err := requests.Subscribe(ctx, "blockchain.requests")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	consumer := consumers.New(consumers.Settings{
+		Subscriber: requests,
+		Handler: requestreply.Handler(publishers.New(bus), func(_ context.Context, request messages.Event) (messages.Event, error) {
+			return messages.Event{Header: messages.Header{ID: "reply-" + request.Header.ID}, Data: request.Data}, nil
+		}),
+	})
+
+	go func() { _ = consumer.Run(ctx) }()
+}
+
+func requestFixture(id string) publishers.EventMessage {
+	return publishers.EventMessage{
+		ChannelName: "blockchain.requests",
+		Event: messages.Event{
+			Header: messages.Header{
+				ID:          id,
+				Domain:      "credit",
+				EventType:   "balance-requested",
+				Version:     "0.1.0",
+				Application: "credit-app",
+			},
+			Data: []byte(`{"account": "1"}`),
+		},
+	}
+}
@@ -0,0 +1,3 @@
+// Package routing provides declarative filters on event header fields and attributes, so
+// subscribers only get the events they care about.
+package routing
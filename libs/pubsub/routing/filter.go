@@ -0,0 +1,241 @@
+package routing
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+// Header fields conditions can check.
+const (
+	FieldDomain      = "domain"
+	FieldEventType   = "eventtype"
+	FieldVersion     = "version"
+	FieldApplication = "application"
+	FieldOrderingKey = "orderingkey"
+	FieldChannel     = "channel"
+
+	attributePrefix = "attributes."
+)
+
+// Operator defines how a condition compares the field with its values.
+type Operator string
+
+const (
+	// OperatorEqual the field is equal to one of the values.
+	OperatorEqual Operator = "eq"
+	// OperatorNotEqual the field is different from every value.
+	OperatorNotEqual Operator = "ne"
+	// OperatorPrefix the field starts with one of the values.
+	OperatorPrefix Operator = "prefix"
+	// OperatorExists the field is not empty.
+	OperatorExists Operator = "exists"
+	// OperatorGreaterOrEqual the field is greater than or equal to the value.
+	OperatorGreaterOrEqual Operator = "ge"
+	// OperatorLess the field is less than the value.
+	OperatorLess Operator = "lt"
+)
+
+var (
+	errUnknownField    = errors.New("unknown filter field")
+	errUnknownOperator = errors.New("unknown filter operator")
+	errMissingValue    = errors.New("filter condition needs a value")
+)
+
+// Condition checks a header field, or attribute, against the values.
+type Condition struct {
+	Field    string
+	Operator Operator
+	Values   []string
+}
+
+// Filter matches the events fulfilling every condition, an empty filter matches every event.
+type Filter []Condition
+
+// Attribute returns the field name of the given header attribute.
+func Attribute(key string) string {
+	return attributePrefix + key
+}
+
+// Equals returns a condition that holds when the field is one of the values.
+func Equals(field string, values ...string) Condition {
+	return Condition{Field: field, Operator: OperatorEqual, Values: values}
+}
+
+// NotEquals returns a condition that holds when the field is none of the values.
+func NotEquals(field string, values ...string) Condition {
+	return Condition{Field: field, Operator: OperatorNotEqual, Values: values}
+}
+
+// HasPrefix returns a condition that holds when the field starts with one of the prefixes.
+func HasPrefix(field string, prefixes ...string) Condition {
+	return Condition{Field: field, Operator: OperatorPrefix, Values: prefixes}
+}
+
+// Exists returns a condition that holds when the field is not empty.
+func Exists(field string) Condition {
+	return Condition{Field: field, Operator: OperatorExists}
+}
+
+// AtLeast returns a condition that holds when the field is greater than or equal to the value,
+// versions like 2.1.0 or v2 are compared segment by segment.
+func AtLeast(field, value string) Condition {
+	return Condition{Field: field, Operator: OperatorGreaterOrEqual, Values: []string{value}}
+}
+
+// Below returns a condition that holds when the field is less than the value.
+func Below(field, value string) Condition {
+	return Condition{Field: field, Operator: OperatorLess, Values: []string{value}}
+}
+
+// Validate checks every condition has a known field, operator and the values it needs.
+func (f Filter) Validate() error {
+	for _, condition := range f {
+		if !knownField(condition.Field) {
+			return errors.WithMessagef(errUnknownField, "%q", condition.Field)
+		}
+
+		switch condition.Operator {
+		case OperatorExists:
+		case OperatorEqual, OperatorNotEqual, OperatorPrefix, OperatorGreaterOrEqual, OperatorLess:
+			if len(condition.Values) == 0 {
+				return errors.WithMessagef(errMissingValue, "%q %s", condition.Field, condition.Operator)
+			}
+		default:
+			return errors.WithMessagef(errUnknownOperator, "%q", condition.Operator)
+		}
+	}
+
+	return nil
+}
+
+// Match reports whether the header fulfils every condition of the filter.
+func (f Filter) Match(header messages.Header) bool {
+	for _, condition := range f {
+		if !condition.Match(header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Match reports whether the header fulfils the condition.
+func (c Condition) Match(header messages.Header) bool {
+	value := Value(header, c.Field)
+
+	switch c.Operator {
+	case OperatorExists:
+		return value != ""
+	case OperatorNotEqual:
+		return !contains(c.Values, value)
+	case OperatorEqual:
+		return contains(c.Values, value)
+	case OperatorPrefix:
+		for _, prefix := range c.Values {
+			if strings.HasPrefix(value, prefix) {
+				return true
+			}
+		}
+
+		return false
+	case OperatorGreaterOrEqual:
+		return value != "" && len(c.Values) > 0 && Compare(value, c.Values[0]) >= 0
+	case OperatorLess:
+		return value != "" && len(c.Values) > 0 && Compare(value, c.Values[0]) < 0
+	default:
+		return false
+	}
+}
+
+// Value returns the header field, or attribute, the condition field names.
+func Value(header messages.Header, field string) string {
+	switch field {
+	case FieldDomain:
+		return header.Domain
+	case FieldEventType:
+		return header.EventType
+	case FieldVersion:
+		return header.Version
+	case FieldApplication:
+		return header.Application
+	case FieldOrderingKey:
+		return header.OrderingKey
+	case FieldChannel:
+		return header.Channel
+	default:
+		return header.Attribute(strings.TrimPrefix(field, attributePrefix))
+	}
+}
+
+// AttributeKey returns the attribute key of an attribute field and whether it is one.
+func AttributeKey(field string) (string, bool) {
+	if !strings.HasPrefix(field, attributePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(field, attributePrefix), true
+}
+
+// Compare compares two versions segment by segment, numeric segments as numbers and the rest
+// as text, a leading v is ignored. It returns -1, 0 or 1.
+func Compare(a, b string) int {
+	aSegments := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bSegments := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for idx := 0; idx < len(aSegments) || idx < len(bSegments); idx++ {
+		aSegment, bSegment := "0", "0"
+		if idx < len(aSegments) {
+			aSegment = aSegments[idx]
+		}
+
+		if idx < len(bSegments) {
+			bSegment = bSegments[idx]
+		}
+
+		if result := compareSegment(aSegment, bSegment); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+func compareSegment(a, b string) int {
+	aNumber, aErr := strconv.ParseUint(a, 10, 64)
+	bNumber, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil && aNumber < bNumber:
+		return -1
+	case aErr == nil && bErr == nil && aNumber > bNumber:
+		return 1
+	case aErr == nil && bErr == nil:
+		return 0
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func knownField(field string) bool {
+	switch field {
+	case FieldDomain, FieldEventType, FieldVersion, FieldApplication, FieldOrderingKey, FieldChannel:
+		return true
+	default:
+		key, ok := AttributeKey(field)
+
+		return ok && key != ""
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
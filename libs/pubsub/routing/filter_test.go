@@ -0,0 +1,137 @@
+package routing_test
+
+import (
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	header := messages.Header{
+		Domain:     "loans",
+		EventType:  "loan-created",
+		Version:    "2.1.0",
+		Channel:    "credit.loan.created",
+		Attributes: map[string]string{"region": "eu-west"},
+	}
+	tests := map[string]struct {
+		filter   routing.Filter
+		expected bool
+	}{
+		"empty filter": {filter: nil, expected: true},
+		"domain and version": {
+			filter:   routing.Filter{routing.Equals(routing.FieldDomain, "loans"), routing.AtLeast(routing.FieldVersion, "2")},
+			expected: true,
+		},
+		"older version": {
+			filter:   routing.Filter{routing.AtLeast(routing.FieldVersion, "v2.10")},
+			expected: false,
+		},
+		"below version": {
+			filter:   routing.Filter{routing.Below(routing.FieldVersion, "10.0.0")},
+			expected: true,
+		},
+		"other domain": {
+			filter:   routing.Filter{routing.Equals(routing.FieldDomain, "cards", "payments")},
+			expected: false,
+		},
+		"not equals": {
+			filter:   routing.Filter{routing.NotEquals(routing.FieldEventType, "loan-deleted")},
+			expected: true,
+		},
+		"attribute prefix": {
+			filter:   routing.Filter{routing.HasPrefix(routing.Attribute("region"), "eu-")},
+			expected: true,
+		},
+		"missing attribute": {
+			filter:   routing.Filter{routing.Exists(routing.Attribute("tenant"))},
+			expected: false,
+		},
+		"channel": {
+			filter:   routing.Filter{routing.HasPrefix(routing.FieldChannel, "credit.")},
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			got := test.filter.Match(header)
+			// Then
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		filter  routing.Filter
+		isValid bool
+	}{
+		"valid":            {filter: routing.Filter{routing.Exists(routing.Attribute("tenant"))}, isValid: true},
+		"unknown field":    {filter: routing.Filter{routing.Equals("tenant", "1")}, isValid: false},
+		"empty attribute":  {filter: routing.Filter{routing.Exists(routing.Attribute(""))}, isValid: false},
+		"missing value":    {filter: routing.Filter{routing.Equals(routing.FieldDomain)}, isValid: false},
+		"unknown operator": {filter: routing.Filter{{Field: routing.FieldDomain, Operator: "like"}}, isValid: false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			err := test.filter.Validate()
+			// Then
+			assert.Equal(t, test.isValid, err == nil)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, routing.Compare("2", "v2.0.0"))
+	assert.Equal(t, -1, routing.Compare("2.9", "2.10"))
+	assert.Equal(t, 1, routing.Compare("3.0.0-beta", "3.0.0-alpha"))
+}
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	// When
+	got, err := routing.ParseFilter([]string{
+		"domain=loans,cards", "eventtype!=loan-deleted", "version>=2", "version<3",
+		"attributes.region^=eu-", "attributes.tenant?",
+	})
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, routing.Filter{
+		routing.Equals(routing.FieldDomain, "loans", "cards"),
+		routing.NotEquals(routing.FieldEventType, "loan-deleted"),
+		routing.AtLeast(routing.FieldVersion, "2"),
+		routing.Below(routing.FieldVersion, "3"),
+		routing.HasPrefix(routing.Attribute("region"), "eu-"),
+		routing.Exists(routing.Attribute("tenant")),
+	}, got)
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, expression := range []string{"domain", "=loans", "tenant=1"} {
+		_, err := routing.ParseFilter([]string{expression})
+		assert.Error(t, err, expression)
+	}
+}
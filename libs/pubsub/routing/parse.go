@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errInvalidExpression = errors.New("invalid filter expression")
+
+// expressionOperators are checked in order, so longer operators win over their prefixes.
+var expressionOperators = []struct {
+	symbol   string
+	operator Operator
+}{
+	{symbol: "!=", operator: OperatorNotEqual},
+	{symbol: ">=", operator: OperatorGreaterOrEqual},
+	{symbol: "^=", operator: OperatorPrefix},
+	{symbol: "=", operator: OperatorEqual},
+	{symbol: "<", operator: OperatorLess},
+}
+
+// ParseFilter parses one condition per expression, like the ones given in command lines:
+//
+//	domain=loans,cards  eventtype!=loan-deleted  version>=2  version<3
+//	attributes.region^=eu-  attributes.tenant?
+//
+// Comma separated values are alternatives.
+func ParseFilter(expressions []string) (Filter, error) {
+	filter := make(Filter, 0, len(expressions))
+
+	for _, expression := range expressions {
+		condition, err := parseCondition(expression)
+		if err != nil {
+			return nil, err
+		}
+
+		filter = append(filter, condition)
+	}
+
+	return filter, filter.Validate()
+}
+
+func parseCondition(expression string) (Condition, error) {
+	if strings.HasSuffix(expression, "?") {
+		return Exists(strings.TrimSpace(strings.TrimSuffix(expression, "?"))), nil
+	}
+
+	for _, candidate := range expressionOperators {
+		idx := strings.Index(expression, candidate.symbol)
+		if idx <= 0 {
+			continue
+		}
+
+		values := strings.Split(expression[idx+len(candidate.symbol):], ",")
+		for valueIdx := range values {
+			values[valueIdx] = strings.TrimSpace(values[valueIdx])
+		}
+
+		return Condition{
+			Field:    strings.TrimSpace(expression[:idx]),
+			Operator: candidate.operator,
+			Values:   values,
+		}, nil
+	}
+
+	return Condition{}, errors.WithMessagef(errInvalidExpression, "%q", expression)
+}
@@ -0,0 +1,3 @@
+// Package signing provides Ed25519 signatures for events, so consumers can verify which
+// application produced them and that they were not modified on the way.
+package signing
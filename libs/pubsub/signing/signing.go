@@ -0,0 +1,169 @@
+package signing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"sort"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureAttribute header attribute with the base64 encoded Ed25519 signature.
+	SignatureAttribute = "signature"
+	// KeyIDAttribute header attribute with the id of the key that signed the event.
+	KeyIDAttribute = "signaturekeyid"
+)
+
+var (
+	// ErrMissingSignature is returned when a signature is required but the event has none.
+	ErrMissingSignature = errors.New("event is not signed")
+	// ErrInvalidSignature is returned when the signature does not match the event.
+	ErrInvalidSignature = errors.New("invalid event signature")
+
+	errUnknownKey         = errors.New("unknown signing key")
+	errWrongApplication   = errors.New("signing key does not belong to the event application")
+	errInvalidPrivateKey  = errors.New("invalid ed25519 private key")
+	errMissingApplication = errors.New("signed events must have an application")
+)
+
+// SigningKey is the private key an application signs its events with.
+type SigningKey struct {
+	// KeyID id consumers use to find the public key.
+	KeyID      string
+	PrivateKey ed25519.PrivateKey
+}
+
+// TrustedKey is a public key consumers accept signatures from.
+type TrustedKey struct {
+	// Application the only application allowed to sign with this key.
+	Application string
+	PublicKey   ed25519.PublicKey
+}
+
+// VerifierSettings contains the signature verification configuration.
+type VerifierSettings struct {
+	// Keys trusted public keys by key id, several keys of the same application can be
+	// trusted at once while rotating them.
+	Keys map[string]TrustedKey
+	// RequireSignature rejects events without signature when it is true.
+	RequireSignature bool
+}
+
+// Sign signs the event header, attributes included, and its data. It must be the last change
+// made to the event before publishing it.
+func Sign(key SigningKey, event messages.Event) (messages.Event, error) {
+	if len(key.PrivateKey) != ed25519.PrivateKeySize {
+		return messages.Event{}, errInvalidPrivateKey
+	}
+
+	if event.Header.Application == "" {
+		return messages.Event{}, errMissingApplication
+	}
+
+	event.Header = event.Header.
+		WithoutAttribute(SignatureAttribute).
+		WithAttribute(KeyIDAttribute, key.KeyID)
+	signature := ed25519.Sign(key.PrivateKey, signedContent(event))
+	event.Header = event.Header.WithAttribute(SignatureAttribute, base64.StdEncoding.EncodeToString(signature))
+
+	return event, nil
+}
+
+// Verify checks the event signature was made by a trusted key of the event application and
+// removes the signature attributes.
+func Verify(settings VerifierSettings, event messages.Event) (messages.Event, error) {
+	encoded := event.Header.Attribute(SignatureAttribute)
+	if encoded == "" {
+		if settings.RequireSignature {
+			return messages.Event{}, errors.WithMessagef(ErrMissingSignature, "%q", event.Header.ID)
+		}
+
+		return event, nil
+	}
+
+	keyID := event.Header.Attribute(KeyIDAttribute)
+
+	trusted, ok := settings.Keys[keyID]
+	if !ok {
+		return messages.Event{}, errors.WithMessagef(errUnknownKey, "%q", keyID)
+	}
+
+	if trusted.Application != event.Header.Application {
+		return messages.Event{}, errors.WithMessagef(errWrongApplication, "%q", event.Header.Application)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return messages.Event{}, errors.WithMessagef(ErrInvalidSignature, "%q", event.Header.ID)
+	}
+
+	event.Header = event.Header.WithoutAttribute(SignatureAttribute)
+	if !ed25519.Verify(trusted.PublicKey, signedContent(event), signature) {
+		return messages.Event{}, errors.WithMessagef(ErrInvalidSignature, "%q", event.Header.ID)
+	}
+
+	event.Header = event.Header.WithoutAttribute(KeyIDAttribute)
+
+	return event, nil
+}
+
+// Publisher returns a publisher middleware that signs every event.
+func Publisher(key SigningKey) publishers.Middleware {
+	return publishers.Transform(
+		func(_ context.Context, _ string, event messages.Event) (messages.Event, error) {
+			return Sign(key, event)
+		},
+	)
+}
+
+// Subscriber returns a subscriber middleware that verifies every event signature.
+func Subscriber(settings VerifierSettings) subscribers.Middleware {
+	return subscribers.Transform(
+		func(_ context.Context, event messages.Event) (messages.Event, error) {
+			return Verify(settings, event)
+		},
+	)
+}
+
+// signedContent returns an unambiguous encoding of the signed fields, every field is
+// prefixed by its length and attributes are sorted by key. MessageID is assigned by the event
+// bus so it is not signed.
+func signedContent(event messages.Event) []byte {
+	header := event.Header
+	fields := []string{
+		header.ID, header.Domain, header.EventType, header.Version, header.Application, header.OrderingKey,
+	}
+	keys := make([]string, 0, len(header.Attributes))
+
+	for key := range header.Attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fields = append(fields, key, header.Attributes[key])
+	}
+
+	var result []byte
+
+	for _, field := range fields {
+		result = appendField(result, []byte(field))
+	}
+
+	return appendField(result, event.Data)
+}
+
+func appendField(dst, field []byte) []byte {
+	var length [8]byte
+
+	binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+
+	return append(append(dst, length[:]...), field...)
+}
@@ -0,0 +1,160 @@
+package signing_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/publishers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/signing"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerifyThroughMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	expectedEvent := eventMessageFixture()
+	key, trusted := keysFixture()
+	eventBus := new(eventBusMock)
+	publisher := publishers.New(publishers.Chain(eventBus, signing.Publisher(key)))
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, signing.Subscriber(trusted)),
+		MessagesPerPull: 1,
+	})
+	// When
+	err := publisher.Publish(ctx, publishers.EventMessage{ChannelName: "orders-topic", Event: expectedEvent})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []messages.Event{expectedEvent}, got)
+	assert.NotEmpty(t, eventBus.events[0].Header.Attribute(signing.SignatureAttribute))
+}
+
+func TestVerifyRejectsEvents(t *testing.T) {
+	t.Parallel()
+
+	key, trusted := keysFixture()
+	tests := map[string]struct {
+		tamper        func(event messages.Event) messages.Event
+		expectedError error
+	}{
+		"modified data": {
+			tamper: func(event messages.Event) messages.Event {
+				event.Data = []byte(`{"value_one": "changed"}`)
+
+				return event
+			},
+			expectedError: signing.ErrInvalidSignature,
+		},
+		"modified attribute": {
+			tamper: func(event messages.Event) messages.Event {
+				event.Header = event.Header.WithAttribute("tenant", "another")
+
+				return event
+			},
+			expectedError: signing.ErrInvalidSignature,
+		},
+		"impersonated application": {
+			tamper: func(event messages.Event) messages.Event {
+				event.Header.Application = "another-app"
+
+				return event
+			},
+		},
+		"unsigned": {
+			tamper: func(event messages.Event) messages.Event {
+				event.Header = event.Header.WithoutAttribute(signing.SignatureAttribute)
+
+				return event
+			},
+			expectedError: signing.ErrMissingSignature,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			signed, err := signing.Sign(key, eventMessageFixture())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			// When
+			_, err = signing.Verify(trusted, test.tamper(signed))
+			// Then
+			assert.Error(t, err)
+
+			if test.expectedError != nil {
+				assert.True(t, errors.Is(err, test.expectedError))
+			}
+		})
+	}
+}
+
+func keysFixture() (signing.SigningKey, signing.VerifierSettings) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{7}, 32)))
+	key := signing.SigningKey{KeyID: "core-app-1", PrivateKey: privateKey}
+	trusted := signing.VerifierSettings{
+		Keys: map[string]signing.TrustedKey{
+			"core-app-1": {Application: "core-app", PublicKey: publicKey},
+		},
+		RequireSignature: true,
+	}
+
+	return key, trusted
+}
+
+func eventMessageFixture() messages.Event {
+	header := messages.Header{
+		ID:          "123-456-789",
+		Domain:      "loans",
+		EventType:   "orders",
+		Version:     "0.1.0",
+		Application: "core-app",
+	}
+
+	return messages.Event{
+		Header: header.WithAttribute("tenant", "acme"),
+		Data:   []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
+
+// eventBusMock delivers published events to its subscribers.
+type eventBusMock struct {
+	events []messages.Event
+}
+
+func (e *eventBusMock) Publish(_ context.Context, _ string, message interface{}) error {
+	e.events = append(e.events, message.(messages.Event))
+
+	return nil
+}
+
+func (e *eventBusMock) Pull(_ context.Context, _ uint8) ([]messages.Event, error) {
+	return e.events, nil
+}
+
+func (e *eventBusMock) Stream(_ context.Context) (<-chan messages.Event, error) {
+	return nil, nil
+}
+
+func (e *eventBusMock) Subscribe(_ context.Context, _ string) error {
+	return nil
+}
+
+func (e *eventBusMock) Acknowledge(_ context.Context, _ string) error {
+	return nil
+}
@@ -0,0 +1,122 @@
+package subscribers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChannelSeparator separates the segments of hierarchical channel names like credit.loan.created.
+	ChannelSeparator = "."
+	// SingleSegmentWildcard matches exactly one channel segment.
+	SingleSegmentWildcard = "*"
+	// MultiSegmentWildcard matches one or more trailing channel segments, it must be the last one.
+	MultiSegmentWildcard = ">"
+)
+
+var (
+	// ErrUnsubscribeNotSupported is returned when unsubscribing from an event bus that cannot
+	// stop listening to a single channel.
+	ErrUnsubscribeNotSupported = errors.New("event bus does not support unsubscribing")
+
+	errNotSubscribed = errors.New("not subscribed to channel")
+)
+
+// UnsubscribeEventBusSubscriber is implemented by event buses that can stop listening to one
+// of the channels they are subscribed to.
+type UnsubscribeEventBusSubscriber interface {
+	EventBusSubscriber
+	// Unsubscribe stops receiving events from the channel, or channel pattern.
+	Unsubscribe(ctx context.Context, channel string) error
+}
+
+// Unsubscribe stops listening to the given channel, or channel pattern, the other channels
+// are still received.
+func (s *Subscriber) Unsubscribe(ctx context.Context, channel string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.subscribed(channel)
+	if idx < 0 {
+		return errors.WithMessagef(errNotSubscribed, "%q", channel)
+	}
+
+	found := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(UnsubscribeEventBusSubscriber)
+
+		return ok
+	})
+	if found == nil {
+		return errors.WithMessagef(ErrUnsubscribeNotSupported, "%q", channel)
+	}
+
+	err := found.(UnsubscribeEventBusSubscriber).Unsubscribe(ctx, channel)
+	if err != nil {
+		return errors.WithMessagef(err, "unexpected error unsubscribing from channel %q", channel)
+	}
+
+	s.channels = append(s.channels[:idx:idx], s.channels[idx+1:]...)
+
+	return nil
+}
+
+// Channels returns the channels and channel patterns the subscriber listens to.
+func (s *Subscriber) Channels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.channels...)
+}
+
+// IsChannelPattern reports whether the channel contains wildcards.
+func IsChannelPattern(channel string) bool {
+	for _, segment := range strings.Split(channel, ChannelSeparator) {
+		if segment == SingleSegmentWildcard || segment == MultiSegmentWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchChannel reports whether the channel matches the pattern, so event buses without
+// native wildcards can filter the channels they receive.
+func MatchChannel(pattern, channel string) bool {
+	patternSegments := strings.Split(pattern, ChannelSeparator)
+	channelSegments := strings.Split(channel, ChannelSeparator)
+
+	for idx, segment := range patternSegments {
+		if segment == MultiSegmentWildcard && idx == len(patternSegments)-1 {
+			return len(channelSegments) > idx
+		}
+
+		if idx >= len(channelSegments) {
+			return false
+		}
+
+		if segment != SingleSegmentWildcard && segment != channelSegments[idx] {
+			return false
+		}
+	}
+
+	return len(patternSegments) == len(channelSegments)
+}
+
+// subscribed returns the index of the channel in the subscribed channels or -1, the lock
+// must be held.
+func (s *Subscriber) subscribed(channel string) int {
+	for idx, subscribed := range s.channels {
+		if subscribed == channel {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// channelNames returns the subscribed channels for error messages.
+func (s *Subscriber) channelNames() string {
+	return strings.Join(s.Channels(), ",")
+}
@@ -0,0 +1,111 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeToSeveralChannels(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(unsubscribeEventBusMock)
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	ctx := context.TODO()
+	// When
+	errOrders := subscriber.Subscribe(ctx, "orders-topic")
+	errCredit := subscriber.Subscribe(ctx, "credit.*.created")
+	errAgain := subscriber.Subscribe(ctx, "orders-topic")
+	// Then
+	assert.NoError(t, errOrders)
+	assert.NoError(t, errCredit)
+	assert.NoError(t, errAgain)
+	assert.Equal(t, []string{"orders-topic", "credit.*.created"}, subscriber.Channels())
+	assert.Equal(t, []string{"orders-topic", "credit.*.created"}, eventBus.subscribed)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(unsubscribeEventBusMock)
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	ctx := context.TODO()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	_ = subscriber.Subscribe(ctx, "credit.*.created")
+	// When
+	err := subscriber.Unsubscribe(ctx, "orders-topic")
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"credit.*.created"}, subscriber.Channels())
+	assert.Equal(t, []string{"orders-topic"}, eventBus.unsubscribed)
+}
+
+func TestUnsubscribeErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	subscriber := subscribers.New(subscribers.Settings{EventBus: new(eventBusMock)})
+	ctx := context.TODO()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	errNotSupported := subscriber.Unsubscribe(ctx, "orders-topic")
+	errNotSubscribed := subscriber.Unsubscribe(ctx, "credit.*.created")
+	// Then
+	assert.True(t, errors.Is(errNotSupported, subscribers.ErrUnsubscribeNotSupported))
+	assert.Error(t, errNotSubscribed)
+	assert.Equal(t, []string{"orders-topic"}, subscriber.Channels())
+}
+
+func TestMatchChannel(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pattern, channel string
+		expected         bool
+	}{
+		"same channel":                {"credit.loan.created", "credit.loan.created", true},
+		"different channel":           {"credit.loan.created", "credit.loan.updated", false},
+		"single segment wildcard":     {"credit.*.created", "credit.card.created", true},
+		"single wildcard one segment": {"credit.*.created", "credit.card.gold.created", false},
+		"multi segment wildcard":      {"credit.>", "credit.card.gold.created", true},
+		"multi wildcard needs one":    {"credit.>", "credit", false},
+		"shorter channel":             {"credit.*.created", "credit.card", false},
+		"longer channel":              {"credit.card", "credit.card.created", false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// When
+			got := subscribers.MatchChannel(test.pattern, test.channel)
+			// Then
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+// unsubscribeEventBusMock records the channels subscribed to and unsubscribed from.
+type unsubscribeEventBusMock struct {
+	eventBusMock
+	subscribed, unsubscribed []string
+}
+
+func (u *unsubscribeEventBusMock) Subscribe(_ context.Context, channel string) error {
+	u.subscribed = append(u.subscribed, channel)
+
+	return nil
+}
+
+func (u *unsubscribeEventBusMock) Unsubscribe(_ context.Context, channel string) error {
+	u.unsubscribed = append(u.unsubscribed, channel)
+
+	return nil
+}
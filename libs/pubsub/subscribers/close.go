@@ -0,0 +1,51 @@
+package subscribers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSubscriberClosed is returned when using a closed subscriber.
+var ErrSubscriberClosed = errors.New("subscriber is closed")
+
+// Close stops accepting subscriptions, pulls and streams and ends the open streams. Consumers
+// must be shut down first so the events they are handling can still be acknowledged, and the
+// event bus adapter closed afterwards.
+func (s *Subscriber) Close(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSubscriberClosed
+	}
+
+	s.closed = true
+	close(s.closing)
+
+	return nil
+}
+
+func (s *Subscriber) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// untilClosed returns a context done when the given one is, the subscriber is closed or the
+// returned cancel function is called.
+func (s *Subscriber) untilClosed(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		defer cancel()
+
+		select {
+		case <-ctx.Done():
+		case <-s.closing:
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,58 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseEndsStreams(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	eventBus := new(eventBusMock).withEvents(flowEventsFixture(3)).withEventStream()
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	stream, _ := subscriber.Stream(ctx)
+	first := <-stream
+	// When
+	err := subscriber.Close(ctx)
+
+	for range stream {
+	}
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "1", first.Header.MessageID)
+	assert.NoError(t, subscriber.Err())
+}
+
+func TestCloseRejectsNewCalls(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: new(eventBusMock).withEvents(flowEventsFixture(1)).withEventStream(),
+	})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	err := subscriber.Close(ctx)
+	// Then
+	assert.NoError(t, err)
+
+	_, err = subscriber.Pull(ctx)
+	assert.True(t, errors.Is(err, subscribers.ErrSubscriberClosed))
+
+	_, err = subscriber.Stream(ctx)
+	assert.True(t, errors.Is(err, subscribers.ErrSubscriberClosed))
+	assert.True(t, errors.Is(subscriber.Subscribe(ctx, "payments-topic"), subscribers.ErrSubscriberClosed))
+	assert.True(t, errors.Is(subscriber.Close(ctx), subscribers.ErrSubscriberClosed))
+}
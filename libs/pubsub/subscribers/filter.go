@@ -0,0 +1,85 @@
+package subscribers
+
+import (
+	"context"
+	"log"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+)
+
+// FilterEventBusSubscriber is implemented by event buses that can filter events before
+// delivering them, like sns filter policies or nats subject filters.
+type FilterEventBusSubscriber interface {
+	EventBusSubscriber
+	// SubscribeFiltered subscribes to the channel receiving only the events the filter matches.
+	// It returns false when the event bus could only apply part of the filter, the subscriber
+	// then checks every received event.
+	SubscribeFiltered(ctx context.Context, channel string, filter routing.Filter) (bool, error)
+}
+
+// subscribeFiltered pushes the filter down to the event bus when it can filter, otherwise the
+// events are filtered client side.
+func (s *Subscriber) subscribeFiltered(ctx context.Context, channel string) error {
+	found := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(FilterEventBusSubscriber)
+
+		return ok
+	})
+	if found == nil || s.group != "" || s.name != "" {
+		s.clientFilter = true
+
+		return s.subscribe(ctx, channel)
+	}
+
+	complete, err := found.(FilterEventBusSubscriber).SubscribeFiltered(ctx, channel, s.filter)
+	if err != nil {
+		return err
+	}
+
+	if !complete {
+		s.clientFilter = true
+	}
+
+	return nil
+}
+
+// matches reports whether the event must be delivered, events filtered out client side are
+// acknowledged so the event bus does not deliver them again.
+func (s *Subscriber) matches(ctx context.Context, event messages.Event) bool {
+	s.mu.Lock()
+	clientFilter := s.clientFilter
+	s.mu.Unlock()
+
+	if !clientFilter || s.filter.Match(event.Header) {
+		return true
+	}
+
+	err := s.eventBus.Acknowledge(ctx, event.Header.MessageID)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "subscribers.Subscriber.matches",
+		)
+	}
+
+	return false
+}
+
+// keep returns the events that must be delivered.
+func (s *Subscriber) keep(ctx context.Context, events []messages.Event) []messages.Event {
+	if len(s.filter) == 0 {
+		return events
+	}
+
+	result := events[:0:0]
+
+	for _, event := range events {
+		if s.matches(ctx, event) {
+			result = append(result, event)
+		}
+	}
+
+	return result
+}
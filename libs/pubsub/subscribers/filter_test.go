@@ -0,0 +1,125 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterClientSide(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	events := flowEventsFixture(3)
+	events[0].Header.Version = "2.0.0"
+	events[1].Header.Domain = "cards"
+	events[2].Header.Version = "1.5.0"
+	eventBus := &ackEventBusMock{}
+	eventBus.withEvents(events).withEventStream()
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: eventBus,
+		Filter: routing.Filter{
+			routing.Equals(routing.FieldDomain, "loans"),
+			routing.AtLeast(routing.FieldVersion, "2"),
+		},
+	})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	stream, err := subscriber.Stream(ctx)
+
+	var got []string
+
+	for event := range stream {
+		got = append(got, event.Header.MessageID)
+	}
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, got)
+	assert.Equal(t, []string{"2", "3"}, eventBus.acknowledged)
+}
+
+func TestFilterPushedDown(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	filter := routing.Filter{routing.Equals(routing.FieldDomain, "loans")}
+	eventBus := &filterEventBusMock{complete: true}
+	eventBus.withEvents([]messages.Event{eventMessageFixture()})
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus, Filter: filter})
+	ctx := context.TODO()
+	// When
+	err := subscriber.Subscribe(ctx, "orders-topic")
+	eventBus.events[0].Header.Domain = "cards"
+	got, _ := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, filter, eventBus.filter)
+	assert.Len(t, got, 1, "the event bus filters, the subscriber must not filter again")
+}
+
+func TestFilterPartiallyPushedDown(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := &filterEventBusMock{complete: false}
+	eventBus.withEvents([]messages.Event{eventMessageFixture()})
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: eventBus,
+		Filter:   routing.Filter{routing.AtLeast(routing.FieldVersion, "2")},
+	})
+	ctx := context.TODO()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	got, err := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestFilterInvalid(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: new(eventBusMock),
+		Filter:   routing.Filter{routing.Equals("tenant", "1")},
+	})
+	// When
+	err := subscriber.Subscribe(context.TODO(), "orders-topic")
+	// Then
+	assert.Error(t, err)
+}
+
+// ackEventBusMock records the acknowledged messages.
+type ackEventBusMock struct {
+	eventBusMock
+	acknowledged []string
+}
+
+func (a *ackEventBusMock) Acknowledge(_ context.Context, id string) error {
+	a.acknowledged = append(a.acknowledged, id)
+
+	return nil
+}
+
+// filterEventBusMock records the pushed down filter.
+type filterEventBusMock struct {
+	eventBusMock
+	complete bool
+	filter   routing.Filter
+}
+
+func (f *filterEventBusMock) SubscribeFiltered(_ context.Context, _ string, filter routing.Filter) (bool, error) {
+	f.filter = filter
+
+	return f.complete, nil
+}
@@ -0,0 +1,275 @@
+package subscribers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+// ErrEventBusSubscriber is implemented by event buses that can tell why their stream ended.
+type ErrEventBusSubscriber interface {
+	EventBusSubscriber
+	// Err returns the error that closed the stream or nil.
+	Err() error
+}
+
+// flow limits the streamed events waiting to be acknowledged and lets pausing the stream.
+// Events holding a slot longer than the timeout release it, so events never acknowledged nor
+// rejected do not stall the stream forever.
+type flow struct {
+	maxMessages int
+	maxBytes    int
+	timeout     time.Duration
+	now         func() time.Time
+	mu          sync.Mutex
+	paused      bool
+	inFlight    map[string]slot // slots by message id.
+	bytes       int
+	released    chan struct{}
+	err         error
+}
+
+type slot struct {
+	size       int
+	acquiredAt time.Time
+}
+
+func newFlow(maxMessages, maxBytes int, timeout time.Duration) *flow {
+	newFlow := flow{
+		maxMessages: maxMessages,
+		maxBytes:    maxBytes,
+		timeout:     timeout,
+		now:         time.Now,
+		inFlight:    make(map[string]slot),
+		released:    make(chan struct{}, 1),
+	}
+
+	return &newFlow
+}
+
+// acquire waits until the event fits in the limits and the flow is not paused, it returns
+// false when the context is done first. An event larger than the bytes limit is let through
+// alone so it does not block the stream forever.
+func (f *flow) acquire(ctx context.Context, event messages.Event) bool {
+	for {
+		f.mu.Lock()
+		wait := f.expire()
+		if f.fits(len(event.Data)) {
+			if f.limited() {
+				f.release(event.Header.MessageID)
+				f.inFlight[event.Header.MessageID] = slot{size: len(event.Data), acquiredAt: f.now()}
+				f.bytes += len(event.Data)
+			}
+
+			f.mu.Unlock()
+
+			return true
+		}
+		f.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return false
+		case <-f.released:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+	}
+}
+
+// expire releases the timed out slots and returns the time until the next one times out, the
+// lock must be held.
+func (f *flow) expire() time.Duration {
+	now := f.now()
+	wait := f.timeout
+
+	for messageID, acquired := range f.inFlight {
+		left := f.timeout - now.Sub(acquired.acquiredAt)
+		if left <= 0 {
+			f.release(messageID)
+
+			continue
+		}
+
+		if left < wait {
+			wait = left
+		}
+	}
+
+	return wait
+}
+
+// fits reports whether an event of the given size can be delivered, the lock must be held.
+func (f *flow) fits(size int) bool {
+	if f.paused {
+		return false
+	}
+
+	if f.maxMessages > 0 && len(f.inFlight) >= f.maxMessages {
+		return false
+	}
+
+	return f.maxBytes <= 0 || len(f.inFlight) == 0 || f.bytes+size <= f.maxBytes
+}
+
+func (f *flow) limited() bool {
+	return f.maxMessages > 0 || f.maxBytes > 0
+}
+
+// extend restarts the timeout of the message slot.
+func (f *flow) extend(messageID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	acquired, ok := f.inFlight[messageID]
+	if ok {
+		acquired.acquiredAt = f.now()
+		f.inFlight[messageID] = acquired
+	}
+}
+
+// done releases the message once it is acknowledged, rejected or left to the event bus.
+func (f *flow) done(messageID string) {
+	f.mu.Lock()
+	f.release(messageID)
+	f.mu.Unlock()
+
+	f.signal()
+}
+
+// release removes the message from the in flight ones, the lock must be held.
+func (f *flow) release(messageID string) {
+	acquired, ok := f.inFlight[messageID]
+	if !ok {
+		return
+	}
+
+	delete(f.inFlight, messageID)
+	f.bytes -= acquired.size
+}
+
+func (f *flow) setPaused(paused bool) {
+	f.mu.Lock()
+	f.paused = paused
+	f.mu.Unlock()
+
+	f.signal()
+}
+
+func (f *flow) signal() {
+	select {
+	case f.released <- struct{}{}:
+	default:
+	}
+}
+
+func (f *flow) setErr(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+func (f *flow) getErr() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+// Pause stops delivering streamed events until Resume is called, the event bus keeps the
+// events not read yet. The event the stream is already handing over is still delivered.
+func (s *Subscriber) Pause() {
+	s.flow.setPaused(true)
+}
+
+// Resume delivers streamed events again after Pause.
+func (s *Subscriber) Resume() {
+	s.flow.setPaused(false)
+}
+
+// Release frees the in flight slot of a streamed message left neither acknowledged nor
+// rejected, for the event bus to deliver it again once its deadline is over.
+func (s *Subscriber) Release(messageID string) {
+	s.flow.done(messageID)
+}
+
+// Err returns the error that ended the last stream. It is nil while streaming, when the
+// stream ended because its context is done or when the event bus closed it without error.
+func (s *Subscriber) Err() error {
+	return s.flow.getErr()
+}
+
+// control applies the flow limits to the event bus stream and records why it ended, cancel is
+// called once it ends.
+func (s *Subscriber) control(ctx context.Context, cancel context.CancelFunc, stream <-chan messages.Event) <-chan messages.Event {
+	result := make(chan messages.Event)
+
+	s.flow.setErr(nil)
+
+	go func() {
+		defer close(result)
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-stream:
+				if !ok {
+					s.flow.setErr(s.streamErr(ctx))
+
+					return
+				}
+
+				if len(s.filter) > 0 && !s.matches(ctx, event) {
+					continue
+				}
+
+				if !s.flow.acquire(ctx, event) {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					s.flow.done(event.Header.MessageID)
+
+					return
+				case result <- event:
+				}
+			}
+		}
+	}()
+
+	return result
+}
+
+// streamErr returns the error the event bus reports for its closed stream.
+func (s *Subscriber) streamErr(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	found := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(ErrEventBusSubscriber)
+
+		return ok
+	})
+	if found == nil {
+		return nil
+	}
+
+	err := found.(ErrEventBusSubscriber).Err()
+	if err != nil {
+		return errors.WithMessagef(err, "unexpected error streaming messages from %q", s.channelNames())
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamLimitsInFlightMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]subscribers.Settings{
+		"messages": {MaxInFlightMessages: 2},
+		"bytes":    {MaxInFlightBytes: 2 * len(eventMessageFixture().Data)},
+	}
+
+	for name, settings := range tests {
+		settings := settings
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+			defer cancel()
+
+			settings.EventBus = new(eventBusMock).withEvents(flowEventsFixture(3)).withEventStream()
+			subscriber := subscribers.New(settings)
+			_ = subscriber.Subscribe(ctx, "orders-topic")
+			// When
+			stream, err := subscriber.Stream(ctx)
+			first, second := <-stream, <-stream
+			// Then
+			assert.NoError(t, err)
+			assertNoEvent(t, stream)
+			assert.NoError(t, subscriber.Acknowledge(ctx, first.Header.MessageID))
+			assert.Equal(t, "3", (<-stream).Header.MessageID)
+			assert.Equal(t, "2", second.Header.MessageID)
+		})
+	}
+}
+
+func TestStreamReleasesInFlightSlots(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		timeout time.Duration
+		release bool
+	}{
+		"released":  {timeout: time.Hour, release: true},
+		"timed out": {timeout: 20 * time.Millisecond},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+			defer cancel()
+
+			subscriber := subscribers.New(subscribers.Settings{
+				EventBus:            new(eventBusMock).withEvents(flowEventsFixture(2)).withEventStream(),
+				MaxInFlightMessages: 1,
+				InFlightTimeout:     test.timeout,
+			})
+			_ = subscriber.Subscribe(ctx, "orders-topic")
+			stream, _ := subscriber.Stream(ctx)
+			first := <-stream
+			// When
+			if test.release {
+				subscriber.Release(first.Header.MessageID)
+			}
+
+			second := <-stream
+			// Then
+			assert.Equal(t, "2", second.Header.MessageID)
+		})
+	}
+}
+
+func TestStreamPauseAndResume(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: new(eventBusMock).withEvents(flowEventsFixture(3)).withEventStream(),
+	})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	stream, _ := subscriber.Stream(ctx)
+	received := []string{(<-stream).Header.MessageID}
+	// When
+	subscriber.Pause()
+	// the event already handed over when pausing may still arrive.
+	select {
+	case event := <-stream:
+		received = append(received, event.Header.MessageID)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assertNoEvent(t, stream)
+	subscriber.Resume()
+
+	for len(received) < 3 {
+		received = append(received, (<-stream).Header.MessageID)
+	}
+	// Then
+	assert.Equal(t, []string{"1", "2", "3"}, received)
+}
+
+func TestStreamErr(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+
+	errToReturn := errors.New("connection lost")
+	eventBus := &errEventBusMock{err: errToReturn}
+	eventBus.withEvents(flowEventsFixture(1)).withEventStream()
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	stream, err := subscriber.Stream(ctx)
+
+	for range stream {
+	}
+	// Then
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(subscriber.Err(), errToReturn))
+	assert.Contains(t, subscriber.Err().Error(), `streaming messages from "orders-topic"`)
+}
+
+func TestStreamErrIsNilWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx, cancel := context.WithCancel(context.TODO())
+	eventBus := &errEventBusMock{err: errors.New("connection lost")}
+	eventBus.withEvents(flowEventsFixture(1)).withEventStream()
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	stream, _ := subscriber.Stream(ctx)
+	// When
+	cancel()
+
+	for range stream {
+	}
+	// Then
+	assert.NoError(t, subscriber.Err())
+}
+
+func assertNoEvent(t *testing.T, stream <-chan messages.Event) {
+	t.Helper()
+
+	select {
+	case event := <-stream:
+		t.Fatalf("unexpected event: %s", event.Header.MessageID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func flowEventsFixture(count int) []messages.Event {
+	events := make([]messages.Event, count)
+
+	for idx := range events {
+		events[idx] = eventMessageFixture()
+		events[idx].Header.MessageID = string(rune('1' + idx))
+	}
+
+	return events
+}
+
+// errEventBusMock reports the given error once its stream is closed.
+type errEventBusMock struct {
+	eventBusMock
+	err error
+}
+
+func (e *errEventBusMock) Err() error {
+	return e.err
+}
@@ -0,0 +1,93 @@
+package subscribers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrGroupsNotSupported is returned when subscribing with a group or a name to an event bus
+// without consumer groups.
+var ErrGroupsNotSupported = errors.New("event bus does not support consumer groups")
+
+// Partition is a channel partition, or shard, assigned to a subscriber.
+type Partition struct {
+	Channel string
+	ID      int
+}
+
+// Rebalance contains the partitions a subscriber got and lost in a group rebalance.
+type Rebalance struct {
+	Assigned []Partition
+	Revoked  []Partition
+}
+
+// RebalanceHandler is called by the event bus on every group rebalance, revoked partitions
+// must stop being processed before it returns.
+type RebalanceHandler func(ctx context.Context, rebalance Rebalance)
+
+// Subscription defines a subscription of a consumer group to a channel.
+type Subscription struct {
+	Channel     string
+	Group       string
+	Name        string
+	OnRebalance RebalanceHandler
+}
+
+// GroupEventBusSubscriber is implemented by event buses with consumer groups, like kafka
+// consumer groups, nats durable queue groups or a sqs queue per group.
+type GroupEventBusSubscriber interface {
+	EventBusSubscriber
+	// SubscribeGroup subscribes to the channel as a member of the subscription group.
+	SubscribeGroup(ctx context.Context, subscription Subscription) error
+}
+
+// Unwrapper is implemented by middlewares to give access to the event bus they wrap, so
+// optional event bus features are reachable through a middleware chain.
+type Unwrapper interface {
+	Unwrap() EventBusSubscriber
+}
+
+// subscribe subscribes to the channel with the configured group and name, if any.
+func (s *Subscriber) subscribe(ctx context.Context, channel string) error {
+	if s.group == "" && s.name == "" {
+		return s.eventBus.Subscribe(ctx, channel)
+	}
+
+	found := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(GroupEventBusSubscriber)
+
+		return ok
+	})
+	if found == nil {
+		return ErrGroupsNotSupported
+	}
+
+	subscription := Subscription{
+		Channel:     channel,
+		Group:       s.group,
+		Name:        s.name,
+		OnRebalance: s.onRebalance,
+	}
+
+	return found.(GroupEventBusSubscriber).SubscribeGroup(ctx, subscription)
+}
+
+// find walks the middleware chain from the given event bus and returns the first event bus
+// matching the function or nil.
+func find(eventBus EventBusSubscriber, match func(EventBusSubscriber) bool) EventBusSubscriber {
+	for eventBus != nil {
+		if match(eventBus) {
+			return eventBus
+		}
+
+		unwrapper, ok := eventBus.(Unwrapper)
+		if !ok {
+			return nil
+		}
+
+		eventBus = unwrapper.Unwrap()
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeWithGroup(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var rebalances []subscribers.Rebalance
+
+	expectedRebalance := subscribers.Rebalance{
+		Assigned: []subscribers.Partition{{Channel: "orders-topic", ID: 1}},
+	}
+	eventBus := new(groupEventBusMock)
+	noop := subscribers.Transform(func(_ context.Context, event messages.Event) (messages.Event, error) {
+		return event, nil
+	})
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: subscribers.Chain(eventBus, noop),
+		Group:    "audit",
+		Name:     "audit-orders",
+		OnRebalance: func(_ context.Context, rebalance subscribers.Rebalance) {
+			rebalances = append(rebalances, rebalance)
+		},
+	})
+	ctx := context.TODO()
+	// When
+	err := subscriber.Subscribe(ctx, "orders-topic")
+	eventBus.subscription.OnRebalance(ctx, expectedRebalance)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-topic", eventBus.subscription.Channel)
+	assert.Equal(t, "audit", eventBus.subscription.Group)
+	assert.Equal(t, "audit-orders", eventBus.subscription.Name)
+	assert.Equal(t, []subscribers.Rebalance{expectedRebalance}, rebalances)
+}
+
+func TestSubscribeWithGroupNotSupported(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: new(eventBusMock),
+		Group:    "audit",
+	})
+	// When
+	err := subscriber.Subscribe(context.TODO(), "orders-topic")
+	// Then
+	assert.True(t, errors.Is(err, subscribers.ErrGroupsNotSupported))
+}
+
+type groupEventBusMock struct {
+	eventBusMock
+	subscription subscribers.Subscription
+}
+
+func (g *groupEventBusMock) SubscribeGroup(_ context.Context, subscription subscribers.Subscription) error {
+	g.subscription = subscription
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package subscribers
+
+import (
+	"context"
+	"log"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+)
+
+// Middleware decorates an event bus subscriber with extra behavior.
+type Middleware func(EventBusSubscriber) EventBusSubscriber
+
+// TransformFunc changes an event received from the event bus before it is handed over.
+type TransformFunc func(ctx context.Context, event messages.Event) (messages.Event, error)
+
+// Chain wraps the event bus with the given middlewares, the first middleware is the
+// outermost one, so it is the last to see every received event.
+func Chain(eventBus EventBusSubscriber, middlewares ...Middleware) EventBusSubscriber {
+	for idx := len(middlewares) - 1; idx >= 0; idx-- {
+		eventBus = middlewares[idx](eventBus)
+	}
+
+	return eventBus
+}
+
+// Transform returns a middleware that applies the given function to every received event.
+// Events the function fails on are logged and left out without acknowledging them, so the
+// event bus delivers them again or moves them to its dead letter channel.
+func Transform(transform TransformFunc) Middleware {
+	return func(next EventBusSubscriber) EventBusSubscriber {
+		return &transformer{
+			EventBusSubscriber: next,
+			transform:          transform,
+		}
+	}
+}
+
+type transformer struct {
+	EventBusSubscriber
+	transform TransformFunc
+}
+
+func (t *transformer) Pull(ctx context.Context, numberOfMessages uint8) ([]messages.Event, error) {
+	events, err := t.EventBusSubscriber.Pull(ctx, numberOfMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]messages.Event, 0, len(events))
+
+	for _, event := range events {
+		transformed, ok := t.apply(ctx, event)
+		if ok {
+			result = append(result, transformed)
+		}
+	}
+
+	return result, nil
+}
+
+func (t *transformer) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	stream, err := t.EventBusSubscriber.Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(chan messages.Event)
+
+	go func() {
+		defer close(result)
+
+		for event := range stream {
+			transformed, ok := t.apply(ctx, event)
+			if !ok {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case result <- transformed:
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+func (t *transformer) apply(ctx context.Context, event messages.Event) (messages.Event, bool) {
+	transformed, err := t.transform(ctx, event)
+	if err != nil {
+		log.Println(
+			"error", err,
+			"message_id", event.Header.MessageID,
+			"method", "subscribers.transformer.apply",
+		)
+
+		return messages.Event{}, false
+	}
+
+	return transformed, true
+}
+
+// Unwrap returns the wrapped event bus.
+func (t *transformer) Unwrap() EventBusSubscriber {
+	return t.EventBusSubscriber
+}
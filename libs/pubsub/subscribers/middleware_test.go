@@ -0,0 +1,75 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformPullSkipsFailedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	events := eventMessagesFixture()
+	expectedEvent := events[1]
+	expectedEvent.Data = []byte("transformed")
+	eventBus := new(eventBusMock).withEvents(events)
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        subscribers.Chain(eventBus, skipFirstMessage()),
+		MessagesPerPull: 2,
+	})
+	ctx := context.TODO()
+	// When
+	got, err := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []messages.Event{expectedEvent}, got)
+}
+
+func TestTransformStream(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	events := eventMessagesFixture()
+	expectedEvent := events[1]
+	expectedEvent.Data = []byte("transformed")
+	eventBus := new(eventBusMock).withEvents(events).withEventStream()
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus: subscribers.Chain(eventBus, skipFirstMessage()),
+	})
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+
+	defer cancel()
+	// When
+	stream, err := subscriber.Stream(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got []messages.Event
+
+	for event := range stream {
+		got = append(got, event)
+	}
+	// Then
+	assert.Equal(t, []messages.Event{expectedEvent}, got)
+}
+
+func skipFirstMessage() subscribers.Middleware {
+	return subscribers.Transform(
+		func(_ context.Context, event messages.Event) (messages.Event, error) {
+			if event.Header.MessageID == "1" {
+				return messages.Event{}, errors.New("error")
+			}
+
+			event.Data = []byte("transformed")
+
+			return event, nil
+		},
+	)
+}
@@ -0,0 +1,335 @@
+package subscribers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+// DefaultInFlightTimeout is the longest acknowledgement deadline of the usual event buses.
+const DefaultInFlightTimeout = 10 * time.Minute
+
+var errUnknownMessage = errors.New("message is not in flight")
+
+// NackEventBusSubscriber is implemented by event buses that can reject a message and deliver
+// it again after a delay, like sqs ChangeMessageVisibility or nats NakWithDelay.
+type NackEventBusSubscriber interface {
+	EventBusSubscriber
+	// Nack rejects the message, it is delivered again once the delay is over.
+	Nack(ctx context.Context, ID string, delay time.Duration) error
+}
+
+// DeadlineEventBusSubscriber is implemented by event buses that redeliver messages not
+// acknowledged within a deadline and can extend it, like sqs visibility timeouts or nats
+// in progress acknowledgements.
+type DeadlineEventBusSubscriber interface {
+	EventBusSubscriber
+	// ExtendDeadline gives the message the given duration from now to be acknowledged.
+	ExtendDeadline(ctx context.Context, ID string, duration time.Duration) error
+}
+
+// Nack rejects the given message so it is delivered again after the delay. Event buses
+// without native support get it emulated, the event is delivered again by this subscriber.
+func (s *Subscriber) Nack(ctx context.Context, messageID string, delay time.Duration) error {
+	eventBus := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(NackEventBusSubscriber)
+
+		return ok
+	})
+
+	err := eventBus.(NackEventBusSubscriber).Nack(ctx, messageID, delay)
+	if err != nil {
+		return errors.WithMessagef(err, "unexpected error rejecting message: %q", messageID)
+	}
+
+	s.flow.done(messageID)
+
+	return nil
+}
+
+// ExtendDeadline gives the message the given duration from now to be acknowledged and
+// restarts its in flight timeout. It does nothing else on event buses without acknowledgement
+// deadlines.
+func (s *Subscriber) ExtendDeadline(ctx context.Context, messageID string, duration time.Duration) error {
+	eventBus := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(DeadlineEventBusSubscriber)
+
+		return ok
+	})
+
+	err := eventBus.(DeadlineEventBusSubscriber).ExtendDeadline(ctx, messageID, duration)
+	if err != nil {
+		return errors.WithMessagef(err, "unexpected error extending message deadline: %q", messageID)
+	}
+
+	s.flow.extend(messageID)
+
+	return nil
+}
+
+// Heartbeat extends the message deadline by the extension every interval, while a slow
+// handler runs, until the returned stop function is called or the context is done.
+func (s *Subscriber) Heartbeat(ctx context.Context, messageID string, interval, extension time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.ExtendDeadline(ctx, messageID, extension)
+				if err != nil && ctx.Err() == nil {
+					log.Println("error", err, "message_id", messageID, "method", "subscribers.Subscriber.Heartbeat")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+// withRedelivery wraps the event bus with the emulation of the features it lacks.
+func withRedelivery(eventBus EventBusSubscriber, timeout time.Duration) EventBusSubscriber {
+	nacker, _ := find(eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(NackEventBusSubscriber)
+
+		return ok
+	}).(NackEventBusSubscriber)
+	extender, _ := find(eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(DeadlineEventBusSubscriber)
+
+		return ok
+	}).(DeadlineEventBusSubscriber)
+
+	if nacker != nil && extender != nil {
+		return eventBus
+	}
+
+	return &redeliverer{
+		EventBusSubscriber: eventBus,
+		nacker:             nacker,
+		extender:           extender,
+		timeout:            timeout,
+		now:                time.Now,
+		inFlight:           make(map[string]tracked),
+		notify:             make(chan struct{}, 1),
+	}
+}
+
+// redeliverer emulates Nack by keeping the events in flight and delivering them again once
+// the delay is over, and ExtendDeadline as a no-op, when the event bus lacks them. Events
+// neither acknowledged nor rejected within the timeout are forgotten and can not be rejected
+// anymore, extending their deadline restarts it.
+type redeliverer struct {
+	EventBusSubscriber
+	nacker   NackEventBusSubscriber
+	extender DeadlineEventBusSubscriber
+	timeout  time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	inFlight map[string]tracked
+	sweptAt  time.Time
+	ready    []messages.Event
+	notify   chan struct{}
+}
+
+type tracked struct {
+	event     messages.Event
+	trackedAt time.Time
+}
+
+func (r *redeliverer) Pull(ctx context.Context, numberOfMessages uint8) ([]messages.Event, error) {
+	if r.nacker != nil {
+		return r.EventBusSubscriber.Pull(ctx, numberOfMessages)
+	}
+
+	result := r.takeReady(int(numberOfMessages))
+	if numberOfMessages > 0 && len(result) >= int(numberOfMessages) {
+		return result, nil
+	}
+
+	remaining := numberOfMessages
+	if numberOfMessages > 0 {
+		remaining -= uint8(len(result))
+	}
+
+	events, err := r.EventBusSubscriber.Pull(ctx, remaining)
+	if err != nil && len(result) == 0 {
+		return nil, err
+	}
+
+	r.track(events...)
+
+	return append(result, events...), nil
+}
+
+func (r *redeliverer) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	stream, err := r.EventBusSubscriber.Stream(ctx)
+	if err != nil || r.nacker != nil {
+		return stream, err
+	}
+
+	result := make(chan messages.Event)
+
+	go func() {
+		defer close(result)
+
+		for {
+			var pending []messages.Event
+
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-stream:
+				if !ok {
+					return
+				}
+
+				r.track(event)
+				pending = []messages.Event{event}
+			case <-r.notify:
+				pending = r.takeReady(0)
+			}
+
+			for _, event := range pending {
+				select {
+				case <-ctx.Done():
+					return
+				case result <- event:
+				}
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+func (r *redeliverer) Acknowledge(ctx context.Context, messageID string) error {
+	err := r.EventBusSubscriber.Acknowledge(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	delete(r.inFlight, messageID)
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *redeliverer) Nack(ctx context.Context, messageID string, delay time.Duration) error {
+	if r.nacker != nil {
+		return r.nacker.Nack(ctx, messageID, delay)
+	}
+
+	r.mu.Lock()
+	message, ok := r.inFlight[messageID]
+	delete(r.inFlight, messageID)
+	r.mu.Unlock()
+
+	if !ok || r.now().Sub(message.trackedAt) > r.timeout {
+		return errors.WithMessagef(errUnknownMessage, "%q", messageID)
+	}
+
+	if delay <= 0 {
+		r.redeliver(message.event)
+
+		return nil
+	}
+
+	time.AfterFunc(delay, func() { r.redeliver(message.event) })
+
+	return nil
+}
+
+func (r *redeliverer) ExtendDeadline(ctx context.Context, messageID string, duration time.Duration) error {
+	if r.extender != nil {
+		return r.extender.ExtendDeadline(ctx, messageID, duration)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.inFlight[messageID]
+	if ok {
+		message.trackedAt = r.now()
+		r.inFlight[messageID] = message
+	}
+
+	return nil
+}
+
+// Unwrap returns the wrapped event bus.
+func (r *redeliverer) Unwrap() EventBusSubscriber {
+	return r.EventBusSubscriber
+}
+
+func (r *redeliverer) track(events ...messages.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trackLocked(events)
+}
+
+// trackLocked keeps the events in flight and forgets the timed out ones, looking for them at
+// most twice per timeout. The lock must be held.
+func (r *redeliverer) trackLocked(events []messages.Event) {
+	now := r.now()
+
+	if now.Sub(r.sweptAt) > r.timeout/2 {
+		r.sweptAt = now
+
+		for messageID, message := range r.inFlight {
+			if now.Sub(message.trackedAt) > r.timeout {
+				delete(r.inFlight, messageID)
+			}
+		}
+	}
+
+	for _, event := range events {
+		r.inFlight[event.Header.MessageID] = tracked{event: event, trackedAt: now}
+	}
+}
+
+func (r *redeliverer) redeliver(event messages.Event) {
+	r.mu.Lock()
+	r.ready = append(r.ready, event)
+	r.mu.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// takeReady removes up to max events ready to be delivered again and tracks them as in flight,
+// every ready event when max is zero.
+func (r *redeliverer) takeReady(max int) []messages.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := len(r.ready)
+	if max > 0 && max < count {
+		count = max
+	}
+
+	result := append([]messages.Event(nil), r.ready[:count]...)
+	r.ready = r.ready[count:]
+	r.trackLocked(result)
+
+	return result
+}
@@ -0,0 +1,174 @@
+package subscribers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNackRedeliversAfterDelay(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        &pullOnceEventBusMock{events: []messages.Event{eventMessageFixture()}},
+		MessagesPerPull: 1,
+	})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	pulled, _ := subscriber.Pull(ctx)
+	// When
+	err := subscriber.Nack(ctx, pulled[0].Header.MessageID, 20*time.Millisecond)
+	early, _ := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, early)
+	assert.Eventually(t, func() bool {
+		redelivered, _ := subscriber.Pull(ctx)
+
+		return len(redelivered) == 1 && redelivered[0].Header.ID == pulled[0].Header.ID
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNackAfterInFlightTimeout(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	subscriber := subscribers.New(subscribers.Settings{
+		EventBus:        &pullOnceEventBusMock{events: []messages.Event{eventMessageFixture()}},
+		MessagesPerPull: 1,
+		InFlightTimeout: 10 * time.Millisecond,
+	})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	pulled, _ := subscriber.Pull(ctx)
+	// When
+	time.Sleep(20 * time.Millisecond)
+
+	err := subscriber.Nack(ctx, pulled[0].Header.MessageID, 0)
+	// Then
+	assert.Error(t, err)
+}
+
+func TestPullEveryMessageWithRedeliveredOnes(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	ctx := context.TODO()
+	eventBus := &pullOnceEventBusMock{events: []messages.Event{eventMessageFixture()}}
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	pulled, _ := subscriber.Pull(ctx)
+	_ = subscriber.Nack(ctx, pulled[0].Header.MessageID, 0)
+	// When
+	redelivered, err := subscriber.Pull(ctx)
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+	assert.Equal(t, []uint8{0, 0}, eventBus.requested)
+}
+
+func TestNackUnknownMessage(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	subscriber := subscribers.New(subscribers.Settings{EventBus: new(eventBusMock)})
+	// When
+	err := subscriber.Nack(context.TODO(), "unknown", 0)
+	// Then
+	assert.Error(t, err)
+}
+
+func TestNackAndExtendDeadlineUseNativeSupport(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(redeliveryEventBusMock)
+	noop := subscribers.Transform(func(_ context.Context, event messages.Event) (messages.Event, error) {
+		return event, nil
+	})
+	subscriber := subscribers.New(subscribers.Settings{EventBus: subscribers.Chain(eventBus, noop)})
+	ctx := context.TODO()
+	// When
+	errNack := subscriber.Nack(ctx, "1", time.Minute)
+	errExtend := subscriber.ExtendDeadline(ctx, "2", time.Minute)
+	// Then
+	assert.NoError(t, errNack)
+	assert.NoError(t, errExtend)
+	assert.Equal(t, []string{"1"}, eventBus.nacks)
+	assert.Equal(t, []string{"2"}, eventBus.extensions())
+}
+
+func TestHeartbeatExtendsDeadlineUntilStopped(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(redeliveryEventBusMock)
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	// When
+	stop := subscriber.Heartbeat(context.TODO(), "1", 5*time.Millisecond, time.Minute)
+
+	assert.Eventually(t, func() bool { return len(eventBus.extensions()) >= 2 }, time.Second, time.Millisecond)
+	stop()
+
+	extended := len(eventBus.extensions())
+
+	time.Sleep(20 * time.Millisecond)
+	// Then
+	assert.Equal(t, extended, len(eventBus.extensions()))
+}
+
+// pullOnceEventBusMock returns its events on the first pull only.
+type pullOnceEventBusMock struct {
+	eventBusMock
+	events    []messages.Event
+	pulled    bool
+	requested []uint8
+}
+
+func (p *pullOnceEventBusMock) Pull(_ context.Context, numberOfMessages uint8) ([]messages.Event, error) {
+	p.requested = append(p.requested, numberOfMessages)
+
+	if p.pulled {
+		return nil, nil
+	}
+
+	p.pulled = true
+
+	return p.events, nil
+}
+
+// redeliveryEventBusMock records the rejected messages and the extended deadlines.
+type redeliveryEventBusMock struct {
+	eventBusMock
+	mu       sync.Mutex
+	nacks    []string
+	extended []string
+}
+
+func (r *redeliveryEventBusMock) Nack(_ context.Context, id string, _ time.Duration) error {
+	r.nacks = append(r.nacks, id)
+
+	return nil
+}
+
+func (r *redeliveryEventBusMock) ExtendDeadline(_ context.Context, id string, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.extended = append(r.extended, id)
+
+	return nil
+}
+
+func (r *redeliveryEventBusMock) extensions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.extended...)
+}
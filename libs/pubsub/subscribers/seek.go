@@ -0,0 +1,91 @@
+package subscribers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSeekNotSupported is returned when seeking on an event bus that does not retain the
+// channel history, like sqs or sns.
+var ErrSeekNotSupported = errors.New("event bus does not support seeking")
+
+// PositionKind defines how a seek position is given.
+type PositionKind int
+
+const (
+	// PositionEarliest the oldest event the event bus retains.
+	PositionEarliest PositionKind = iota
+	// PositionLatest only events published after seeking.
+	PositionLatest
+	// PositionTime the first event published at or after the position time.
+	PositionTime
+	// PositionOffset the event at the position offset.
+	PositionOffset
+)
+
+// Position is a point of a channel history to seek to.
+type Position struct {
+	Kind PositionKind
+	Time time.Time
+	// Offset offset or sequence number in the event bus format, kafka offsets and nats
+	// sequences are decimal numbers while kinesis sequence numbers are opaque strings.
+	Offset string
+}
+
+// Earliest returns the position of the oldest retained event.
+func Earliest() Position {
+	return Position{Kind: PositionEarliest}
+}
+
+// Latest returns the position right after the newest event.
+func Latest() Position {
+	return Position{Kind: PositionLatest}
+}
+
+// AtTime returns the position of the first event published at or after the given time.
+func AtTime(at time.Time) Position {
+	return Position{Kind: PositionTime, Time: at}
+}
+
+// AtOffset returns the position of the event with the given offset or sequence number.
+func AtOffset(offset string) Position {
+	return Position{Kind: PositionOffset, Offset: offset}
+}
+
+// SeekEventBusSubscriber is implemented by event buses that retain the channel history, like
+// kafka, kinesis or nats jetstream.
+type SeekEventBusSubscriber interface {
+	EventBusSubscriber
+	// Seek moves the subscription to the channel to the given position, the next pulled or
+	// streamed events start there.
+	Seek(ctx context.Context, channel string, position Position) error
+}
+
+// Seek moves the subscription to the given channel, or channel pattern, to the position so
+// past events are processed again or skipped.
+func (s *Subscriber) Seek(ctx context.Context, channel string, position Position) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscribed(channel) < 0 {
+		return errors.WithMessagef(errNotSubscribed, "%q", channel)
+	}
+
+	found := find(s.eventBus, func(eventBus EventBusSubscriber) bool {
+		_, ok := eventBus.(SeekEventBusSubscriber)
+
+		return ok
+	})
+	if found == nil {
+		return errors.WithMessagef(ErrSeekNotSupported, "%q", channel)
+	}
+
+	err := found.(SeekEventBusSubscriber).Seek(ctx, channel, position)
+	if err != nil {
+		return errors.WithMessagef(err, "unexpected error seeking channel %q", channel)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package subscribers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeek(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	eventBus := new(seekEventBusMock)
+	subscriber := subscribers.New(subscribers.Settings{EventBus: eventBus})
+	ctx := context.TODO()
+	at := time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	err := subscriber.Seek(ctx, "orders-topic", subscribers.AtTime(at))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-topic", eventBus.channel)
+	assert.Equal(t, subscribers.Position{Kind: subscribers.PositionTime, Time: at}, eventBus.position)
+}
+
+func TestSeekErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	subscriber := subscribers.New(subscribers.Settings{EventBus: new(eventBusMock)})
+	ctx := context.TODO()
+	_ = subscriber.Subscribe(ctx, "orders-topic")
+	// When
+	errNotSupported := subscriber.Seek(ctx, "orders-topic", subscribers.Earliest())
+	errNotSubscribed := subscriber.Seek(ctx, "payments-topic", subscribers.Earliest())
+	// Then
+	assert.True(t, errors.Is(errNotSupported, subscribers.ErrSeekNotSupported))
+	assert.Error(t, errNotSubscribed)
+	assert.False(t, errors.Is(errNotSubscribed, subscribers.ErrSeekNotSupported))
+}
+
+// seekEventBusMock records the last seek.
+type seekEventBusMock struct {
+	eventBusMock
+	channel  string
+	position subscribers.Position
+}
+
+func (s *seekEventBusMock) Seek(_ context.Context, channel string, position subscribers.Position) error {
+	s.channel = channel
+	s.position = position
+
+	return nil
+}
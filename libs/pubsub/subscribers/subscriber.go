@@ -2,8 +2,11 @@ package subscribers
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/routing"
 	"github.com/pkg/errors"
 )
 
@@ -17,60 +20,139 @@ type EventBusSubscriber interface {
 
 type Subscriber struct {
 	eventBus        EventBusSubscriber
-	channel         string
+	mu              sync.Mutex
+	channels        []string
 	messagesPerPull uint8
+	group           string
+	name            string
+	onRebalance     RebalanceHandler
+	flow            *flow
+	filter          routing.Filter
+	clientFilter    bool
+	closed          bool
+	closing         chan struct{} // closed by Close to end the open streams.
 }
 
 type Settings struct {
 	EventBus        EventBusSubscriber
 	MessagesPerPull uint8
+	// Group consumer group, subscribers of the same group share the channel events while
+	// every group gets its own copy. Leave it empty to get every event of the channel.
+	Group string
+	// Name durable subscription name, the event bus keeps the subscription position under it.
+	Name string
+	// OnRebalance optional callback called when the event bus changes the partitions assigned
+	// to this subscriber.
+	OnRebalance RebalanceHandler
+	// MaxInFlightMessages maximum number of streamed events waiting to be acknowledged or
+	// rejected, the stream stops reading from the event bus when it is reached. Zero means
+	// no limit.
+	MaxInFlightMessages int
+	// MaxInFlightBytes maximum data size in bytes of the streamed events waiting to be
+	// acknowledged or rejected. Zero means no limit.
+	MaxInFlightBytes int
+	// Filter only the events it matches are delivered. It is pushed down to event buses able
+	// to filter and applied client side otherwise, events filtered out there are acknowledged.
+	Filter routing.Filter
+	// InFlightTimeout time a delivered event can stay neither acknowledged nor rejected before
+	// it is forgotten, it frees its in flight slot and on event buses without native rejections
+	// it can not be rejected anymore. Extending the event deadline restarts it. Defaults to
+	// DefaultInFlightTimeout.
+	InFlightTimeout time.Duration
 }
 
 var errNoChannelName = errors.New("must provide a channel name")
 
 func New(settings Settings) *Subscriber {
+	if settings.InFlightTimeout <= 0 {
+		settings.InFlightTimeout = DefaultInFlightTimeout
+	}
+
 	newSubscriber := Subscriber{
-		eventBus:        settings.EventBus,
-		channel:         "",
+		eventBus:        withRedelivery(settings.EventBus, settings.InFlightTimeout),
+		channels:        nil,
 		messagesPerPull: settings.MessagesPerPull,
+		group:           settings.Group,
+		name:            settings.Name,
+		onRebalance:     settings.OnRebalance,
+		flow:            newFlow(settings.MaxInFlightMessages, settings.MaxInFlightBytes, settings.InFlightTimeout),
+		filter:          settings.Filter,
+		closing:         make(chan struct{}),
 	}
 
 	return &newSubscriber
 }
 
+// Subscribe adds the channel, or channel pattern like credit.*.created, to the channels the
+// subscriber listens to. Subscribing again to the same channel does nothing.
 func (s *Subscriber) Subscribe(ctx context.Context, channel string) error {
 	if channel == "" {
 		return errNoChannelName
 	}
 
-	err := s.eventBus.Subscribe(ctx, channel)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSubscriberClosed
+	}
+
+	if s.subscribed(channel) >= 0 {
+		return nil
+	}
+
+	err := s.filter.Validate()
+	if err != nil {
+		return err
+	}
+
+	if len(s.filter) > 0 {
+		err = s.subscribeFiltered(ctx, channel)
+	} else {
+		err = s.subscribe(ctx, channel)
+	}
+
 	if err != nil {
 		return errors.WithMessagef(err, "unexpected error subscribing to channel %q", channel)
 	}
 
-	s.channel = channel
+	s.channels = append(s.channels, channel)
 
 	return nil
 }
 
 // Pull calls the event bus pull method to get the configured number of messages.
 func (s *Subscriber) Pull(ctx context.Context) ([]messages.Event, error) {
+	if s.isClosed() {
+		return nil, ErrSubscriberClosed
+	}
+
 	result, err := s.eventBus.Pull(ctx, s.messagesPerPull)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "unexpected error pulling messages from %q", s.channel)
+		return nil, errors.WithMessagef(err, "unexpected error pulling messages from %q", s.channelNames())
 	}
 
-	return result, nil
+	return s.keep(ctx, result), nil
 }
 
-// Stream calls the event bus stream method to get a stream of events.
+// Stream calls the event bus stream method to get a stream of events, it honors the filter,
+// the in flight limits and Pause. Err tells why the stream ended once it is closed, the stream
+// also ends when the subscriber is closed.
 func (s *Subscriber) Stream(ctx context.Context) (<-chan messages.Event, error) {
+	if s.isClosed() {
+		return nil, ErrSubscriberClosed
+	}
+
+	ctx, cancel := s.untilClosed(ctx)
+
 	stream, err := s.eventBus.Stream(ctx)
 	if err != nil {
-		return nil, errors.WithMessagef(err, "unexpected error streaming messages from %q", s.channel)
+		cancel()
+
+		return nil, errors.WithMessagef(err, "unexpected error streaming messages from %q", s.channelNames())
 	}
 
-	return stream, nil
+	return s.control(ctx, cancel, stream), nil
 }
 
 // Acknowledge acknowledge a given message id to avoid processing it again.
@@ -80,5 +162,7 @@ func (s *Subscriber) Acknowledge(ctx context.Context, messageID string) error {
 		return errors.WithMessagef(err, "unexpected error acknowledging message: %q", messageID)
 	}
 
+	s.flow.done(messageID)
+
 	return nil
 }
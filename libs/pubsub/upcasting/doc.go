@@ -0,0 +1,3 @@
+// Package upcasting provides a registry of upcasters that migrate events of older versions,
+// step by step, to the latest version before handlers see them.
+package upcasting
@@ -0,0 +1,126 @@
+package upcasting
+
+import (
+	"context"
+	"sync"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/consumers"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/subscribers"
+	"github.com/pkg/errors"
+)
+
+var (
+	errDuplicatedUpcaster = errors.New("an upcaster is already registered for the version")
+	errSameVersion        = errors.New("upcasters must change the version")
+	errUpcastCycle        = errors.New("upcasters form a cycle")
+)
+
+// Upcaster migrates an event to the next version, the registry sets the new version on the
+// returned header.
+type Upcaster func(ctx context.Context, event messages.Event) (messages.Event, error)
+
+// Registry keeps the upcasters of every event type by the version they migrate from.
+type Registry struct {
+	mu    sync.RWMutex
+	steps map[version]step
+}
+
+type version struct {
+	domain, eventType, version string
+}
+
+type step struct {
+	to       string
+	upcaster Upcaster
+}
+
+// NewRegistry instances a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{steps: make(map[version]step)}
+}
+
+// Register adds the upcaster migrating the events of the domain and type from one version to
+// the next one.
+func (r *Registry) Register(domain, eventType, from, to string, upcaster Upcaster) error {
+	if from == to {
+		return errors.WithMessagef(errSameVersion, "%s %s %q", domain, eventType, from)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := version{domain: domain, eventType: eventType, version: from}
+	if _, ok := r.steps[key]; ok {
+		return errors.WithMessagef(errDuplicatedUpcaster, "%s %s %q", domain, eventType, from)
+	}
+
+	r.steps[key] = step{to: to, upcaster: upcaster}
+
+	return nil
+}
+
+// Upcast applies the upcasters of the event one after the other until its version has no
+// upcaster, events already on the latest version are returned as they are.
+func (r *Registry) Upcast(ctx context.Context, event messages.Event) (messages.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for applied := 0; ; applied++ {
+		key := version{domain: event.Header.Domain, eventType: event.Header.EventType, version: event.Header.Version}
+
+		next, ok := r.steps[key]
+		if !ok {
+			return event, nil
+		}
+
+		if applied == len(r.steps) {
+			return messages.Event{}, errors.WithMessagef(errUpcastCycle, "%s %s", key.domain, key.eventType)
+		}
+
+		upcasted, err := next.upcaster(ctx, event)
+		if err != nil {
+			return messages.Event{}, errors.WithMessagef(
+				err, "could not upcast event %q from version %q to %q", event.Header.ID, key.version, next.to,
+			)
+		}
+
+		upcasted.Header.Version = next.to
+		event = upcasted
+	}
+}
+
+// UpcastAll upcasts every event, like the events of a stream read from an event store.
+func (r *Registry) UpcastAll(ctx context.Context, events []messages.Event) ([]messages.Event, error) {
+	result := make([]messages.Event, len(events))
+
+	for idx, event := range events {
+		upcasted, err := r.Upcast(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		result[idx] = upcasted
+	}
+
+	return result, nil
+}
+
+// Subscriber returns a subscriber middleware that upcasts every received event, events that
+// fail to upcast are left unacknowledged.
+func (r *Registry) Subscriber() subscribers.Middleware {
+	return subscribers.Transform(r.Upcast)
+}
+
+// Handler returns a consumer handler that upcasts every event before handing it over, a
+// failed upcast fails the event.
+func (r *Registry) Handler(handler consumers.Handler) consumers.Handler {
+	return func(ctx context.Context, event messages.Event) error {
+		upcasted, err := r.Upcast(ctx, event)
+		if err != nil {
+			return err
+		}
+
+		return handler(ctx, upcasted)
+	}
+}
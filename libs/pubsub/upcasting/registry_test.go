@@ -0,0 +1,160 @@
+package upcasting_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/upcasting"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/upcasting/upcastingtest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpcastGolden(t *testing.T) {
+	t.Parallel()
+
+	registry := registryFixture(t)
+
+	for _, name := range []string{"loan-created-v1", "loan-created-v2", "loan-created-v3"} {
+		name := name
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			upcastingtest.AssertGolden(t, registry, name)
+		})
+	}
+}
+
+func TestUpcastAll(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	registry := registryFixture(t)
+	events := []messages.Event{
+		loanCreatedFixture("1", `{"amount": 100}`),
+		loanCreatedFixture("2", `{"amount": 200}`),
+	}
+	// When
+	got, err := registry.UpcastAll(context.TODO(), events)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "3", got[1].Header.Version)
+	assert.JSONEq(t, `{"principal": 200, "currency": "USD"}`, string(got[1].Data))
+	assert.Equal(t, "1", events[0].Header.Version)
+}
+
+func TestUpcastErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	registry := registryFixture(t)
+	// When
+	_, err := registry.Upcast(context.TODO(), loanCreatedFixture("1", `not json`))
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `from version "1" to "2"`)
+}
+
+func TestUpcastCycle(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	registry := upcasting.NewRegistry()
+	identity := func(_ context.Context, event messages.Event) (messages.Event, error) { return event, nil }
+	_ = registry.Register("loans", "loan-created", "1", "2", identity)
+	_ = registry.Register("loans", "loan-created", "2", "1", identity)
+	// When
+	_, err := registry.Upcast(context.TODO(), loanCreatedFixture("1", `{}`))
+	// Then
+	assert.Error(t, err)
+}
+
+func TestRegisterErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	registry := registryFixture(t)
+	identity := func(_ context.Context, event messages.Event) (messages.Event, error) { return event, nil }
+	// When
+	errDuplicated := registry.Register("loans", "loan-created", "1", "4", identity)
+	errSameVersion := registry.Register("loans", "loan-created", "4", "4", identity)
+	// Then
+	assert.Error(t, errDuplicated)
+	assert.Error(t, errSameVersion)
+}
+
+func TestHandlerUpcastsEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var got messages.Event
+
+	registry := registryFixture(t)
+	handler := registry.Handler(func(_ context.Context, event messages.Event) error {
+		got = event
+
+		return nil
+	})
+	// When
+	err := handler(context.TODO(), loanCreatedFixture("1", `{"amount": 100}`))
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "3", got.Header.Version)
+}
+
+// registryFixture renames amount to principal in version 2 and adds the currency in version 3.
+func registryFixture(t *testing.T) *upcasting.Registry {
+	t.Helper()
+
+	registry := upcasting.NewRegistry()
+
+	err := registry.Register("loans", "loan-created", "1", "2", editData(func(data map[string]interface{}) {
+		data["principal"] = data["amount"]
+		delete(data, "amount")
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = registry.Register("loans", "loan-created", "2", "3", editData(func(data map[string]interface{}) {
+		data["currency"] = "USD"
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return registry
+}
+
+func editData(edit func(map[string]interface{})) upcasting.Upcaster {
+	return func(_ context.Context, event messages.Event) (messages.Event, error) {
+		var data map[string]interface{}
+
+		err := json.Unmarshal(event.Data, &data)
+		if err != nil {
+			return messages.Event{}, errors.Wrap(err, "invalid data")
+		}
+
+		edit(data)
+
+		event.Data, err = json.Marshal(data)
+
+		return event, errors.Wrap(err, "invalid data")
+	}
+}
+
+func loanCreatedFixture(id, data string) messages.Event {
+	return messages.Event{
+		Header: messages.Header{
+			ID:          id,
+			Domain:      "loans",
+			EventType:   "loan-created",
+			Version:     "1",
+			Application: "credit-app",
+		},
+		Data: []byte(data),
+	}
+}
@@ -0,0 +1,110 @@
+// Package upcastingtest provides golden file tests for upcasters.
+package upcastingtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/upcasting"
+	"github.com/stretchr/testify/assert"
+)
+
+// UpdateEnv environment variable that rewrites the golden files with the current output when
+// it is set to 1.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// File is the json layout of the input and golden files, data must be json.
+type File struct {
+	Header Header          `json:"header"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Header is the event header of the input and golden files, transport fields such as the
+// message id or the channel are left out because upcasters do not change them.
+type Header struct {
+	ID          string            `json:"id"`
+	Domain      string            `json:"domain"`
+	EventType   string            `json:"event_type"`
+	Version     string            `json:"version"`
+	Application string            `json:"application,omitempty"`
+	OrderingKey string            `json:"ordering_key,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+func headerOf(header messages.Header) Header {
+	return Header{
+		ID:          header.ID,
+		Domain:      header.Domain,
+		EventType:   header.EventType,
+		Version:     header.Version,
+		Application: header.Application,
+		OrderingKey: header.OrderingKey,
+		Attributes:  header.Attributes,
+	}
+}
+
+func (h Header) header() messages.Header {
+	return messages.Header{
+		ID:          h.ID,
+		Domain:      h.Domain,
+		EventType:   h.EventType,
+		Version:     h.Version,
+		Application: h.Application,
+		OrderingKey: h.OrderingKey,
+		Attributes:  h.Attributes,
+	}
+}
+
+// AssertGolden upcasts the event of testdata/<name>.input.json and compares it with
+// testdata/<name>.golden.json.
+func AssertGolden(t *testing.T, registry *upcasting.Registry, name string) {
+	t.Helper()
+
+	var input File
+
+	readJSON(t, filepath.Join("testdata", name+".input.json"), &input)
+
+	upcasted, err := registry.Upcast(context.Background(), messages.Event{Header: input.Header.header(), Data: input.Data})
+	if err != nil {
+		t.Fatalf("unexpected error upcasting %s: %s", name, err)
+	}
+
+	got, err := json.MarshalIndent(File{Header: headerOf(upcasted.Header), Data: upcasted.Data}, "", "  ")
+	if err != nil {
+		t.Fatalf("upcasted data of %s is not json: %s", name, err)
+	}
+
+	goldenPath := filepath.Join("testdata", name+".golden.json")
+
+	if os.Getenv(UpdateEnv) == "1" {
+		err = os.WriteFile(goldenPath, append(got, '\n'), 0o600)
+		if err != nil {
+			t.Fatalf("could not update %s: %s", goldenPath, err)
+		}
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("could not read %s, run with %s=1 to create it: %s", goldenPath, UpdateEnv, err)
+	}
+
+	assert.JSONEq(t, string(expected), string(got))
+}
+
+func readJSON(t *testing.T, path string, value interface{}) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read %s: %s", path, err)
+	}
+
+	err = json.Unmarshal(content, value)
+	if err != nil {
+		t.Fatalf("could not decode %s: %s", path, err)
+	}
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned when an endpoint is skipped because its circuit is open.
+var ErrCircuitOpen = errors.New("webhook endpoint circuit is open")
+
+// breaker is a per endpoint circuit breaker, it opens after a number of consecutive failures
+// and lets a single trial request through once the open timeout is over.
+type breaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	failures    int
+	openedAt    time.Time
+	trialActive bool
+}
+
+func newBreaker(threshold int, openTimeout time.Duration) *breaker {
+	return &breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// allow reports whether a request can be sent at the given time.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	if b.trialActive || now.Sub(b.openedAt) < b.openTimeout {
+		return false
+	}
+
+	b.trialActive = true
+
+	return true
+}
+
+// record registers the outcome of a request sent at the given time.
+func (b *breaker) record(now time.Time, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialActive = false
+
+	if success {
+		b.failures = 0
+
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = now
+	}
+}
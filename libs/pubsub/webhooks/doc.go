@@ -0,0 +1,7 @@
+// Package webhooks provides an event bus publisher that pushes events to http webhooks and
+// the http handler receivers use to verify them.
+//
+// Events are sent as CloudEvents in binary content mode. Every request is signed with
+// HMAC-SHA256 over the timestamp, the event attribute headers and the body, so receivers can
+// check both who sent it and that it is not being replayed.
+package webhooks
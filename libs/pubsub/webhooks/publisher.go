@@ -0,0 +1,271 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/cloudevents"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTimeout          = 10 * time.Second
+	defaultMaxRetries       = 3
+	defaultRetryBackoff     = 100 * time.Millisecond
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+var (
+	errNoEndpoints    = errors.New("no webhook endpoint configured for channel")
+	errUnexpectedCode = errors.New("unexpected webhook response status")
+)
+
+// Endpoint defines a webhook receiver.
+type Endpoint struct {
+	// URL where events are posted to.
+	URL string
+	// Secret shared with the receiver to sign requests.
+	Secret []byte
+	// Channels the channels delivered to this endpoint, every channel when it is empty.
+	Channels []string
+}
+
+// Settings contains the webhook publisher configuration, zero values take defaults.
+type Settings struct {
+	Endpoints []Endpoint
+	// Client http client used to send requests, it defaults to a client with a 10s timeout.
+	Client *http.Client
+	// MaxRetries number of retries after a failed attempt, defaults to 3. Use a negative
+	// number to disable retries.
+	MaxRetries int
+	// RetryBackoff wait before the first retry, it doubles on every retry. Defaults to 100ms.
+	RetryBackoff time.Duration
+	// FailureThreshold consecutive failed deliveries that open an endpoint circuit, defaults to 5.
+	FailureThreshold int
+	// OpenTimeout how long a circuit stays open before a trial request, defaults to 30s.
+	OpenTimeout time.Duration
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+}
+
+// Publisher is an event bus publisher that posts events to webhooks.
+type Publisher struct {
+	endpoints    []endpoint
+	client       *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	now          func() time.Time
+}
+
+type endpoint struct {
+	Endpoint
+	breaker *breaker
+}
+
+// New instances a new webhook publisher.
+func New(settings Settings) *Publisher {
+	settings = withDefaults(settings)
+	endpoints := make([]endpoint, len(settings.Endpoints))
+
+	for idx, value := range settings.Endpoints {
+		endpoints[idx] = endpoint{
+			Endpoint: value,
+			breaker:  newBreaker(settings.FailureThreshold, settings.OpenTimeout),
+		}
+	}
+
+	newPublisher := Publisher{
+		endpoints:    endpoints,
+		client:       settings.Client,
+		maxRetries:   settings.MaxRetries,
+		retryBackoff: settings.RetryBackoff,
+		now:          settings.Now,
+	}
+
+	return &newPublisher
+}
+
+// Publish posts the given event to every endpoint configured for the message channel.
+// Every endpoint is tried, the first failure is returned.
+func (p *Publisher) Publish(ctx context.Context, messageChannel string, message interface{}) error {
+	event, err := messages.AsEvent(message)
+	if err != nil {
+		return err
+	}
+
+	cloudEvent, err := cloudevents.FromMessage(event)
+	if err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+
+	body, err := cloudevents.WriteHTTP(header, cloudEvent, cloudevents.Binary)
+	if err != nil {
+		return err
+	}
+
+	var (
+		firstErr error
+		failed   int
+		matched  int
+	)
+
+	for idx := range p.endpoints {
+		target := &p.endpoints[idx]
+		if !target.delivers(messageChannel) {
+			continue
+		}
+
+		matched++
+
+		err := p.deliver(ctx, target, header, body)
+		if err != nil {
+			failed++
+
+			if firstErr == nil {
+				firstErr = errors.WithMessagef(err, "could not deliver event to %q", target.URL)
+			}
+		}
+	}
+
+	if matched == 0 {
+		return errors.WithMessagef(errNoEndpoints, "%q", messageChannel)
+	}
+
+	if firstErr != nil {
+		return errors.WithMessagef(firstErr, "%d of %d webhooks failed", failed, matched)
+	}
+
+	return nil
+}
+
+func (p *Publisher) deliver(ctx context.Context, target *endpoint, header http.Header, body []byte) error {
+	if !target.breaker.allow(p.now()) {
+		return ErrCircuitOpen
+	}
+
+	var err error
+
+	backoff := p.retryBackoff
+
+	for attempt := 0; attempt <= p.maxRetries; attempt++ {
+		if attempt > 0 {
+			err = sleep(ctx, backoff)
+			if err != nil {
+				break
+			}
+
+			backoff *= 2
+		}
+
+		var retryable bool
+
+		retryable, err = p.send(ctx, target, header, body)
+		if err == nil || !retryable {
+			break
+		}
+	}
+
+	target.breaker.record(p.now(), err == nil)
+
+	return err
+}
+
+// send posts the body once and reports whether a failure can be retried.
+func (p *Publisher) send(ctx context.Context, target *endpoint, header http.Header, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "could not create webhook request")
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	signedAt := p.now()
+	request.Header.Set(TimestampHeader, strconv.FormatInt(signedAt.Unix(), 10))
+	request.Header.Set(SignatureHeader, Sign(target.Secret, signedAt, request.Header, body))
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return ctx.Err() == nil, errors.Wrap(err, "webhook request failed")
+	}
+
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+
+	retryable := response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode >= http.StatusInternalServerError
+
+	return retryable, errors.WithMessagef(errUnexpectedCode, "%d", response.StatusCode)
+}
+
+func (e endpoint) delivers(channel string) bool {
+	if len(e.Channels) == 0 {
+		return true
+	}
+
+	for _, value := range e.Channels {
+		if value == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "retry interrupted")
+	case <-timer.C:
+		return nil
+	}
+}
+
+func withDefaults(settings Settings) Settings {
+	if settings.Client == nil {
+		settings.Client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	if settings.MaxRetries == 0 {
+		settings.MaxRetries = defaultMaxRetries
+	}
+
+	if settings.MaxRetries < 0 {
+		settings.MaxRetries = 0
+	}
+
+	if settings.RetryBackoff <= 0 {
+		settings.RetryBackoff = defaultRetryBackoff
+	}
+
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = defaultFailureThreshold
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = defaultOpenTimeout
+	}
+
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	return settings
+}
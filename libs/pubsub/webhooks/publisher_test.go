@@ -0,0 +1,173 @@
+package webhooks_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/webhooks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var secret = []byte("a-shared-secret")
+
+func TestPublishToVerifier(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	expectedEvent := eventMessageFixture()
+	received := make(chan messages.Event, 1)
+	verifier := webhooks.NewVerifier(webhooks.VerifierSettings{
+		Secret: secret,
+		Handler: func(_ context.Context, event messages.Event) error {
+			received <- event
+
+			return nil
+		},
+	})
+	server := httptest.NewServer(verifier)
+
+	defer server.Close()
+
+	publisher := webhooks.New(webhooks.Settings{
+		Endpoints: []webhooks.Endpoint{{URL: server.URL, Secret: secret}},
+	})
+	// When
+	err := publisher.Publish(context.TODO(), "orders-topic", expectedEvent)
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvent, <-received)
+}
+
+func TestPublishRetriesServerErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	publisher := webhooks.New(webhooks.Settings{
+		Endpoints:    []webhooks.Endpoint{{URL: server.URL, Secret: secret}},
+		RetryBackoff: time.Millisecond,
+	})
+	// When
+	err := publisher.Publish(context.TODO(), "orders-topic", eventMessageFixture())
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestPublishDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var calls int32
+
+	expectedError := `1 of 1 webhooks failed: could not deliver event to "%s": 400: ` +
+		"unexpected webhook response status"
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		res.WriteHeader(http.StatusBadRequest)
+	}))
+
+	defer server.Close()
+
+	publisher := webhooks.New(webhooks.Settings{
+		Endpoints:    []webhooks.Endpoint{{URL: server.URL, Secret: secret}},
+		RetryBackoff: time.Millisecond,
+	})
+	// When
+	err := publisher.Publish(context.TODO(), "orders-topic", eventMessageFixture())
+	// Then
+	assert.EqualError(t, err, fmt.Sprintf(expectedError, server.URL))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPublishOpensCircuit(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	var calls int32
+
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		res.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	publisher := webhooks.New(webhooks.Settings{
+		Endpoints:        []webhooks.Endpoint{{URL: server.URL, Secret: secret}},
+		MaxRetries:       -1,
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		Now:              func() time.Time { return now },
+	})
+	ctx := context.TODO()
+	// When
+	_ = publisher.Publish(ctx, "orders-topic", eventMessageFixture())
+	_ = publisher.Publish(ctx, "orders-topic", eventMessageFixture())
+	err := publisher.Publish(ctx, "orders-topic", eventMessageFixture())
+	// Then
+	assert.True(t, errors.Is(err, webhooks.ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestPublishWithoutEndpointsForChannel(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	publisher := webhooks.New(webhooks.Settings{
+		Endpoints: []webhooks.Endpoint{
+			{URL: "http://localhost", Secret: secret, Channels: []string{"payments-topic"}},
+		},
+	})
+	// When
+	err := publisher.Publish(context.TODO(), "orders-topic", eventMessageFixture())
+	// Then
+	assert.EqualError(t, err, `"orders-topic": no webhook endpoint configured for channel`)
+}
+
+func TestPublishUnsupportedMessage(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	publisher := webhooks.New(webhooks.Settings{})
+	// When
+	err := publisher.Publish(context.TODO(), "orders-topic", "not an event")
+	// Then
+	assert.True(t, errors.Is(err, messages.ErrUnsupportedMessage))
+}
+
+func eventMessageFixture() messages.Event {
+	header := messages.Header{
+		ID:          "123-456-789",
+		Domain:      "loans",
+		EventType:   "orders",
+		Version:     "0.1.0",
+		Application: "core-app",
+	}
+
+	return messages.Event{
+		Header: header,
+		Data:   []byte(`{"value_one": "one", "value_two": "two"}`),
+	}
+}
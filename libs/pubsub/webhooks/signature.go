@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader header carrying the request signature.
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader header carrying the unix time the request was signed at.
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	signaturePrefix = "sha256="
+	// signedHeaderPrefix prefix of the cloud event attribute headers in binary content mode.
+	signedHeaderPrefix = "ce-"
+	contentTypeHeader  = "content-type"
+)
+
+var (
+	errMissingSignature = errors.New("missing webhook signature")
+	errInvalidSignature = errors.New("invalid webhook signature")
+	errInvalidTimestamp = errors.New("invalid webhook timestamp")
+	errExpiredTimestamp = errors.New("webhook timestamp is outside the tolerance window")
+)
+
+// Sign returns the signature of the given headers and body sent at the given time.
+//
+// The signature is "sha256=" followed by the hex encoded HMAC-SHA256 of
+// "<unix timestamp>.<headers>\n<body>" using the endpoint secret. Headers are the ce-* and
+// content-type headers, which carry the event attributes in binary content mode, as
+// "<lowercase name>:<comma separated values>\n" lines sorted by name.
+func Sign(secret []byte, timestamp time.Time, header http.Header, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(canonicalHeaders(header)))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalHeaders returns the signed headers as sorted "<name>:<values>\n" lines.
+func canonicalHeaders(header http.Header) string {
+	lines := make([]string, 0, len(header))
+
+	for key, values := range header {
+		name := strings.ToLower(key)
+		if !strings.HasPrefix(name, signedHeaderPrefix) && name != contentTypeHeader {
+			continue
+		}
+
+		lines = append(lines, name+":"+strings.Join(values, ",")+"\n")
+	}
+
+	sort.Strings(lines)
+
+	return strings.Join(lines, "")
+}
+
+// verifySignature checks the signature and that the timestamp is within the tolerance
+// window around now.
+func verifySignature(secret []byte, signature, timestamp string, header http.Header, body []byte,
+	now time.Time, tolerance time.Duration,
+) error {
+	if signature == "" || timestamp == "" {
+		return errMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.WithMessagef(errInvalidTimestamp, "%q", timestamp)
+	}
+
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-tolerance)) || signedAt.After(now.Add(tolerance)) {
+		return errExpiredTimestamp
+	}
+
+	expected := Sign(secret, signedAt, header, body)
+	if !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(expected), []byte(signature)) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/cloudevents"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+)
+
+const (
+	defaultTolerance   = 5 * time.Minute
+	maxRequestBodySize = 10 << 20 // 10MB
+)
+
+// Handler processes an event received by a webhook.
+type Handler func(ctx context.Context, event messages.Event) error
+
+// VerifierSettings contains the webhook verifier configuration.
+type VerifierSettings struct {
+	// Secret shared with the sender to sign requests.
+	Secret []byte
+	// Tolerance maximum difference between the request timestamp and now, defaults to 5m.
+	Tolerance time.Duration
+	// Handler is called with every verified event.
+	Handler Handler
+	// Now returns the current time, it defaults to time.Now.
+	Now func() time.Time
+}
+
+// Verifier is an http.Handler that verifies signed webhook requests before handing the event
+// over. Requests signed out of the tolerance window are rejected and events already received,
+// by source and id, are answered without handing them over again.
+type Verifier struct {
+	secret    []byte
+	tolerance time.Duration
+	handler   Handler
+	now       func() time.Time
+	mu        sync.Mutex
+	seen      map[string]received
+	sweptAt   time.Time
+}
+
+// received event, handled once its handler succeeded.
+type received struct {
+	at      time.Time
+	handled bool
+}
+
+// NewVerifier instances a new webhook verifier.
+func NewVerifier(settings VerifierSettings) *Verifier {
+	if settings.Tolerance <= 0 {
+		settings.Tolerance = defaultTolerance
+	}
+
+	if settings.Now == nil {
+		settings.Now = time.Now
+	}
+
+	newVerifier := Verifier{
+		secret:    settings.Secret,
+		tolerance: settings.Tolerance,
+		handler:   settings.Handler,
+		now:       settings.Now,
+		seen:      make(map[string]received),
+	}
+
+	return &newVerifier
+}
+
+// ServeHTTP verifies the request and calls the handler with the event it carries.
+// It answers 401 when the request is not authentic, 400 when it does not carry an event,
+// 500 when the handler fails, 200 when the event was already handled, 429 while the same event
+// is still being handled, so the sender retries it, and 204 otherwise.
+func (v *Verifier) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(res, req.Body, maxRequestBodySize))
+	if err != nil {
+		res.WriteHeader(http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	now := v.now()
+
+	err = verifySignature(v.secret, req.Header.Get(SignatureHeader), req.Header.Get(TimestampHeader),
+		req.Header, body, now, v.tolerance)
+	if err != nil {
+		log.Println("error", err, "method", "webhooks.Verifier.ServeHTTP")
+		res.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	cloudEvent, err := cloudevents.ReadHTTP(req.Header, body)
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	key := cloudEvent.Source + " " + cloudEvent.ID
+
+	previous, ok := v.remember(key, now)
+	if ok {
+		status := http.StatusOK
+		if !previous.handled {
+			status = http.StatusTooManyRequests
+		}
+
+		res.WriteHeader(status)
+
+		return
+	}
+
+	event, err := cloudevents.ToMessage(cloudEvent)
+	if err != nil {
+		v.forget(key)
+		res.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	err = v.handler(req.Context(), event)
+	if err != nil {
+		log.Println("error", err, "event", event.Header.ID, "method", "webhooks.Verifier.ServeHTTP")
+		// let the sender retry the same event.
+		v.forget(key)
+		res.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	v.handled(key)
+	res.WriteHeader(http.StatusNoContent)
+}
+
+// remember records the event key, it returns the event already received with the same key if
+// any. Keys older than twice the tolerance are forgotten, looking for them at most once per
+// tolerance, their requests are rejected by the timestamp check anyway.
+func (v *Verifier) remember(key string, now time.Time) (received, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if now.Sub(v.sweptAt) > v.tolerance {
+		v.sweptAt = now
+
+		for seenKey, seen := range v.seen {
+			if now.Sub(seen.at) > 2*v.tolerance {
+				delete(v.seen, seenKey)
+			}
+		}
+	}
+
+	if previous, ok := v.seen[key]; ok {
+		return previous, true
+	}
+
+	v.seen[key] = received{at: now}
+
+	return received{}, false
+}
+
+func (v *Verifier) handled(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if seen, ok := v.seen[key]; ok {
+		seen.handled = true
+		v.seen[key] = seen
+	}
+}
+
+func (v *Verifier) forget(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.seen, key)
+}
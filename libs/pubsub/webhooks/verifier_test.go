@@ -0,0 +1,193 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/messages"
+	"github.com/akatsuki-members/credit-crypto/libs/pubsub/webhooks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifierRejectsRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"value_one": "one"}`)
+	tests := map[string]struct {
+		secret    []byte
+		signedAt  time.Time
+		signature string
+	}{
+		"wrong secret": {
+			secret:   []byte("another-secret"),
+			signedAt: now,
+		},
+		"expired timestamp": {
+			secret:   secret,
+			signedAt: now.Add(-time.Hour),
+		},
+		"missing signature": {
+			secret:    secret,
+			signedAt:  now,
+			signature: "-",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Given
+			verifier := newVerifier(now)
+			request := signedRequest(t, test.secret, test.signedAt, body)
+
+			if test.signature != "" {
+				request.Header.Del(webhooks.SignatureHeader)
+			}
+
+			recorder := httptest.NewRecorder()
+			// When
+			verifier.ServeHTTP(recorder, request)
+			// Then
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+func TestVerifierAnswersDuplicatedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"value_one": "one"}`)
+
+	var calls int32
+
+	verifier := webhooks.NewVerifier(webhooks.VerifierSettings{
+		Secret: secret,
+		Handler: func(context.Context, messages.Event) error {
+			atomic.AddInt32(&calls, 1)
+
+			return nil
+		},
+		Now: func() time.Time { return now },
+	})
+	first := httptest.NewRecorder()
+	duplicated := httptest.NewRecorder()
+	// When
+	verifier.ServeHTTP(first, signedRequest(t, secret, now, body))
+	verifier.ServeHTTP(duplicated, signedRequest(t, secret, now, body))
+	// Then
+	assert.Equal(t, http.StatusNoContent, first.Code)
+	assert.Equal(t, http.StatusOK, duplicated.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestVerifierAsksToRetryEventsBeingHandled(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"value_one": "one"}`)
+	started, release := make(chan struct{}), make(chan struct{})
+	verifier := webhooks.NewVerifier(webhooks.VerifierSettings{
+		Secret: secret,
+		Handler: func(context.Context, messages.Event) error {
+			close(started)
+			<-release
+
+			return errors.New("database unavailable")
+		},
+		Now: func() time.Time { return now },
+	})
+	first := httptest.NewRecorder()
+	duplicated := httptest.NewRecorder()
+	handled := make(chan struct{})
+
+	go func() {
+		defer close(handled)
+
+		verifier.ServeHTTP(first, signedRequest(t, secret, now, body))
+	}()
+
+	<-started
+	// When
+	verifier.ServeHTTP(duplicated, signedRequest(t, secret, now, body))
+	close(release)
+	<-handled
+	// Then
+	assert.Equal(t, http.StatusTooManyRequests, duplicated.Code)
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+}
+
+func TestVerifierRejectsTamperedAttributes(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"value_one": "one"}`)
+	verifier := newVerifier(now)
+	request := signedRequest(t, secret, now, body)
+	request.Header.Set("ce-type", "refunds")
+	recorder := httptest.NewRecorder()
+	// When
+	verifier.ServeHTTP(recorder, request)
+	// Then
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestVerifierAcceptsEventsWithTheSameBody(t *testing.T) {
+	t.Parallel()
+
+	// Given
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"value_one": "one"}`)
+	verifier := newVerifier(now)
+	first := httptest.NewRecorder()
+	second := httptest.NewRecorder()
+	request := signedRequest(t, secret, now, body)
+	request.Header.Set("ce-id", "987-654-321")
+	request.Header.Set(webhooks.SignatureHeader, webhooks.Sign(secret, now, request.Header, body))
+	// When
+	verifier.ServeHTTP(first, signedRequest(t, secret, now, body))
+	verifier.ServeHTTP(second, request)
+	// Then
+	assert.Equal(t, http.StatusNoContent, first.Code)
+	assert.Equal(t, http.StatusNoContent, second.Code)
+}
+
+func newVerifier(now time.Time) *webhooks.Verifier {
+	return webhooks.NewVerifier(webhooks.VerifierSettings{
+		Secret:  secret,
+		Handler: func(context.Context, messages.Event) error { return nil },
+		Now:     func() time.Time { return now },
+	})
+}
+
+func signedRequest(t *testing.T, key []byte, signedAt time.Time, body []byte) *http.Request {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(context.TODO(), http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	request.Header.Set("ce-specversion", "1.0")
+	request.Header.Set("ce-id", "123-456-789")
+	request.Header.Set("ce-source", "loans")
+	request.Header.Set("ce-type", "orders")
+	request.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(signedAt.Unix(), 10))
+	request.Header.Set(webhooks.SignatureHeader, webhooks.Sign(key, signedAt, request.Header, body))
+
+	return request
+}